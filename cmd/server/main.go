@@ -2,7 +2,9 @@ package main
 
 import (
 	"avito-backend-trainee-2024/internal/config"
+	"avito-backend-trainee-2024/pkg/geoip"
 	"avito-backend-trainee-2024/pkg/hasher"
+	localstorage "avito-backend-trainee-2024/pkg/storage/local"
 	gocache "github.com/patrickmn/go-cache"
 	"time"
 
@@ -25,22 +27,56 @@ import (
 
 	chimiddlewares "github.com/go-chi/chi/v5/middleware"
 
+	assetrepo "avito-backend-trainee-2024/internal/repository/postgres/asset"
+	auditrepo "avito-backend-trainee-2024/internal/repository/postgres/audit"
 	bannerrepo "avito-backend-trainee-2024/internal/repository/postgres/banner"
+	campaignrepo "avito-backend-trainee-2024/internal/repository/postgres/campaign"
 	featurerepo "avito-backend-trainee-2024/internal/repository/postgres/feature"
+	linkcheckrepo "avito-backend-trainee-2024/internal/repository/postgres/linkcheck"
+	missrepo "avito-backend-trainee-2024/internal/repository/postgres/miss"
+	overriderepo "avito-backend-trainee-2024/internal/repository/postgres/override"
+	schedulerepo "avito-backend-trainee-2024/internal/repository/postgres/schedule"
+	segmentrepo "avito-backend-trainee-2024/internal/repository/postgres/segment"
 	tagrepo "avito-backend-trainee-2024/internal/repository/postgres/tag"
+	templaterepo "avito-backend-trainee-2024/internal/repository/postgres/template"
 	userrepo "avito-backend-trainee-2024/internal/repository/postgres/user"
 
+	assetservice "avito-backend-trainee-2024/internal/service/asset"
+	auditservice "avito-backend-trainee-2024/internal/service/audit"
 	authservice "avito-backend-trainee-2024/internal/service/auth"
 	bannerservice "avito-backend-trainee-2024/internal/service/banner"
+	campaignservice "avito-backend-trainee-2024/internal/service/campaign"
+	featureservice "avito-backend-trainee-2024/internal/service/feature"
+	linkcheckservice "avito-backend-trainee-2024/internal/service/linkcheck"
+	missservice "avito-backend-trainee-2024/internal/service/miss"
+	overrideservice "avito-backend-trainee-2024/internal/service/override"
+	scheduleservice "avito-backend-trainee-2024/internal/service/schedule"
+	segmentservice "avito-backend-trainee-2024/internal/service/segment"
+	sweepservice "avito-backend-trainee-2024/internal/service/sweep"
+	tagservice "avito-backend-trainee-2024/internal/service/tag"
+	templateservice "avito-backend-trainee-2024/internal/service/template"
 
 	midlewares "avito-backend-trainee-2024/internal/handler/middleware"
 
+	assethandler "avito-backend-trainee-2024/internal/handler/asset"
+	audithandler "avito-backend-trainee-2024/internal/handler/audit"
 	adminbannerhandler "avito-backend-trainee-2024/internal/handler/banner/admin"
+	explainbannerhandler "avito-backend-trainee-2024/internal/handler/banner/explain"
+	linkcheckbannerhandler "avito-backend-trainee-2024/internal/handler/banner/linkcheck"
+	schedulebannerhandler "avito-backend-trainee-2024/internal/handler/banner/schedule"
 	userbannerhandler "avito-backend-trainee-2024/internal/handler/banner/user"
+	campaignhandler "avito-backend-trainee-2024/internal/handler/campaign"
+	featurehandler "avito-backend-trainee-2024/internal/handler/feature"
+	overridehandler "avito-backend-trainee-2024/internal/handler/override"
+	segmenthandler "avito-backend-trainee-2024/internal/handler/segment"
+	taghandler "avito-backend-trainee-2024/internal/handler/tag"
+	templatehandler "avito-backend-trainee-2024/internal/handler/template"
 
 	authhandler "avito-backend-trainee-2024/internal/handler/auth"
 	httpswagger "github.com/swaggo/http-swagger"
 
+	iputils "avito-backend-trainee-2024/pkg/utils/ip"
+
 	_ "avito-backend-trainee-2024/docs"
 )
 
@@ -106,22 +142,104 @@ func main() {
 	bannerRepo := bannerrepo.New(db)
 	featureRepo := featurerepo.New(db)
 	tagRepo := tagrepo.New(db)
+	auditRepo := auditrepo.New(db)
+	missRepo := missrepo.New(db)
+	segmentRepo := segmentrepo.New(db)
+	overrideRepo := overriderepo.New(db)
+	campaignRepo := campaignrepo.New(db)
+	scheduleRepo := schedulerepo.New(db)
+	templateRepo := templaterepo.New(db)
+	assetRepo := assetrepo.New(db)
+	linkCheckRepo := linkcheckrepo.New(db)
+
+	assetStorage, err := localstorage.New(conf.Storage.LocalPath)
+	if err != nil {
+		logger.Fatalf("can't open asset storage %v: %v", conf.Storage.LocalPath, err)
+	}
 
-	bannerService := bannerservice.New(bannerRepo, featureRepo, tagRepo)
+	missService := missservice.New(
+		missRepo,
+		conf.Telemetry.MissesFlushInterval,
+		conf.Telemetry.MissesRetention,
+		conf.Telemetry.MaxPendingMisses,
+		logger,
+	)
+	campaignService := campaignservice.New(
+		campaignRepo,
+		conf.Telemetry.ImpressionsFlushInterval,
+		conf.Telemetry.ImpressionsReservationSize,
+		logger,
+	)
+	featureService := featureservice.New(featureRepo)
+	bannerService := bannerservice.New(bannerRepo, featureRepo, tagRepo, segmentRepo, overrideRepo, missService, featureService, campaignService)
+	segmentService := segmentservice.New(segmentRepo)
+	tagService := tagservice.New(tagRepo)
+	auditService := auditservice.New(auditRepo)
+	overrideService := overrideservice.New(overrideRepo, bannerRepo)
+	scheduleService := scheduleservice.New(scheduleRepo, bannerRepo, bannerService, conf.Scheduler.EditsApplyInterval, logger)
+	sweepService := sweepservice.New(bannerRepo, conf.Scheduler.SweepInterval, conf.Scheduler.ArchiveAfter, logger)
+	templateService := templateservice.New(templateRepo, bannerService)
+	linkCheckService := linkcheckservice.New(linkCheckRepo, bannerRepo, conf.LinkChecker.CheckInterval, conf.LinkChecker.Concurrency,
+		conf.LinkChecker.HostInterval, conf.LinkChecker.RequestTimeout, conf.LinkChecker.AllowPrivateAddresses, logger)
+	assetService := assetservice.New(assetRepo, assetStorage, conf.Storage.PublicURL)
 	authService := authservice.New(userRepo, hasher.New())
 
+	// geo targeting is optional, without database banners targeted by location are not shown
+	var geoResolver midlewares.GeoResolver
+
+	if conf.Geo.DatabasePath != "" {
+		resolver, err := geoip.New(conf.Geo.DatabasePath, logger)
+		if err != nil {
+			logger.Fatalf("can't open geoip database %v: %v", conf.Geo.DatabasePath, err)
+		}
+
+		defer resolver.Close()
+
+		geoResolver = resolver
+	}
+
+	trustedProxies, err := iputils.ParseNetworks(conf.Geo.TrustedProxies)
+	if err != nil {
+		logger.Fatalf("invalid trusted proxies provided: %v", err)
+	}
+
 	authMiddleware := midlewares.JWTAuthentication("token", conf.Jwt.Secret, logger)
 	adminAuthMiddleware := midlewares.AdminAuthorization(logger)
-	cacheMiddleware := midlewares.InMemUserBannerCache(cache, logger)
+	geoMiddleware := midlewares.GeoLocation(geoResolver, trustedProxies, logger)
+	platformMiddleware := midlewares.ClientPlatform()
+	cacheMiddleware := midlewares.InMemUserBannerCache(cache, featureService, campaignService, logger)
+	slugMiddleware := midlewares.SlugResolution(bannerService, cache, logger)
 
 	authHandler := authhandler.New(authService, conf.Jwt, logger, valid)
-	userBannerHandler := userbannerhandler.New(bannerService, logger, valid, authMiddleware, cacheMiddleware)
+	userBannerHandler := userbannerhandler.New(bannerService, cache, logger, valid, authMiddleware, geoMiddleware, platformMiddleware, slugMiddleware, cacheMiddleware)
 	adminBannerHandler := adminbannerhandler.New(bannerService, logger, valid, authMiddleware, adminAuthMiddleware)
+	explainBannerHandler := explainbannerhandler.New(bannerService, cache, logger, valid, authMiddleware, adminAuthMiddleware, slugMiddleware)
+	scheduleBannerHandler := schedulebannerhandler.New(scheduleService, logger, valid, authMiddleware, adminAuthMiddleware)
+	linkCheckBannerHandler := linkcheckbannerhandler.New(linkCheckService, logger, valid, authMiddleware, adminAuthMiddleware)
+	featureHandler := featurehandler.New(featureService, cache, logger, valid, authMiddleware, adminAuthMiddleware)
+	tagHandler := taghandler.New(tagService, cache, logger, valid, authMiddleware, adminAuthMiddleware)
+	auditHandler := audithandler.New(auditService, logger, valid, authMiddleware, adminAuthMiddleware)
+	segmentHandler := segmenthandler.New(segmentService, logger, valid, authMiddleware, adminAuthMiddleware)
+	overrideHandler := overridehandler.New(overrideService, logger, valid, authMiddleware, adminAuthMiddleware)
+	campaignHandler := campaignhandler.New(campaignService, logger, valid, authMiddleware, adminAuthMiddleware)
+	templateHandler := templatehandler.New(templateService, logger, valid, authMiddleware, adminAuthMiddleware)
+	assetHandler := assethandler.New(assetService, logger, valid, authMiddleware, adminAuthMiddleware)
 
 	routers := make(map[string]chi.Router)
 
 	routers["/user_banner"] = userBannerHandler.Routes()
+	routers["/user_banner/explain"] = explainBannerHandler.Routes()
 	routers["/banner"] = adminBannerHandler.Routes()
+	routers["/banner/scheduled_edits"] = scheduleBannerHandler.Routes()
+	routers["/banner/broken_links"] = linkCheckBannerHandler.Routes()
+	routers["/feature"] = featureHandler.Routes()
+	routers["/tag"] = tagHandler.Routes()
+	routers["/audit"] = auditHandler.Routes()
+	routers["/segment"] = segmentHandler.Routes()
+	routers["/override"] = overrideHandler.Routes()
+	routers["/campaign"] = campaignHandler.Routes()
+	routers["/template"] = templateHandler.Routes()
+	routers["/asset"] = assetHandler.Routes()
 	routers["/auth"] = authHandler.Routes()
 
 	middlewares := []router.Middleware{
@@ -143,6 +261,12 @@ func main() {
 
 	logger.Infof("server started at port %v", server.Addr)
 
+	go missService.Run(ctx)
+	go campaignService.Run(ctx)
+	go scheduleService.Run(ctx)
+	go sweepService.Run(ctx)
+	go linkCheckService.Run(ctx)
+
 	go func() {
 		if listenErr := server.ListenAndServe(); listenErr != nil && !errors.Is(listenErr, http.ErrServerClosed) {
 			logger.WithError(listenErr).Fatalf("server can't listen requests")
@@ -166,6 +290,16 @@ func main() {
 			logger.WithError(shutdownErr).Fatalf("can't close server listening on '%s'", server.Addr)
 		}
 
+		// persist misses counted since the last flush
+		if flushErr := missService.Flush(ctx); flushErr != nil {
+			logger.WithError(flushErr).Error("can't persist banner misses")
+		}
+
+		// return impressions reserved but not shown to campaign budgets
+		if flushErr := campaignService.Flush(ctx); flushErr != nil {
+			logger.WithError(flushErr).Error("can't return reserved campaign impressions")
+		}
+
 		cancel()
 	}()
 
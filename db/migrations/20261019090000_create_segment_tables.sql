@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE segment
+(
+    id         bigserial not null primary key,
+    name       text      not null unique,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now()
+);
+
+CREATE TABLE segment_user
+(
+    segment_id integer not null references segment on delete cascade,
+    user_id    integer not null,
+    primary key (segment_id, user_id)
+);
+
+CREATE INDEX segment_user_user_id_idx ON segment_user (user_id);
+
+CREATE TABLE banner_segment
+(
+    id         bigserial not null primary key,
+    banner_id  integer   not null references banner on delete cascade,
+    segment_id integer   not null references segment
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE banner_segment;
+DROP TABLE segment_user;
+DROP TABLE segment;
+-- +goose StatementEnd
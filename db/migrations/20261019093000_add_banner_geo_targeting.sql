@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE banner
+    ADD COLUMN countries text[] not null default '{}',
+    ADD COLUMN regions   text[] not null default '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner
+    DROP COLUMN countries,
+    DROP COLUMN regions;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE banner
+    ADD COLUMN platforms       text[] not null default '{}',
+    ADD COLUMN min_app_version text   not null default '',
+    ADD COLUMN max_app_version text   not null default '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner
+    DROP COLUMN platforms,
+    DROP COLUMN min_app_version,
+    DROP COLUMN max_app_version;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE banner_override
+(
+    id         bigserial   not null primary key,
+    banner_id  integer     not null references banner on delete cascade,
+    user_id    integer     not null,
+    expires_at timestamptz not null,
+    created_at timestamp   not null default now()
+);
+
+CREATE INDEX banner_override_user_id_idx ON banner_override (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE banner_override;
+-- +goose StatementEnd
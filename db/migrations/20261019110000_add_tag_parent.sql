@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tag
+    ADD COLUMN parent_id bigint references tag;
+
+CREATE INDEX tag_parent_id_idx ON tag (parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tag
+    DROP COLUMN parent_id;
+-- +goose StatementEnd
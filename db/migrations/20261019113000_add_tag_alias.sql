@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tag
+    ADD COLUMN alias_of_id bigint references tag;
+
+-- banner can not be tagged with the same tag twice, which is relied on by merging tags
+DELETE
+FROM banner_tag bt
+    USING banner_tag other
+WHERE bt.banner_id = other.banner_id
+  AND bt.tag_id = other.tag_id
+  AND bt.id > other.id;
+
+CREATE UNIQUE INDEX banner_tag_banner_id_tag_id_idx ON banner_tag (banner_id, tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX banner_tag_banner_id_tag_id_idx;
+
+ALTER TABLE tag
+    DROP COLUMN alias_of_id;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_log
+(
+    id          bigserial not null primary key,
+    action      text      not null,
+    entity_type text      not null,
+    entity_id   integer   not null,
+    username    text      not null,
+    details     jsonb     not null default '{}',
+    created_at  timestamp not null default now()
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+-- +goose StatementEnd
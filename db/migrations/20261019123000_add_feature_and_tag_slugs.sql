@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feature
+    ADD COLUMN slug text;
+
+ALTER TABLE tag
+    ADD COLUMN slug text;
+
+-- existing slugs are made of names, id is appended to ambiguous ones until all of them are unique,
+-- since slug with id appended may be equal to slug made of other name
+UPDATE feature
+SET slug = coalesce(nullif(trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')), ''), 'feature');
+
+UPDATE tag
+SET slug = coalesce(nullif(trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')), ''), 'tag');
+
+DO
+$$
+    BEGIN
+        LOOP
+            UPDATE feature
+            SET slug = slug || '-' || id
+            WHERE slug IN (SELECT slug FROM feature GROUP BY slug HAVING count(*) > 1);
+
+            EXIT WHEN NOT FOUND;
+        END LOOP;
+
+        LOOP
+            UPDATE tag
+            SET slug = slug || '-' || id
+            WHERE slug IN (SELECT slug FROM tag GROUP BY slug HAVING count(*) > 1);
+
+            EXIT WHEN NOT FOUND;
+        END LOOP;
+    END
+$$;
+
+ALTER TABLE feature
+    ALTER COLUMN slug SET NOT NULL;
+
+ALTER TABLE tag
+    ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX feature_slug_idx ON feature (slug);
+CREATE UNIQUE INDEX tag_slug_idx ON tag (slug);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feature
+    DROP COLUMN slug;
+
+ALTER TABLE tag
+    DROP COLUMN slug;
+-- +goose StatementEnd
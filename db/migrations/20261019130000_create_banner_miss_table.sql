@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE banner_miss
+(
+    feature_id     integer   not null,
+    tag_ids        integer[] not null,
+    misses_count   bigint    not null default 0,
+    last_missed_at timestamp not null default now(),
+    primary key (feature_id, tag_ids)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE banner_miss;
+-- +goose StatementEnd
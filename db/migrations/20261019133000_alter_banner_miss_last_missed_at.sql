@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- misses are counted by application and persisted later, so time of the last miss comes with time zone
+ALTER TABLE banner_miss
+    ALTER COLUMN last_missed_at TYPE timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner_miss
+    ALTER COLUMN last_missed_at TYPE timestamp;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feature
+    ADD COLUMN description text   not null default '',
+    ADD COLUMN team        text   not null default '',
+    ADD COLUMN contact     text   not null default '',
+    ADD COLUMN placement   text   not null default '',
+    ADD COLUMN labels      text[] not null default '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feature
+    DROP COLUMN description,
+    DROP COLUMN team,
+    DROP COLUMN contact,
+    DROP COLUMN placement,
+    DROP COLUMN labels;
+-- +goose StatementEnd
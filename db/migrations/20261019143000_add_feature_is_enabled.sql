@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE feature
+    ADD COLUMN is_enabled boolean not null default true;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE feature
+    DROP COLUMN is_enabled;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE banner_feature
+(
+    id         bigserial not null primary key,
+    banner_id  integer   not null references banner on delete cascade,
+    feature_id integer   not null references feature on delete cascade
+);
+
+CREATE UNIQUE INDEX banner_feature_banner_id_feature_id_idx ON banner_feature (banner_id, feature_id);
+CREATE INDEX banner_feature_feature_id_idx ON banner_feature (feature_id);
+
+-- banner may be shown in several features, so feature is moved from banner to the relation
+INSERT INTO banner_feature (banner_id, feature_id)
+SELECT id, feature_id
+FROM banner;
+
+ALTER TABLE banner
+    DROP COLUMN feature_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner
+    ADD COLUMN feature_id integer references feature on delete cascade;
+
+-- banners shown in several features keep the first of them
+UPDATE banner
+SET feature_id = (SELECT min(bf.feature_id) FROM banner_feature bf WHERE bf.banner_id = banner.id);
+
+DELETE
+FROM banner
+WHERE feature_id IS NULL;
+
+ALTER TABLE banner
+    ALTER COLUMN feature_id SET NOT NULL;
+
+DROP TABLE banner_feature;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE campaign
+(
+    id                 bigserial   not null primary key,
+    name               text        not null unique,
+    starts_at          timestamptz,
+    ends_at            timestamptz,
+    -- 0 means campaign has no impressions budget
+    impressions_budget bigint      not null default 0,
+    impressions_count  bigint      not null default 0,
+    -- targeting defaults are applied to banners of the campaign having no targeting of their own
+    countries          text[]      not null default '{}',
+    regions            text[]      not null default '{}',
+    platforms          text[]      not null default '{}',
+    min_app_version    text        not null default '',
+    max_app_version    text        not null default '',
+    created_at         timestamptz not null default now(),
+    updated_at         timestamptz not null default now()
+);
+
+ALTER TABLE banner
+    ADD COLUMN campaign_id integer references campaign on delete set null;
+
+CREATE INDEX banner_campaign_id_idx ON banner (campaign_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner
+    DROP COLUMN campaign_id;
+
+DROP TABLE campaign;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE banner_scheduled_edit
+(
+    id         bigserial   not null primary key,
+    banner_id  integer     not null references banner on delete cascade,
+    -- patch has the same shape as banner update, omitted fields are not updated
+    patch      jsonb       not null,
+    apply_at   timestamptz not null,
+    -- pending edit is applied once apply_at has passed, edit failed too many times is not retried
+    status     text        not null default 'pending',
+    attempts   integer     not null default 0,
+    last_error text        not null default '',
+    applied_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX banner_scheduled_edit_status_apply_at_idx ON banner_scheduled_edit (status, apply_at);
+CREATE INDEX banner_scheduled_edit_banner_id_idx ON banner_scheduled_edit (banner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE banner_scheduled_edit;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- expired banners are deactivated and untouched inactive ones are archived by sweep
+ALTER TABLE banner
+    ADD COLUMN ends_at     timestamptz,
+    ADD COLUMN archived_at timestamptz;
+
+CREATE INDEX banner_ends_at_idx ON banner (ends_at) WHERE is_active;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner
+    DROP COLUMN ends_at,
+    DROP COLUMN archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE banner_template
+(
+    id         bigserial   not null primary key,
+    name       text        not null unique,
+    -- content may contain placeholders like {{discount}} filled when banner is created from template
+    title      text        not null,
+    text       text        not null,
+    url        text        not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE banner_template;
+-- +goose StatementEnd
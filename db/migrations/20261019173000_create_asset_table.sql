@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE asset
+(
+    id         bigserial   not null primary key,
+    -- sha256 of content, content is stored in storage under its hash, so the same image is stored once
+    hash       text        not null unique,
+    mime_type  text        not null,
+    size       bigint      not null,
+    width      integer     not null,
+    height     integer     not null,
+    created_at timestamptz not null default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE asset;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE banner_link_check
+(
+    banner_id   integer     not null primary key references banner on delete cascade,
+    url         text        not null,
+    -- 0 if no response was received, error holds the reason then
+    status_code integer     not null default 0,
+    error       text        not null default '',
+    -- urls banner url is redirected through in order
+    redirects   jsonb       not null default '[]',
+    is_broken   boolean     not null,
+    checked_at  timestamptz not null
+);
+
+CREATE INDEX banner_link_check_is_broken_idx ON banner_link_check (is_broken) WHERE is_broken;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE banner_link_check;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- banners matching the same request are ranked by priority first, higher priority is shown first
+ALTER TABLE banner
+    ADD COLUMN priority integer not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banner
+    DROP COLUMN priority;
+-- +goose StatementEnd
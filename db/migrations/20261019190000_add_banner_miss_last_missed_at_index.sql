@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- misses not missed for retention period are pruned by last_missed_at
+CREATE INDEX banner_miss_last_missed_at_idx ON banner_miss (last_missed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX banner_miss_last_missed_at_idx;
+-- +goose StatementEnd
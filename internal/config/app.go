@@ -4,4 +4,9 @@ type Config struct {
 	Server
 	Jwt
 	Postgres
+	Geo
+	Telemetry
+	Scheduler
+	Storage
+	LinkChecker
 }
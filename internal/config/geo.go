@@ -0,0 +1,7 @@
+package config
+
+type Geo struct {
+	// DatabasePath is path to MaxMind-format database file, geo targeting is disabled if empty
+	DatabasePath   string
+	TrustedProxies []string
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+type LinkChecker struct {
+	// CheckInterval is how often urls of active banners are checked
+	CheckInterval time.Duration
+	// Concurrency is how many urls are checked at once
+	Concurrency int
+	// HostInterval is min time between requests to the same host
+	HostInterval time.Duration
+	// RequestTimeout is how long url is waited for before it is considered broken
+	RequestTimeout time.Duration
+	// AllowPrivateAddresses lets urls resolved to private, loopback and link-local addresses be requested
+	AllowPrivateAddresses bool
+}
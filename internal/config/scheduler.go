@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type Scheduler struct {
+	// EditsApplyInterval is how often scheduled edits of banners which time has come are applied
+	EditsApplyInterval time.Duration
+
+	// SweepInterval is how often expired banners are deactivated and untouched ones are archived
+	SweepInterval time.Duration
+	// ArchiveAfter is how long inactive banner stays untouched before it is archived
+	ArchiveAfter time.Duration
+}
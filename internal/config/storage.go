@@ -0,0 +1,8 @@
+package config
+
+type Storage struct {
+	// LocalPath is directory uploaded assets are stored in
+	LocalPath string
+	// PublicURL is url assets are served from, asset hash is appended to it
+	PublicURL string
+}
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type Telemetry struct {
+	// MissesFlushInterval is how often counted lookups with no banner found are persisted
+	MissesFlushInterval time.Duration
+
+	// MissesRetention is how long persisted combinations with no banner found are kept since their last miss
+	MissesRetention time.Duration
+
+	// MaxPendingMisses is how many combinations with no banner found are counted between flushes
+	MaxPendingMisses int
+
+	// ImpressionsFlushInterval is how often impressions of campaign banners reserved but not shown are returned to budgets
+	ImpressionsFlushInterval time.Duration
+
+	// ImpressionsReservationSize is how many impressions of campaign banners are reserved from budget at once
+	ImpressionsReservationSize int
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Asset is image uploaded to be used in banner content, it is addressed by hash of its content
+type Asset struct {
+	ID        int       `db:"id"`
+	Hash      string    `db:"hash"`
+	MimeType  string    `db:"mime_type"`
+	Size      int       `db:"size"`
+	Width     int       `db:"width"`
+	Height    int       `db:"height"`
+	CreatedAt time.Time `db:"created_at"`
+
+	// URL is where asset is served from, it is set by service from configured public url
+	URL string `db:"-"`
+}
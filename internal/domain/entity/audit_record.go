@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+const (
+	AuditEntityTag    = "tag"
+	AuditEntityBanner = "banner"
+
+	AuditActionMerge = "merge"
+	AuditActionSweep = "sweep"
+
+	// AuditUsernameSystem is username of changes made by background workers
+	AuditUsernameSystem = "system"
+)
+
+// AuditRecord describes change made by admin, details are stored as json
+type AuditRecord struct {
+	ID         int       `db:"id"`
+	Action     string    `db:"action"`
+	EntityType string    `db:"entity_type"`
+	EntityID   int       `db:"entity_id"`
+	Username   string    `db:"username"`
+	Details    string    `db:"details"`
+	CreatedAt  time.Time `db:"created_at"`
+}
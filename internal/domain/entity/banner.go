@@ -2,12 +2,55 @@ package entity
 
 import "time"
 
+// NoAppVersion removes app version bound of banner on update, since empty version keeps the bound
+const NoAppVersion = "none"
+
 type Banner struct {
-	ID        int   `db:"id"`
-	TagIDs    []int `db:"tag_ids"`
-	FeatureID int   `db:"feature_id"`
+	ID            int      `db:"id"`
+	TagIDs        []int    `db:"tag_ids"`
+	FeatureIDs    []int    `db:"feature_ids"`
+	SegmentIDs    []int    `db:"segment_ids"`
+	Countries     []string `db:"countries"`
+	Regions       []string `db:"regions"`
+	Platforms     []string `db:"platforms"`
+	MinAppVersion string   `db:"min_app_version"`
+	MaxAppVersion string   `db:"max_app_version"`
+	CampaignID    *int     `db:"campaign_id"` // nil if banner is not part of any campaign
+	// Priority ranks banners matching the same request, higher is shown first, nil keeps priority on update
+	Priority *int `db:"priority"`
 	Content
-	IsActive  bool      `db:"is_active"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	IsActive   bool       `db:"is_active"`
+	EndsAt     *time.Time `db:"ends_at"`     // banner is deactivated by sweep once it ends, nil if it never ends
+	ArchivedAt *time.Time `db:"archived_at"` // set by sweep if banner is inactive and untouched for long
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+
+	// IsOverridden is set if banner is pinned to requester by override, so it is shown even if inactive
+	IsOverridden bool `db:"-"`
+	// ShownUntil is set if banner is shown to requester only until some moment, e.g. till its campaign ends,
+	// so banner is not served from cache after it
+	ShownUntil *time.Time `db:"-"`
+	// Features are set for banners listed to admins, so they can see which screens banner is shown on
+	Features []*Feature `db:"-"`
+}
+
+// LimitShownUntil makes banner shown to requester until t at most
+func (b *Banner) LimitShownUntil(t time.Time) {
+	if b.ShownUntil == nil || t.Before(*b.ShownUntil) {
+		b.ShownUntil = &t
+	}
+}
+
+// ShownCampaignIDs returns campaigns impressions of banners shown to requester are counted against,
+// inactive banners are shown only if pinned to requester
+func ShownCampaignIDs(banners ...*Banner) []int {
+	var campaignIDs []int
+
+	for _, banner := range banners {
+		if banner.CampaignID != nil && (banner.IsActive || banner.IsOverridden) {
+			campaignIDs = append(campaignIDs, *banner.CampaignID)
+		}
+	}
+
+	return campaignIDs
 }
@@ -0,0 +1,8 @@
+package entity
+
+// BannerCandidate describes if banner of requested feature is returned to requester and why
+type BannerCandidate struct {
+	Banner   *Banner
+	Accepted bool
+	Reason   string
+}
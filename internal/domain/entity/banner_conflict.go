@@ -0,0 +1,13 @@
+package entity
+
+import "fmt"
+
+// BannerConflictError is returned when change leaves active banners indistinguishable by lookup: they share a feature,
+// exactly the same tags, targeting and priority, so there is no telling which of them is shown to user
+type BannerConflictError struct {
+	BannerIDs []int
+}
+
+func (e *BannerConflictError) Error() string {
+	return fmt.Sprintf("banners %v share feature, tags, targeting and priority", e.BannerIDs)
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// BannerMiss is combination of feature and tags requested by users with no banner returned to them
+type BannerMiss struct {
+	FeatureID    int
+	TagIDs       []int
+	MissesCount  int
+	LastMissedAt time.Time
+}
@@ -0,0 +1,13 @@
+package entity
+
+// BannerQuery is a single user banner lookup of a batch
+type BannerQuery struct {
+	FeatureID int
+	TagIDs    []int
+}
+
+// BannerQueryResult is banner found for the query of a batch or error describing why it is not found
+type BannerQueryResult struct {
+	Banner *Banner
+	Err    error
+}
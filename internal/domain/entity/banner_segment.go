@@ -0,0 +1,7 @@
+package entity
+
+type BannerSegment struct {
+	ID        int `db:"id"`
+	BannerID  int `db:"banner_id"`
+	SegmentID int `db:"segment_id"`
+}
@@ -0,0 +1,8 @@
+package entity
+
+// BannerSweep is result of sweeping banners: expired banners are deactivated, inactive banners untouched
+// for long are archived
+type BannerSweep struct {
+	DeactivatedIDs []int
+	ArchivedIDs    []int
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// BannerTemplate is standard banner layout, its content may contain placeholders like {{discount}} which are filled
+// with values when banner is created from template
+type BannerTemplate struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+	Content
+	Placeholders []string  `db:"-"` // names of placeholders in order they first appear in content
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// Campaign groups banners sharing schedule, targeting defaults and impressions budget, banners of the campaign are shown
+// only while it is running
+type Campaign struct {
+	ID                int        `db:"id"`
+	Name              string     `db:"name"`
+	StartsAt          *time.Time `db:"starts_at"`          // nil if campaign is started at once, zero removes date on update
+	EndsAt            *time.Time `db:"ends_at"`            // nil if campaign has no end date, zero removes date on update
+	ImpressionsBudget int        `db:"impressions_budget"` // 0 if campaign has no budget
+	ImpressionsCount  int        `db:"impressions_count"`
+	Countries         []string   `db:"countries"`
+	Regions           []string   `db:"regions"`
+	Platforms         []string   `db:"platforms"`
+	MinAppVersion     string     `db:"min_app_version"`
+	MaxAppVersion     string     `db:"max_app_version"`
+	BannersCount      int        `db:"banners_count"`
+	CreatedAt         time.Time  `db:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+}
+
+// IsRunning reports if banners of campaign may be shown at the moment
+func (c *Campaign) IsRunning(now time.Time) bool {
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return false
+	}
+
+	if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+		return false
+	}
+
+	return c.ImpressionsBudget == 0 || c.ImpressionsCount < c.ImpressionsBudget
+}
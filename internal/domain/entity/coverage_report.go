@@ -0,0 +1,21 @@
+package entity
+
+const (
+	CoverageReasonInactive = "inactive"
+	CoverageReasonExpired  = "expired" // some of banners is active, but its end date has passed
+)
+
+// InactiveCombination is combination of feature and tags which banners are all inactive or expired
+type InactiveCombination struct {
+	FeatureID int
+	TagIDs    []int
+	BannerIDs []int
+	Reason    string
+}
+
+// CoverageReport lists requested combinations of feature and tags without active banner
+// and combinations of feature and tags which banners are all inactive or expired
+type CoverageReport struct {
+	Uncovered []*BannerMiss
+	Inactive  []*InactiveCombination
+}
@@ -2,9 +2,19 @@ package entity
 
 import "time"
 
+// Feature is a slot for banners in the app, Placement is name of the screen or its part where banners are shown,
+// no banners of disabled feature are shown to users
 type Feature struct {
-	ID        int       `db:"id"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID           int       `db:"id"`
+	Name         string    `db:"name"`
+	Slug         string    `db:"slug"`
+	Description  string    `db:"description"`
+	Team         string    `db:"team"`
+	Contact      string    `db:"contact"`
+	Placement    string    `db:"placement"`
+	Labels       []string  `db:"labels"`
+	IsEnabled    bool      `db:"is_enabled"`
+	BannersCount int       `db:"banners_count"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
 }
@@ -0,0 +1,7 @@
+package entity
+
+// FeatureBanners are banners shown to user in the feature
+type FeatureBanners struct {
+	FeatureID int
+	Banners   []*Banner
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// LinkCheck is the last check of banner url, banner is broken if its url can not be reached or responds with error
+type LinkCheck struct {
+	BannerID   int       `db:"banner_id"`
+	Url        string    `db:"url"`
+	StatusCode int       `db:"status_code"` // 0 if no response was received
+	Error      string    `db:"error"`
+	Redirects  []string  `db:"redirects"` // urls banner url is redirected through in order
+	IsBroken   bool      `db:"is_broken"`
+	CheckedAt  time.Time `db:"checked_at"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// Override pins banner to user for banner's features regardless of tags, targeting and activity of the banner
+type Override struct {
+	ID         int       `db:"id"`
+	BannerID   int       `db:"banner_id"`
+	FeatureIDs []int     `db:"feature_ids"`
+	UserID     int       `db:"user_id"`
+	ExpiresAt  time.Time `db:"expires_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
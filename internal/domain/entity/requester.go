@@ -0,0 +1,10 @@
+package entity
+
+// Requester describes the user banner is being resolved for
+type Requester struct {
+	UserID     int
+	Country    string // empty if unknown
+	Region     string // empty if unknown
+	Platform   string // empty if unknown
+	AppVersion string // empty if unknown
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	ScheduledEditPending = "pending"
+	ScheduledEditApplied = "applied"
+	ScheduledEditFailed  = "failed"
+)
+
+// ScheduledEdit is update of banner applied by scheduler once ApplyAt has passed
+type ScheduledEdit struct {
+	ID        int        `db:"id"`
+	BannerID  int        `db:"banner_id"`
+	Patch     Banner     `db:"-"` // update model of the banner, the same as used by banner update
+	IsActive  *bool      `db:"-"` // activity set by edit instead of Patch.IsActive, nil keeps activity of the banner
+	ApplyAt   time.Time  `db:"apply_at"`
+	Status    string     `db:"status"`
+	Attempts  int        `db:"attempts"`
+	LastError string     `db:"last_error"` // error of the last failed attempt to apply edit
+	AppliedAt *time.Time `db:"applied_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
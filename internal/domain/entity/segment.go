@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Segment struct {
+	ID         int       `db:"id"`
+	Name       string    `db:"name"`
+	UsersCount int       `db:"users_count"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
@@ -2,9 +2,15 @@ package entity
 
 import "time"
 
+// Tag can have parent tag, banners tagged with ancestors of the tag are matched by it,
+// AliasOfID is id of the tag this one was merged into, alias is matched as that tag
 type Tag struct {
-	ID        int       `db:"id"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID           int       `db:"id"`
+	Name         string    `db:"name"`
+	Slug         string    `db:"slug"`
+	ParentID     *int      `db:"parent_id"`
+	AliasOfID    *int      `db:"alias_of_id"`
+	BannersCount int       `db:"banners_count"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
 }
@@ -0,0 +1,10 @@
+package entity
+
+// TagMerge is result of merging source tag into target one
+type TagMerge struct {
+	SourceID          int
+	TargetID          int
+	KeepAlias         bool
+	MovedBanners      int
+	RemovedDuplicates int
+}
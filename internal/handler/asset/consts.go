@@ -0,0 +1,12 @@
+package asset
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+
+	// MaxUploadSize is max size of uploaded image in bytes
+	MaxUploadSize = 10 << 20
+
+	// CacheControl is set on served assets, content of asset never changes since it is addressed by its hash
+	CacheControl = "public, max-age=31536000, immutable"
+)
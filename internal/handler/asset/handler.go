@@ -0,0 +1,235 @@
+package asset
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllAssets(ctx context.Context, offset, limit int) ([]*entity.Asset, error)
+	UploadAsset(ctx context.Context, data []byte) (*entity.Asset, error)
+	OpenAsset(ctx context.Context, hash string) (*entity.Asset, io.ReadSeekCloser, error)
+	DeleteAsset(ctx context.Context, id int) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	// assets are shown to users in banners, so they are served without auth
+	router.Get("/{hash}", h.ServeAsset)
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllAssets)
+		r.Post("/", h.UploadAsset)
+		r.Delete("/{id}", h.DeleteAsset)
+	})
+
+	return router
+}
+
+// GetAllAssets godoc
+//
+//	@Summary		Get all assets
+//	@Description	Get uploaded images with their metadata, the latest uploaded first
+//	@Security		JWT
+//	@Tags			Asset
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetAssetResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/asset [get]
+func (h *Handler) GetAllAssets(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	assets, err := h.Service.GetAllAssets(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching assets: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusInternalServerError, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(assets, mapper.MapAssetToGetAssetResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// UploadAsset godoc
+//
+//	@Summary		Upload image
+//	@Description	Upload png, jpeg or gif image up to 10 MB, image is stored by hash of its content, so uploading the same image again returns the same asset
+//	@Security		JWT
+//	@Tags			Asset
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			file	formData	file	true	"image"
+//	@Success		201		{object}	response.GetAssetResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		413		{string}	too			large
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/asset [post]
+func (h *Handler) UploadAsset(rw http.ResponseWriter, req *http.Request) {
+	// multipart overhead is allowed on top of image size
+	req.Body = http.MaxBytesReader(rw, req.Body, MaxUploadSize+1<<20)
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred reading 'file' form field: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	defer file.Close()
+
+	if header.Size > MaxUploadSize {
+		msg := fmt.Sprintf("image size %v exceeds max size %v", header.Size, MaxUploadSize)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusRequestEntityTooLarge, msg, msg)
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred reading uploaded image: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	asset, err := h.Service.UploadAsset(req.Context(), data)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred uploading asset: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapAssetToGetAssetResponse(asset))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// ServeAsset godoc
+//
+//	@Summary		Get image
+//	@Description	Get uploaded image by hash of its content, image is cached for a year since its content never changes
+//	@Tags			Asset
+//	@Produce		png
+//	@Produce		jpeg
+//	@Produce		gif
+//	@Param			hash	path		string	true	"hash of the image"
+//	@Success		200		{file}		binary
+//	@Failure		404		{string}	not			found
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/asset/{hash} [get]
+func (h *Handler) ServeAsset(rw http.ResponseWriter, req *http.Request) {
+	asset, content, err := h.Service.OpenAsset(req.Context(), chi.URLParam(req, "hash"))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred opening asset: %v", err)
+
+		// assets are served to anyone, so storage details are only logged
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusInternalServerError, msg, "internal error")
+		return
+	}
+
+	if asset == nil {
+		msg := "no such asset"
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusNotFound, msg, msg)
+		return
+	}
+
+	defer content.Close()
+
+	rw.Header().Set("Content-Type", asset.MimeType)
+	rw.Header().Set("Cache-Control", CacheControl)
+	rw.Header().Set("ETag", fmt.Sprintf(`"%v"`, asset.Hash))
+
+	http.ServeContent(rw, req, "", asset.CreatedAt, content)
+}
+
+// DeleteAsset godoc
+//
+//	@Summary		Delete asset
+//	@Description	Delete uploaded image, banners referring it are not changed
+//	@Security		JWT
+//	@Tags			Asset
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the asset"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/asset/{id} [delete]
+func (h *Handler) DeleteAsset(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.DeleteAsset(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred deleting asset: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,6 @@
+package audit
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAuditRecords(ctx context.Context, entityType string, offset, limit int) ([]*entity.AuditRecord, error)
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAuditRecords)
+	})
+
+	return router
+}
+
+// GetAuditRecords godoc
+//
+//	@Summary		Get audit records
+//	@Description	Get changes made by admins, the newest first
+//	@Security		JWT
+//	@Tags			Audit
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			entity_type	query		string	false	"type of changed entity, e.g. tag"
+//	@Param			offset		query		int		false	"Offset"
+//	@Param			limit		query		int		false	"Limit"
+//	@Success		200			{object}	[]response.GetAuditRecordResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		403			{string}	Forbidden
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/audit [get]
+func (h *Handler) GetAuditRecords(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	records, err := h.Service.GetAuditRecords(req.Context(), req.URL.Query().Get("entity_type"), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching audit records: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(records, mapper.MapAuditRecordToGetAuditRecordResponse))
+	rw.WriteHeader(http.StatusOK)
+}
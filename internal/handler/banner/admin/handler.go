@@ -6,8 +6,10 @@ import (
 	"avito-backend-trainee-2024/internal/handler/request"
 	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -21,11 +23,17 @@ import (
 )
 
 type Service interface {
-	GetAllBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error)
-	GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int) (*entity.Banner, error)
+	GetAllBanners(ctx context.Context, featureID, offset, limit int) ([]*entity.Banner, error)
+	GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester) (*entity.Banner, error)
 	CreateBanner(ctx context.Context, banner entity.Banner) (*entity.Banner, error)
 	UpdateBanner(ctx context.Context, id int, updateModel entity.Banner) error
+	CloneBanner(ctx context.Context, id int, overrides entity.Banner) (*entity.Banner, error)
 	DeleteBanner(ctx context.Context, id int) (*entity.Banner, error)
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+	GetCoverageReport(ctx context.Context, featureID int) (*entity.CoverageReport, error)
+	GetMisses(ctx context.Context, featureID, offset, limit int) ([]*entity.BannerMiss, error)
+	GetArchivedBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error)
 }
 
 type Middleware = func(http.Handler) http.Handler
@@ -55,7 +63,11 @@ func (h *Handler) Routes() *chi.Mux {
 
 		r.Get("/", h.GetAllBanners)
 		r.Post("/", h.CreateBanner)
+		r.Get("/coverage", h.GetCoverageReport)
+		r.Get("/misses", h.GetMisses)
+		r.Get("/archived", h.GetArchivedBanners)
 		r.Patch("/{id}", h.UpdateBanner)
+		r.Post("/{id}/clone", h.CloneBanner)
 		r.Delete("/{id}", h.DeleteBanner)
 	})
 
@@ -65,14 +77,15 @@ func (h *Handler) Routes() *chi.Mux {
 // GetAllBanners godoc
 //
 //	@Summary		Get all banners
-//	@Description	Get all banners sorting by featureID
+//	@Description	Get all banners sorting by their features, banners may be filtered by feature they are shown in
 //	@Security		JWT
 //	@Tags			Banner
 //	@Accept			json
 //	@Produce		json
 //	@Param token 	header string true "admin auth token"
-//	@Param			offset	query		int	true	"Offset"
-//	@Param			limit	query		int	true	"Limit"
+//	@Param			feature_id	query		int	false	"id of the feature, banners of all features are returned if not provided"
+//	@Param			offset		query		int	true	"Offset"
+//	@Param			limit		query		int	true	"Limit"
 //	@Success		200		{object}	[]response.GetAdminBannerResponse
 //	@Failure		401		{string}	Unauthorized
 //	@Failure		403		{string}	Forbidden
@@ -89,7 +102,21 @@ func (h *Handler) GetAllBanners(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	banners, err := h.Service.GetAllBanners(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	featureID := 0
+
+	if req.URL.Query().Has("feature_id") {
+		var err error
+
+		featureID, err = handlerutils.GetIntParamFromQuery(req, "feature_id")
+		if err != nil {
+			msg := fmt.Sprintf("error occurred getting 'feature_id' query param: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+	}
+
+	banners, err := h.Service.GetAllBanners(req.Context(), featureID, paginationOpts.Offset, paginationOpts.Limit)
 	if err != nil {
 		msg := fmt.Sprintf("error occurred fetching banners: %v", err)
 
@@ -104,7 +131,7 @@ func (h *Handler) GetAllBanners(rw http.ResponseWriter, req *http.Request) {
 // CreateBanner godoc
 //
 //	@Summary		Create new banner
-//	@Description	Create new banner
+//	@Description	Create new banner shown in one or several features, features and tags may be referred by slugs instead of ids
 //	@Security		JWT
 //	@Tags			Banner
 //	@Accept			json
@@ -115,6 +142,7 @@ func (h *Handler) GetAllBanners(rw http.ResponseWriter, req *http.Request) {
 //	@Failure		401		{string}	Unauthorized
 //	@Failure		403		{string}	Forbidden
 //	@Failure		400		{string}	invalid		request
+//	@Failure		409		{string}	conflict	banners
 //	@Failure		500		{string}	internal	error
 //	@Router			/avito-trainee/api/v1/banner [post]
 func (h *Handler) CreateBanner(rw http.ResponseWriter, req *http.Request) {
@@ -127,6 +155,13 @@ func (h *Handler) CreateBanner(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := h.resolveSlugs(req.Context(), bannerReq.FeatureSlugs, bannerReq.TagSlugs, &bannerReq.FeatureIDs, &bannerReq.TagIDs); err != nil {
+		msg := fmt.Sprintf("error occurred resolving slugs of CreateBannerRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
 	if err := bannerReq.Validate(h.validator); err != nil {
 		msg := fmt.Sprintf("error occurred validating CreateBannerRequest struct: %v", err)
 
@@ -138,7 +173,7 @@ func (h *Handler) CreateBanner(rw http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		msg := fmt.Sprintf("error occurred creating banner: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, handlerinternalutils.GetBannerChangeErrStatus(err), msg, msg)
 		return
 	}
 
@@ -149,7 +184,7 @@ func (h *Handler) CreateBanner(rw http.ResponseWriter, req *http.Request) {
 // UpdateBanner godoc
 //
 //	@Summary		Update existing banner
-//	@Description	Update existing banner
+//	@Description	Update existing banner, features and tags may be referred by slugs instead of ids
 //	@Security		JWT
 //	@Tags			Banner
 //	@Accept			json
@@ -161,6 +196,7 @@ func (h *Handler) CreateBanner(rw http.ResponseWriter, req *http.Request) {
 //	@Failure		401	{string}	Unauthorized
 //	@Failure		403	{string}	Forbidden
 //	@Failure		400	{string}	invalid		request
+//	@Failure		409	{string}	conflict	banners
 //	@Failure		500	{string}	internal	error
 //	@Router			/avito-trainee/api/v1/banner/{id} [patch]
 func (h *Handler) UpdateBanner(rw http.ResponseWriter, req *http.Request) {
@@ -181,6 +217,13 @@ func (h *Handler) UpdateBanner(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err = h.resolveSlugs(req.Context(), updateReq.FeatureSlugs, updateReq.TagSlugs, &updateReq.FeatureIDs, &updateReq.TagIDs); err != nil {
+		msg := fmt.Sprintf("error occurred resolving slugs of UpdateBannerRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
 	if err = updateReq.Validate(h.validator); err != nil {
 		msg := fmt.Sprintf("error occurred validating UpdateBannerRequest struct: %v", err)
 
@@ -191,13 +234,75 @@ func (h *Handler) UpdateBanner(rw http.ResponseWriter, req *http.Request) {
 	if err = h.Service.UpdateBanner(req.Context(), id, mapper.MapUpdateBannerRequestToEntity(&updateReq)); err != nil {
 		msg := fmt.Sprintf("error occurred updating banner: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, handlerinternalutils.GetBannerChangeErrStatus(err), msg, msg)
 		return
 	}
 
 	rw.WriteHeader(http.StatusOK)
 }
 
+// CloneBanner godoc
+//
+//	@Summary		Clone banner
+//	@Description	Create inactive copy of banner, fields provided in body replace copied ones, features and tags may be referred by slugs instead of ids
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.CloneBannerRequest	false	"clone banner overrides schema"
+//	@Param			id		path		int							true	"id of the cloned banner"
+//	@Success		201		{object}	response.CreateBannerResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/{id}/clone [post]
+func (h *Handler) CloneBanner(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var cloneReq request.CloneBannerRequest
+
+	// body is optional, banner is copied as is without it
+	if err = render.DecodeJSON(req.Body, &cloneReq); err != nil && !errors.Is(err, io.EOF) {
+		msg := fmt.Sprintf("error occurred decoding request body to CloneBannerRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.resolveSlugs(req.Context(), cloneReq.FeatureSlugs, cloneReq.TagSlugs, &cloneReq.FeatureIDs, &cloneReq.TagIDs); err != nil {
+		msg := fmt.Sprintf("error occurred resolving slugs of CloneBannerRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = cloneReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CloneBannerRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CloneBanner(req.Context(), id, mapper.MapCloneBannerRequestToEntity(&cloneReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred cloning banner: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapBannerToCreateBannerResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
 // DeleteBanner godoc
 //
 //	@Summary		Delete banner
@@ -233,3 +338,168 @@ func (h *Handler) DeleteBanner(rw http.ResponseWriter, req *http.Request) {
 
 	rw.WriteHeader(http.StatusOK)
 }
+
+// GetCoverageReport godoc
+//
+//	@Summary		Get coverage report
+//	@Description	Get combinations of feature and tags requested by users with no served banner and combinations which banners are all inactive or expired
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			feature_id	query		int	false	"id of the feature, all features are reported if not provided"
+//	@Success		200			{object}	response.CoverageReportResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		403			{string}	Forbidden
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/coverage [get]
+func (h *Handler) GetCoverageReport(rw http.ResponseWriter, req *http.Request) {
+	featureID := 0
+
+	if req.URL.Query().Has("feature_id") {
+		var err error
+
+		featureID, err = handlerutils.GetIntParamFromQuery(req, "feature_id")
+		if err != nil {
+			msg := fmt.Sprintf("error occurred getting 'feature_id' query param: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+	}
+
+	report, err := h.Service.GetCoverageReport(req.Context(), featureID)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred building coverage report: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapCoverageReportToResponse(report))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetMisses godoc
+//
+//	@Summary		Get banner misses
+//	@Description	Get combinations of feature and tags requested by users with no banner found, the most frequent first
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			feature_id	query		int	false	"id of the feature, misses of all features are returned if not provided"
+//	@Param			offset		query		int	false	"Offset"
+//	@Param			limit		query		int	false	"Limit"
+//	@Success		200			{object}	[]response.BannerMissResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		403			{string}	Forbidden
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/misses [get]
+func (h *Handler) GetMisses(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	featureID := 0
+
+	if req.URL.Query().Has("feature_id") {
+		var err error
+
+		featureID, err = handlerutils.GetIntParamFromQuery(req, "feature_id")
+		if err != nil {
+			msg := fmt.Sprintf("error occurred getting 'feature_id' query param: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+	}
+
+	misses, err := h.Service.GetMisses(req.Context(), featureID, paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching banner misses: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(misses, mapper.MapBannerMissToResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// resolveSlugs replaces feature ids and tag ids with ids of features and tags referred by slugs if they are provided
+func (h *Handler) resolveSlugs(ctx context.Context, featureSlugs, tagSlugs []string, featureIDs, tagIDs *[]int) error {
+	if len(featureSlugs) != 0 {
+		IDs := make([]int, 0, len(featureSlugs))
+
+		for _, slug := range featureSlugs {
+			id, err := h.Service.GetFeatureIDBySlug(ctx, slug)
+			if err != nil {
+				return err
+			}
+
+			IDs = append(IDs, id)
+		}
+
+		*featureIDs = IDs
+	}
+
+	if len(tagSlugs) != 0 {
+		IDs, err := h.Service.GetTagIDsBySlugs(ctx, tagSlugs)
+		if err != nil {
+			return err
+		}
+
+		*tagIDs = IDs
+	}
+
+	return nil
+}
+
+// GetArchivedBanners godoc
+//
+//	@Summary		Get archived banners
+//	@Description	Get banners archived by sweep for being inactive and untouched for long, updating banner restores it
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset		query		int	false	"Offset"
+//	@Param			limit		query		int	false	"Limit"
+//	@Success		200			{object}	[]response.GetAdminBannerResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		403			{string}	Forbidden
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/archived [get]
+func (h *Handler) GetArchivedBanners(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	banners, err := h.Service.GetArchivedBanners(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching archived banners: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusInternalServerError, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(banners, mapper.MapBannerToAdminBannerResponse))
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,183 @@
+package explain
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/middleware"
+	"avito-backend-trainee-2024/internal/handler/response"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/sirupsen/logrus"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	ExplainBannerResolution(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester) ([]*entity.BannerCandidate, error)
+	IsFeatureEnabled(ctx context.Context, featureID int) (bool, error)
+}
+
+type Cache = middleware.Cache
+
+type Middleware = func(http.Handler) http.Handler
+
+// impersonationParams are query params describing requester, they are not part of user banner request
+var impersonationParams = []string{"user_id", "country", "region", "platform", "app_version"}
+
+type Handler struct {
+	Service     Service
+	Cache       Cache
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, cache Cache, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Cache:       cache,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.ExplainBannerResolution)
+	})
+
+	return router
+}
+
+// isCached reports if user banner request of requester would be served from cache, cached banners of disabled
+// feature and expired ones are not served
+func (h *Handler) isCached(req *http.Request, featureID int, requester entity.Requester) (bool, error) {
+	enabled, err := h.Service.IsFeatureEnabled(req.Context(), featureID)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	query := req.URL.Query()
+
+	for _, param := range impersonationParams {
+		query.Del(param)
+	}
+
+	query.Del("use_last_revision")
+
+	_, found := middleware.PeekCachedUserBanner(h.Cache, middleware.UserBannerCacheKey(middleware.UserBannerLookupRoute, query, requester))
+
+	return found, nil
+}
+
+// ExplainBannerResolution godoc
+//
+//	@Summary		Explain banner resolution
+//	@Description	Get every banner of the feature with the reason it is returned to user or not
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			feature_id		query		string		false	"id of the feature, required if feature_slug is not provided"
+//	@Param			tag_ids			query		[]int		false	"ids of the tags, required if tag_slugs are not provided"
+//	@Param			feature_slug	query		string		false	"slug of the feature, used instead of feature_id"
+//	@Param			tag_slugs		query		[]string	false	"slugs of the tags, used instead of tag_ids"
+//	@Param			user_id		query		int		false	"id of the impersonated user, admin's id by default"
+//	@Param			country		query		string	false	"country of the impersonated user"
+//	@Param			region		query		string	false	"region of the impersonated user"
+//	@Param			platform	query		string	false	"platform of the impersonated user"
+//	@Param			app_version	query		string	false	"app version of the impersonated user"
+//	@Success		200			{object}	response.ExplainBannerResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		403			{string}	Forbidden
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/user_banner/explain [get]
+func (h *Handler) ExplainBannerResolution(rw http.ResponseWriter, req *http.Request) {
+	featureID, err := handlerutils.GetIntParamFromQuery(req, "feature_id")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting 'feature_id' query param: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	tagIDs, err := handlerutils.GetIntArrayParamFromQuery(req, "tag_ids")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting 'tag_ids' query param: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	// id header of admin is set by authentication middleware
+	userID, err := handlerutils.GetIntHeaderByKey(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting user id: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusUnauthorized, msg, msg)
+		return
+	}
+
+	if req.URL.Query().Has("user_id") {
+		userID, err = handlerutils.GetIntParamFromQuery(req, "user_id")
+		if err != nil {
+			msg := fmt.Sprintf("error occurred getting 'user_id' query param: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+	}
+
+	requester := entity.Requester{
+		UserID:     userID,
+		Country:    req.URL.Query().Get("country"),
+		Region:     req.URL.Query().Get("region"),
+		Platform:   req.URL.Query().Get("platform"),
+		AppVersion: req.URL.Query().Get("app_version"),
+	}
+
+	candidates, err := h.Service.ExplainBannerResolution(req.Context(), featureID, tagIDs, requester)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred explaining banner resolution: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	cacheHit, err := h.isCached(req, featureID, requester)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred checking if feature is enabled: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusInternalServerError, msg, "")
+		return
+	}
+
+	resp := response.ExplainBannerResponse{
+		FeatureID:  featureID,
+		TagIDs:     tagIDs,
+		UserID:     requester.UserID,
+		Country:    requester.Country,
+		Region:     requester.Region,
+		Platform:   requester.Platform,
+		AppVersion: requester.AppVersion,
+		CacheHit:   cacheHit,
+		Candidates: sliceutils.Map(candidates, mapper.MapBannerCandidateToResponse),
+	}
+
+	render.JSON(rw, req, resp)
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,6 @@
+package linkcheck
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -0,0 +1,94 @@
+package linkcheck
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetBrokenLinks(ctx context.Context, offset, limit int) ([]*entity.LinkCheck, error)
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetBrokenLinks)
+	})
+
+	return router
+}
+
+// GetBrokenLinks godoc
+//
+//	@Summary		Get banners with broken links
+//	@Description	Get the last checks of active banners which urls can not be reached or respond with error, the latest checked first,
+//	@Description	urls are checked periodically in background
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetLinkCheckResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/broken_links [get]
+func (h *Handler) GetBrokenLinks(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	checks, err := h.Service.GetBrokenLinks(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching broken links: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusInternalServerError, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(checks, mapper.MapLinkCheckToGetLinkCheckResponse))
+	rw.WriteHeader(http.StatusOK)
+}
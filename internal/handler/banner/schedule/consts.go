@@ -0,0 +1,6 @@
+package schedule
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -0,0 +1,236 @@
+package schedule
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetScheduledEdits(ctx context.Context, bannerID, offset, limit int) ([]*entity.ScheduledEdit, error)
+	ScheduleEdit(ctx context.Context, edit entity.ScheduledEdit) (*entity.ScheduledEdit, error)
+	CancelScheduledEdit(ctx context.Context, id int) error
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetScheduledEdits)
+		r.Post("/", h.ScheduleEdit)
+		r.Delete("/{id}", h.CancelScheduledEdit)
+	})
+
+	return router
+}
+
+// GetScheduledEdits godoc
+//
+//	@Summary		Get scheduled banner edits
+//	@Description	Get edits of banners which are not applied yet in the order they are applied,
+//	@Description	edits failed to apply several times are listed with the last error and are not retried
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			banner_id	query		int	false	"id of the banner, edits of all banners are returned if not provided"
+//	@Param			offset		query		int	false	"Offset"
+//	@Param			limit		query		int	false	"Limit"
+//	@Success		200			{object}	[]response.GetScheduledEditResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		403			{string}	Forbidden
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/scheduled_edits [get]
+func (h *Handler) GetScheduledEdits(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	bannerID := 0
+
+	if req.URL.Query().Has("banner_id") {
+		var err error
+
+		bannerID, err = handlerutils.GetIntParamFromQuery(req, "banner_id")
+		if err != nil {
+			msg := fmt.Sprintf("error occurred getting 'banner_id' query param: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+	}
+
+	edits, err := h.Service.GetScheduledEdits(req.Context(), bannerID, paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching scheduled edits: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(edits, mapper.MapScheduledEditToGetScheduledEditResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// ScheduleEdit godoc
+//
+//	@Summary		Schedule banner edit
+//	@Description	Schedule edit of existing banner applied at the given time, patch has the same shape as banner update,
+//	@Description	features and tags may be referred by slugs instead of ids, edit is validated at once and when it is applied
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.ScheduleEditRequest	true	"schedule edit schema"
+//	@Success		200		{object}	response.CreateScheduledEditResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/scheduled_edits [post]
+func (h *Handler) ScheduleEdit(rw http.ResponseWriter, req *http.Request) {
+	var editReq request.ScheduleEditRequest
+
+	if err := render.DecodeJSON(req.Body, &editReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to ScheduleEditRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	patch := &editReq.Patch
+
+	if err := h.resolveSlugs(req.Context(), patch.FeatureSlugs, patch.TagSlugs, &patch.FeatureIDs, &patch.TagIDs); err != nil {
+		msg := fmt.Sprintf("error occurred resolving slugs of ScheduleEditRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := editReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating ScheduleEditRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.ScheduleEdit(req.Context(), mapper.MapScheduleEditRequestToEntity(&editReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred scheduling banner edit: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapScheduledEditToCreateScheduledEditResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// CancelScheduledEdit godoc
+//
+//	@Summary		Cancel scheduled banner edit
+//	@Description	Cancel edit which is not applied yet
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the scheduled edit"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/banner/scheduled_edits/{id} [delete]
+func (h *Handler) CancelScheduledEdit(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.CancelScheduledEdit(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred canceling scheduled edit: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// resolveSlugs replaces feature ids and tag ids with ids of features and tags referred by slugs if they are provided
+func (h *Handler) resolveSlugs(ctx context.Context, featureSlugs, tagSlugs []string, featureIDs, tagIDs *[]int) error {
+	if len(featureSlugs) != 0 {
+		IDs := make([]int, 0, len(featureSlugs))
+
+		for _, slug := range featureSlugs {
+			id, err := h.Service.GetFeatureIDBySlug(ctx, slug)
+			if err != nil {
+				return err
+			}
+
+			IDs = append(IDs, id)
+		}
+
+		*featureIDs = IDs
+	}
+
+	if len(tagSlugs) != 0 {
+		IDs, err := h.Service.GetTagIDsBySlugs(ctx, tagSlugs)
+		if err != nil {
+			return err
+		}
+
+		*tagIDs = IDs
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package user
+
+const (
+	MaxBannersLimit = 20
+
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -4,36 +4,52 @@ import (
 	"avito-backend-trainee-2024/internal/domain/entity"
 	"avito-backend-trainee-2024/internal/handler/mapper"
 	"avito-backend-trainee-2024/internal/handler/middleware"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
 	"context"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
 	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
-	urlutils "avito-backend-trainee-2024/pkg/utils/url"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
 )
 
 type Service interface {
-	GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int) (*entity.Banner, error)
+	GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester) (*entity.Banner, error)
+	GetBannersByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester, limit int) ([]*entity.Banner, error)
+	GetUserBanners(ctx context.Context, tagIDs []int, requester entity.Requester, offset, limit int) ([]*entity.FeatureBanners, error)
+	GetBannersByQueries(ctx context.Context, queries []entity.BannerQuery, requester entity.Requester) ([]*entity.BannerQueryResult, error)
+	IsFeatureEnabled(ctx context.Context, featureID int) (bool, error)
+	ClaimImpressions(ctx context.Context, campaignIDs []int) (bool, error)
 }
 
+// Cache is shared with user banner cache middleware, so batch queries are served from the same entries
+type Cache = middleware.Cache
+
 type Middleware = func(http.Handler) http.Handler
 
 type Handler struct {
 	Service     Service
+	Cache       Cache
 	Middlewares []Middleware
 
 	logger    *logrus.Logger
 	validator *validator.Validate
 }
 
-func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+func New(service Service, cache Cache, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
 	return &Handler{
 		Service:     service,
+		Cache:       cache,
 		Middlewares: middlewares,
 		logger:      logger,
 		validator:   validator,
@@ -47,6 +63,8 @@ func (h *Handler) Routes() *chi.Mux {
 		r.Use(h.Middlewares...)
 
 		r.Get("/", h.GetBannerByFeatureAndTags)
+		r.Get("/all", h.GetUserBanners)
+		r.Post("/batch", h.GetBannersByQueries)
 	})
 
 	return router
@@ -55,16 +73,22 @@ func (h *Handler) Routes() *chi.Mux {
 // GetBannerByFeatureAndTags godoc
 //
 //	@Summary		Get banner with feature and tags
-//	@Description	Get banner with feature and tags
+//	@Description	Get banner with feature and tags, banners tagged with ancestors of the tags are matched if no closer banner exists.
+//	@Description	If limit is provided, list of up to limit active banners is returned in the order they are preferred
 //	@Security		JWT
 //	@Tags			Banner
 //	@Accept			json
 //	@Produce		json
 //	@Param token 	header string true "user auth token"
-//	@Param			feature_id	query		string	true	"id of the feature"
-//	@Param			tag_ids		query		[]int	true	"ids of the tags"
+//	@Param			feature_id		query		string		false	"id of the feature, required if feature_slug is not provided"
+//	@Param			tag_ids			query		[]int		false	"ids of the tags, required if tag_slugs are not provided"
+//	@Param			feature_slug	query		string		false	"slug of the feature, used instead of feature_id"
+//	@Param			tag_slugs		query		[]string	false	"slugs of the tags, used instead of tag_ids"
 //	@Param			use_last_revision		query		bool	true	"use last revision?"
-//	@Success		200			{object}	response.GetUserBannerResponse
+//	@Param			limit			query		int		false	"max count of returned banners, list of banners is returned if provided"
+//	@Param			X-Platform		header		string	false	"client platform: android, ios or web, parsed from User-Agent if not provided"
+//	@Param			X-App-Version	header		string	false	"client app version, parsed from User-Agent if not provided"
+//	@Success		200			{object}	response.GetUserBannerResponse	"banner or list of banners if limit is provided"
 //	@Failure		401			{string}	Unauthorized
 //	@Failure		400			{string}	invalid		request
 //	@Failure		403			{string}	invalid		request
@@ -87,7 +111,30 @@ func (h *Handler) GetBannerByFeatureAndTags(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	banner, err := h.Service.GetBannerByFeatureAndTags(req.Context(), featureID, tagIDs)
+	// id header is set by authentication middleware
+	userID, err := handlerutils.GetIntHeaderByKey(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting user id: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusUnauthorized, msg, msg)
+		return
+	}
+
+	// location and platform headers are set by geo location and client platform middlewares
+	requester := entity.Requester{
+		UserID:     userID,
+		Country:    req.Header.Get("country"),
+		Region:     req.Header.Get("region"),
+		Platform:   req.Header.Get("platform"),
+		AppVersion: req.Header.Get("app_version"),
+	}
+
+	if req.URL.Query().Has("limit") {
+		h.getBannersByFeatureAndTags(rw, req, featureID, tagIDs, requester)
+		return
+	}
+
+	banner, err := h.Service.GetBannerByFeatureAndTags(req.Context(), featureID, tagIDs, requester)
 	if err != nil {
 		msg := fmt.Sprintf("error occurred fetching banner: %v", err)
 
@@ -95,8 +142,10 @@ func (h *Handler) GetBannerByFeatureAndTags(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	// return to users only active banners, if user = admin, then return anyway
-	if !banner.IsActive && req.Header.Get("is_admin") != "true" {
+	// return to users only active banners, if user = admin or banner is pinned to user, then return anyway
+	isActive := banner.IsActive || banner.IsOverridden
+
+	if !isActive && req.Header.Get("is_admin") != "true" {
 		msg := "banner is inactive"
 
 		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusForbidden, msg, msg)
@@ -105,13 +154,292 @@ func (h *Handler) GetBannerByFeatureAndTags(rw http.ResponseWriter, req *http.Re
 
 	resp := mapper.MapBannerToUserBannerResponse(banner)
 
-	if middlewareData, ok := req.Context().
-		Value(urlutils.RemoveQueryParamByKey(*req.URL, "use_last_revision").
-			RequestURI()).(middleware.MiddlewareData); ok {
-		middlewareData["banner"] = resp
-		middlewareData["is_active"] = banner.IsActive
+	// impressions of cached campaign banners are claimed each time they are served
+	if middlewareData, ok := req.Context().Value(middleware.RequestCacheKey(req)).(middleware.MiddlewareData); ok {
+		middlewareData["banner"] = middleware.NewCachedUserBanner(resp, isActive, banner)
 	}
 
 	render.JSON(rw, req, resp)
 	rw.WriteHeader(http.StatusOK)
 }
+
+// getBannersByFeatureAndTags responds with list of banners for carousels and other slots showing several banners at once
+func (h *Handler) getBannersByFeatureAndTags(rw http.ResponseWriter, req *http.Request, featureID int, tagIDs []int,
+	requester entity.Requester) {
+	limit, err := handlerutils.GetIntParamFromQuery(req, "limit")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting 'limit' query param: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if limit < 1 || limit > MaxBannersLimit {
+		msg := fmt.Sprintf("'limit' query param must be between 1 and %v", MaxBannersLimit)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	banners, err := h.Service.GetBannersByFeatureAndTags(req.Context(), featureID, tagIDs, requester, limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching banners: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	resp := sliceutils.Map(banners, mapper.MapBannerToUserBannerResponse)
+
+	// listed banners are all shown to users
+	if middlewareData, ok := req.Context().Value(middleware.RequestCacheKey(req)).(middleware.MiddlewareData); ok {
+		middlewareData["banner"] = middleware.NewCachedUserBanner(resp, true, banners...)
+	}
+
+	render.JSON(rw, req, resp)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetUserBanners godoc
+//
+//	@Summary		Get banners of all features with tags
+//	@Description	Get active banners with tags grouped by feature, so apps can prefetch banners of all slots at once,
+//	@Description	banners of each feature are ranked the same way as by limit param of single feature lookup,
+//	@Description	features are paginated, so page may lack features left without banners shown to user
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "user auth token"
+//	@Param			tag_ids			query		[]int		false	"ids of the tags, required if tag_slugs are not provided"
+//	@Param			tag_slugs		query		[]string	false	"slugs of the tags, used instead of tag_ids"
+//	@Param			use_last_revision		query		bool	true	"use last revision?"
+//	@Param			offset		query		int	false	"Offset of features"
+//	@Param			limit		query		int	false	"Limit of features"
+//	@Param			X-Platform		header		string	false	"client platform: android, ios or web, parsed from User-Agent if not provided"
+//	@Param			X-App-Version	header		string	false	"client app version, parsed from User-Agent if not provided"
+//	@Success		200			{object}	[]response.GetFeatureBannersResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/user_banner/all [get]
+func (h *Handler) GetUserBanners(rw http.ResponseWriter, req *http.Request) {
+	tagIDs, err := handlerutils.GetIntArrayParamFromQuery(req, "tag_ids")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting 'tag_ids' query param: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	// id header is set by authentication middleware
+	userID, err := handlerutils.GetIntHeaderByKey(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting user id: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusUnauthorized, msg, msg)
+		return
+	}
+
+	requester := entity.Requester{
+		UserID:     userID,
+		Country:    req.Header.Get("country"),
+		Region:     req.Header.Get("region"),
+		Platform:   req.Header.Get("platform"),
+		AppVersion: req.Header.Get("app_version"),
+	}
+
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	featureBanners, err := h.Service.GetUserBanners(req.Context(), tagIDs, requester, paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching banners: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	resp := sliceutils.Map(featureBanners, mapper.MapFeatureBannersToResponse)
+
+	var banners []*entity.Banner
+
+	for _, featureBanner := range featureBanners {
+		banners = append(banners, featureBanner.Banners...)
+	}
+
+	if middlewareData, ok := req.Context().Value(middleware.RequestCacheKey(req)).(middleware.MiddlewareData); ok {
+		middlewareData["banner"] = middleware.NewCachedUserBanner(resp, true, banners...)
+	}
+
+	render.JSON(rw, req, resp)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetBannersByQueries godoc
+//
+//	@Summary		Get banners of several features and tags
+//	@Description	Get banner for each of queries in one request, results are keyed by query key or by 'feature_id=<id>&tag_ids=<ids>'
+//	@Description	if key is not provided, banners are resolved the same way as by single lookup and share its cache
+//	@Security		JWT
+//	@Tags			Banner
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "user auth token"
+//	@Param			input				body		request.BatchUserBannerRequest	true	"batch of user banner queries"
+//	@Param			use_last_revision	query		bool							false	"use last revision?"
+//	@Param			X-Platform		header		string	false	"client platform: android, ios or web, parsed from User-Agent if not provided"
+//	@Param			X-App-Version	header		string	false	"client app version, parsed from User-Agent if not provided"
+//	@Success		200			{object}	map[string]response.BatchUserBannerResultResponse
+//	@Failure		401			{string}	Unauthorized
+//	@Failure		400			{string}	invalid		request
+//	@Failure		500			{string}	internal	error
+//	@Router			/avito-trainee/api/v1/user_banner/batch [post]
+func (h *Handler) GetBannersByQueries(rw http.ResponseWriter, req *http.Request) {
+	var batchReq request.BatchUserBannerRequest
+
+	if err := render.DecodeJSON(req.Body, &batchReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to BatchUserBannerRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := batchReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating BatchUserBannerRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	// id header is set by authentication middleware
+	userID, err := handlerutils.GetIntHeaderByKey(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting user id: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusUnauthorized, msg, msg)
+		return
+	}
+
+	requester := entity.Requester{
+		UserID:     userID,
+		Country:    req.Header.Get("country"),
+		Region:     req.Header.Get("region"),
+		Platform:   req.Header.Get("platform"),
+		AppVersion: req.Header.Get("app_version"),
+	}
+
+	useLastRevision := req.URL.Query().Get("use_last_revision") == "true"
+	isAdmin := req.Header.Get("is_admin") == "true"
+
+	results := make(map[string]response.BatchUserBannerResultResponse, len(batchReq.Queries))
+
+	// queries not served from cache are resolved at once
+	var (
+		queries   []entity.BannerQuery
+		keys      []string
+		cacheKeys []string
+	)
+
+	seen := make(map[string]bool, len(batchReq.Queries))
+
+	for _, query := range batchReq.Queries {
+		key := query.Key
+		if key == "" {
+			key = fmt.Sprintf("feature_id=%v&tag_ids=%v", query.FeatureID, joinIDs(query.TagIDs))
+		}
+
+		if seen[key] {
+			msg := fmt.Sprintf("duplicate query key provided: %v", key)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+
+		seen[key] = true
+
+		cacheKey := middleware.UserBannerCacheKey(middleware.UserBannerLookupRoute, url.Values{
+			"feature_id": {strconv.Itoa(query.FeatureID)},
+			"tag_ids":    {joinIDs(query.TagIDs)},
+		}, requester)
+
+		if !useLastRevision {
+			result, found, err := h.getCachedResult(req.Context(), cacheKey, query.FeatureID, isAdmin)
+			if err != nil {
+				msg := fmt.Sprintf("error occurred getting cached banner: %v", err)
+
+				handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusInternalServerError, msg, "")
+				return
+			}
+
+			if found {
+				results[key] = result
+				continue
+			}
+		}
+
+		queries = append(queries, entity.BannerQuery{FeatureID: query.FeatureID, TagIDs: query.TagIDs})
+		keys = append(keys, key)
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+
+	if len(queries) != 0 {
+		resolved, err := h.Service.GetBannersByQueries(req.Context(), queries, requester)
+		if err != nil {
+			msg := fmt.Sprintf("error occurred fetching banners: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+
+		for i, result := range resolved {
+			results[keys[i]] = h.cacheResult(result, cacheKeys[i], isAdmin)
+		}
+	}
+
+	render.JSON(rw, req, results)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// getCachedResult returns result of the query cached by single lookup, banners of disabled features are not served
+func (h *Handler) getCachedResult(ctx context.Context, key string, featureID int, isAdmin bool) (response.BatchUserBannerResultResponse, bool, error) {
+	cached, found, err := middleware.GetCachedUserBanner(ctx, h.Cache, h.Service, h.Service, key, featureID)
+	if err != nil || !found {
+		return response.BatchUserBannerResultResponse{}, false, err
+	}
+
+	// list of banners is cached by lookup with 'limit' query param
+	banner, ok := cached.Response.(response.GetUserBannerResponse)
+	if !ok {
+		return response.BatchUserBannerResultResponse{}, false, nil
+	}
+
+	if !cached.IsActive && !isAdmin {
+		return response.BatchUserBannerResultResponse{Error: "banner is inactive"}, true, nil
+	}
+
+	return response.BatchUserBannerResultResponse{Banner: &banner}, true, nil
+}
+
+// cacheResult caches banner found for the query the same way cache middleware does and maps it to response
+func (h *Handler) cacheResult(result *entity.BannerQueryResult, key string, isAdmin bool) response.BatchUserBannerResultResponse {
+	if result.Err != nil {
+		return response.BatchUserBannerResultResponse{Error: result.Err.Error()}
+	}
+
+	banner := mapper.MapBannerToUserBannerResponse(result.Banner)
+
+	// return to users only active banners, if user = admin or banner is pinned to user, then return anyway
+	isActive := result.Banner.IsActive || result.Banner.IsOverridden
+
+	middleware.CacheUserBanner(h.Cache, key, middleware.NewCachedUserBanner(banner, isActive, result.Banner))
+
+	if !isActive && !isAdmin {
+		return response.BatchUserBannerResultResponse{Error: "banner is inactive"}
+	}
+
+	return response.BatchUserBannerResultResponse{Banner: &banner}
+}
+
+func joinIDs(IDs []int) string {
+	return strings.Join(sliceutils.Map(IDs, strconv.Itoa), ",")
+}
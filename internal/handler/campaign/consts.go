@@ -0,0 +1,6 @@
+package campaign
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -0,0 +1,273 @@
+package campaign
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllCampaigns(ctx context.Context, offset, limit int) ([]*entity.Campaign, error)
+	GetCampaignByID(ctx context.Context, id int) (*entity.Campaign, error)
+	CreateCampaign(ctx context.Context, campaign entity.Campaign) (*entity.Campaign, error)
+	UpdateCampaign(ctx context.Context, id int, updateModel entity.Campaign) error
+	DeleteCampaign(ctx context.Context, id int) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllCampaigns)
+		r.Post("/", h.CreateCampaign)
+		r.Get("/{id}", h.GetCampaign)
+		r.Patch("/{id}", h.UpdateCampaign)
+		r.Delete("/{id}", h.DeleteCampaign)
+	})
+
+	return router
+}
+
+// GetAllCampaigns godoc
+//
+//	@Summary		Get all campaigns
+//	@Description	Get all campaigns sorting by id with impressions counted so far
+//	@Security		JWT
+//	@Tags			Campaign
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetCampaignResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/campaign [get]
+func (h *Handler) GetAllCampaigns(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	campaigns, err := h.Service.GetAllCampaigns(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching campaigns: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(campaigns, mapper.MapCampaignToGetCampaignResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetCampaign godoc
+//
+//	@Summary		Get campaign
+//	@Description	Get campaign with its schedule, targeting defaults, impressions and count of its banners
+//	@Security		JWT
+//	@Tags			Campaign
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path		int	true	"id of the campaign"
+//	@Success		200	{object}	response.GetCampaignResponse
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/campaign/{id} [get]
+func (h *Handler) GetCampaign(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	campaign, err := h.Service.GetCampaignByID(req.Context(), id)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching campaign: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapCampaignToGetCampaignResponse(campaign))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CreateCampaign godoc
+//
+//	@Summary		Create new campaign
+//	@Description	Create new campaign, banners of the campaign are shown only between its start and end and until impressions budget is spent,
+//	@Description	campaign targeting is applied to its banners having no targeting of their own, budget 0 means no budget
+//	@Security		JWT
+//	@Tags			Campaign
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.CreateCampaignRequest	true	"create campaign schema"
+//	@Success		200		{object}	response.CreateCampaignResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/campaign [post]
+func (h *Handler) CreateCampaign(rw http.ResponseWriter, req *http.Request) {
+	var campaignReq request.CreateCampaignRequest
+
+	if err := render.DecodeJSON(req.Body, &campaignReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateCampaignRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := campaignReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateCampaignRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CreateCampaign(req.Context(), mapper.MapCreateCampaignRequestToEntity(&campaignReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating campaign: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapCampaignToCreateCampaignResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// UpdateCampaign godoc
+//
+//	@Summary		Update campaign
+//	@Description	Update schedule, budget or targeting of campaign, empty fields are not updated, empty targeting list removes targeting
+//	@Security		JWT
+//	@Tags			Campaign
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body	request.UpdateCampaignRequest	true	"update campaign schema"
+//	@Param			id		path	int								true	"id of the updating campaign"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/campaign/{id} [patch]
+func (h *Handler) UpdateCampaign(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var updateReq request.UpdateCampaignRequest
+
+	if err = render.DecodeJSON(req.Body, &updateReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to UpdateCampaignRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = updateReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating UpdateCampaignRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.UpdateCampaign(req.Context(), id, mapper.MapUpdateCampaignRequestToEntity(&updateReq)); err != nil {
+		msg := fmt.Sprintf("error occurred updating campaign: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// DeleteCampaign godoc
+//
+//	@Summary		Delete campaign
+//	@Description	Delete campaign, its banners are kept and shown on their own
+//	@Security		JWT
+//	@Tags			Campaign
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the campaign"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/campaign/{id} [delete]
+func (h *Handler) DeleteCampaign(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.DeleteCampaign(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred deleting campaign: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,6 @@
+package feature
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -0,0 +1,335 @@
+package feature
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/go-playground/validator/v10"
+
+	"avito-backend-trainee-2024/internal/handler/middleware"
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllFeatures(ctx context.Context, offset, limit int) ([]*entity.Feature, error)
+	GetFeatureByID(ctx context.Context, id int) (*entity.Feature, error)
+	CreateFeature(ctx context.Context, feature entity.Feature) (*entity.Feature, error)
+	UpdateFeature(ctx context.Context, id int, updateModel entity.Feature) error
+	DeleteFeature(ctx context.Context, id int) error
+	SetFeatureEnabled(ctx context.Context, id int, enabled bool) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	// slugCache holds ids resolved from slugs by user banner routes, ids of changed features are dropped from it
+	slugCache *cache.Cache
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, slugCache *cache.Cache, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		slugCache:   slugCache,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllFeatures)
+		r.Post("/", h.CreateFeature)
+		r.Get("/{id}", h.GetFeature)
+		r.Patch("/{id}", h.UpdateFeature)
+		r.Delete("/{id}", h.DeleteFeature)
+		r.Put("/{id}/enabled", h.SetFeatureEnabled)
+	})
+
+	return router
+}
+
+// GetAllFeatures godoc
+//
+//	@Summary		Get all features
+//	@Description	Get all features sorting by id
+//	@Security		JWT
+//	@Tags			Feature
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetFeatureResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/feature [get]
+func (h *Handler) GetAllFeatures(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	features, err := h.Service.GetAllFeatures(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching features: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(features, mapper.MapFeatureToGetFeatureResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetFeature godoc
+//
+//	@Summary		Get feature
+//	@Description	Get feature with its metadata and count of its banners
+//	@Security		JWT
+//	@Tags			Feature
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path		int	true	"id of the feature"
+//	@Success		200	{object}	response.GetFeatureResponse
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/feature/{id} [get]
+func (h *Handler) GetFeature(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	feature, err := h.Service.GetFeatureByID(req.Context(), id)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching feature: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapFeatureToGetFeatureResponse(feature))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CreateFeature godoc
+//
+//	@Summary		Create new feature
+//	@Description	Create new feature
+//	@Security		JWT
+//	@Tags			Feature
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.CreateFeatureRequest	true	"create feature schema"
+//	@Success		200		{object}	response.CreateFeatureResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/feature [post]
+func (h *Handler) CreateFeature(rw http.ResponseWriter, req *http.Request) {
+	var featureReq request.CreateFeatureRequest
+
+	if err := render.DecodeJSON(req.Body, &featureReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateFeatureRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := featureReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateFeatureRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CreateFeature(req.Context(), mapper.MapCreateFeatureRequestToEntity(&featureReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating feature: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapFeatureToCreateFeatureResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// UpdateFeature godoc
+//
+//	@Summary		Update feature
+//	@Description	Rename existing feature or update its slug and metadata, empty fields are not updated
+//	@Security		JWT
+//	@Tags			Feature
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body	request.UpdateFeatureRequest	true	"update feature schema"
+//	@Param			id		path	int								true	"id of the updating feature"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/feature/{id} [patch]
+func (h *Handler) UpdateFeature(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var updateReq request.UpdateFeatureRequest
+
+	if err = render.DecodeJSON(req.Body, &updateReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to UpdateFeatureRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = updateReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating UpdateFeatureRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.UpdateFeature(req.Context(), id, mapper.MapUpdateFeatureRequestToEntity(&updateReq)); err != nil {
+		msg := fmt.Sprintf("error occurred updating feature: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	middleware.ForgetFeatureSlugs(h.slugCache, id)
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// DeleteFeature godoc
+//
+//	@Summary		Delete feature
+//	@Description	Delete feature which is not used by any banner
+//	@Security		JWT
+//	@Tags			Feature
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the feature"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/feature/{id} [delete]
+func (h *Handler) DeleteFeature(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.DeleteFeature(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred deleting feature: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	middleware.ForgetFeatureSlugs(h.slugCache, id)
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// SetFeatureEnabled godoc
+//
+//	@Summary		Turn feature on or off
+//	@Description	Turn feature on or off, users get no banners of disabled feature, even cached ones
+//	@Security		JWT
+//	@Tags			Feature
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body	request.SetFeatureEnabledRequest	true	"set feature enabled schema"
+//	@Param			id		path	int									true	"id of the feature"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/feature/{id}/enabled [put]
+func (h *Handler) SetFeatureEnabled(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var enabledReq request.SetFeatureEnabledRequest
+
+	if err = render.DecodeJSON(req.Body, &enabledReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to SetFeatureEnabledRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = enabledReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating SetFeatureEnabledRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.SetFeatureEnabled(req.Context(), id, *enabledReq.IsEnabled); err != nil {
+		msg := fmt.Sprintf("error occurred switching feature: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
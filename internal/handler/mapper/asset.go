@@ -0,0 +1,19 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapAssetToGetAssetResponse(asset *entity.Asset) response.GetAssetResponse {
+	return response.GetAssetResponse{
+		ID:        asset.ID,
+		Hash:      asset.Hash,
+		URL:       asset.URL,
+		MimeType:  asset.MimeType,
+		Size:      asset.Size,
+		Width:     asset.Width,
+		Height:    asset.Height,
+		CreatedAt: asset.CreatedAt,
+	}
+}
@@ -0,0 +1,19 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/response"
+	"encoding/json"
+)
+
+func MapAuditRecordToGetAuditRecordResponse(record *entity.AuditRecord) response.GetAuditRecordResponse {
+	return response.GetAuditRecordResponse{
+		ID:         record.ID,
+		Action:     record.Action,
+		EntityType: record.EntityType,
+		EntityID:   record.EntityID,
+		Username:   record.Username,
+		Details:    json.RawMessage(record.Details),
+		CreatedAt:  record.CreatedAt,
+	}
+}
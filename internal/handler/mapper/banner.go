@@ -1,24 +1,38 @@
 package mapper
 
 import (
+	"time"
+
 	"avito-backend-trainee-2024/internal/domain/entity"
 	"avito-backend-trainee-2024/internal/handler/request"
 	"avito-backend-trainee-2024/internal/handler/response"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
 )
 
 func MapBannerToAdminBannerResponse(banner *entity.Banner) response.GetAdminBannerResponse {
 	return response.GetAdminBannerResponse{
-		ID:        banner.ID,
-		TagIDs:    banner.TagIDs,
-		FeatureID: banner.FeatureID,
+		ID:            banner.ID,
+		TagIDs:        banner.TagIDs,
+		FeatureIDs:    banner.FeatureIDs,
+		SegmentIDs:    banner.SegmentIDs,
+		Countries:     banner.Countries,
+		Regions:       banner.Regions,
+		Platforms:     banner.Platforms,
+		MinAppVersion: banner.MinAppVersion,
+		MaxAppVersion: banner.MaxAppVersion,
+		CampaignID:    banner.CampaignID,
+		Priority:      banner.Priority,
 		GetContentResponse: response.GetContentResponse{
 			Title: banner.Content.Title,
 			Text:  banner.Content.Text,
 			Url:   banner.Content.Url,
 		},
-		IsActive:  banner.IsActive,
-		CreatedAt: banner.CreatedAt,
-		UpdatedAt: banner.UpdatedAt,
+		IsActive:   banner.IsActive,
+		EndsAt:     banner.EndsAt,
+		ArchivedAt: banner.ArchivedAt,
+		CreatedAt:  banner.CreatedAt,
+		UpdatedAt:  banner.UpdatedAt,
+		Features:   sliceutils.Map(banner.Features, MapFeatureToBannerFeatureResponse),
 	}
 }
 
@@ -31,32 +45,128 @@ func MapBannerToUserBannerResponse(banner *entity.Banner) response.GetUserBanner
 		}}
 }
 
+func MapFeatureBannersToResponse(featureBanners *entity.FeatureBanners) response.GetFeatureBannersResponse {
+	return response.GetFeatureBannersResponse{
+		FeatureID: featureBanners.FeatureID,
+		Banners:   sliceutils.Map(featureBanners.Banners, MapBannerToUserBannerResponse),
+	}
+}
+
 func MapBannerToCreateBannerResponse(banner *entity.Banner) response.CreateBannerResponse {
 	return response.CreateBannerResponse{ID: banner.ID}
 }
 
 func MapCreateBannerRequestToEntity(req *request.CreateBannerRequest) entity.Banner {
 	return entity.Banner{
-		TagIDs:    req.TagIDs,
-		FeatureID: req.FeatureID,
+		TagIDs:        req.TagIDs,
+		FeatureIDs:    req.FeatureIDs,
+		SegmentIDs:    req.SegmentIDs,
+		Countries:     req.Countries,
+		Regions:       req.Regions,
+		Platforms:     req.Platforms,
+		MinAppVersion: req.MinAppVersion,
+		MaxAppVersion: req.MaxAppVersion,
+		CampaignID:    req.CampaignID,
+		Priority:      &req.Priority,
 		Content: entity.Content{
 			Title: req.CreateContentRequest.Title,
 			Text:  req.CreateContentRequest.Text,
 			Url:   req.CreateContentRequest.Url,
 		},
 		IsActive: req.IsActive,
+		EndsAt:   req.EndsAt,
 	}
 }
 
 func MapUpdateBannerRequestToEntity(req *request.UpdateBannerRequest) entity.Banner {
+	// zero end date removes it
+	endsAt := req.EndsAt
+
+	if req.RemoveEndsAt {
+		endsAt = &time.Time{}
+	}
+
+	minAppVersion, maxAppVersion := req.MinAppVersion, req.MaxAppVersion
+
+	if req.RemoveMinAppVersion {
+		minAppVersion = entity.NoAppVersion
+	}
+
+	if req.RemoveMaxAppVersion {
+		maxAppVersion = entity.NoAppVersion
+	}
+
 	return entity.Banner{
-		TagIDs:    req.TagIDs,
-		FeatureID: req.FeatureID,
+		TagIDs:        req.TagIDs,
+		FeatureIDs:    req.FeatureIDs,
+		SegmentIDs:    req.SegmentIDs,
+		Countries:     req.Countries,
+		Regions:       req.Regions,
+		Platforms:     req.Platforms,
+		MinAppVersion: minAppVersion,
+		MaxAppVersion: maxAppVersion,
+		CampaignID:    req.CampaignID,
+		Priority:      req.Priority,
 		Content: entity.Content{
 			Title: req.UpdateContentRequest.Title,
 			Text:  req.UpdateContentRequest.Text,
 			Url:   req.UpdateContentRequest.Url,
 		},
 		IsActive: req.IsActive,
+		EndsAt:   endsAt,
+	}
+}
+
+func MapCloneBannerRequestToEntity(req *request.CloneBannerRequest) entity.Banner {
+	return entity.Banner{
+		TagIDs:        req.TagIDs,
+		FeatureIDs:    req.FeatureIDs,
+		SegmentIDs:    req.SegmentIDs,
+		Countries:     req.Countries,
+		Regions:       req.Regions,
+		Platforms:     req.Platforms,
+		MinAppVersion: req.MinAppVersion,
+		MaxAppVersion: req.MaxAppVersion,
+		CampaignID:    req.CampaignID,
+		Priority:      req.Priority,
+		Content: entity.Content{
+			Title: req.UpdateContentRequest.Title,
+			Text:  req.UpdateContentRequest.Text,
+			Url:   req.UpdateContentRequest.Url,
+		},
+		EndsAt: req.EndsAt,
+	}
+}
+
+func MapBannerCandidateToResponse(candidate *entity.BannerCandidate) response.BannerCandidateResponse {
+	return response.BannerCandidateResponse{
+		BannerID: candidate.Banner.ID,
+		TagIDs:   candidate.Banner.TagIDs,
+		IsActive: candidate.Banner.IsActive,
+		Accepted: candidate.Accepted,
+		Reason:   candidate.Reason,
+	}
+}
+
+func MapBannerMissToResponse(miss *entity.BannerMiss) response.BannerMissResponse {
+	return response.BannerMissResponse{
+		FeatureID:    miss.FeatureID,
+		TagIDs:       miss.TagIDs,
+		MissesCount:  miss.MissesCount,
+		LastMissedAt: miss.LastMissedAt,
+	}
+}
+
+func MapCoverageReportToResponse(report *entity.CoverageReport) response.CoverageReportResponse {
+	return response.CoverageReportResponse{
+		Uncovered: sliceutils.Map(report.Uncovered, MapBannerMissToResponse),
+		Inactive: sliceutils.Map(report.Inactive, func(combination *entity.InactiveCombination) response.InactiveCombinationResponse {
+			return response.InactiveCombinationResponse{
+				FeatureID: combination.FeatureID,
+				TagIDs:    combination.TagIDs,
+				BannerIDs: combination.BannerIDs,
+				Reason:    combination.Reason,
+			}
+		}),
 	}
 }
@@ -0,0 +1,72 @@
+package mapper
+
+import (
+	"time"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapCampaignToGetCampaignResponse(campaign *entity.Campaign) response.GetCampaignResponse {
+	return response.GetCampaignResponse{
+		ID:                campaign.ID,
+		Name:              campaign.Name,
+		StartsAt:          campaign.StartsAt,
+		EndsAt:            campaign.EndsAt,
+		ImpressionsBudget: campaign.ImpressionsBudget,
+		ImpressionsCount:  campaign.ImpressionsCount,
+		Countries:         campaign.Countries,
+		Regions:           campaign.Regions,
+		Platforms:         campaign.Platforms,
+		MinAppVersion:     campaign.MinAppVersion,
+		MaxAppVersion:     campaign.MaxAppVersion,
+		IsRunning:         campaign.IsRunning(time.Now()),
+		BannersCount:      campaign.BannersCount,
+		CreatedAt:         campaign.CreatedAt,
+		UpdatedAt:         campaign.UpdatedAt,
+	}
+}
+
+func MapCampaignToCreateCampaignResponse(campaign *entity.Campaign) response.CreateCampaignResponse {
+	return response.CreateCampaignResponse{ID: campaign.ID}
+}
+
+func MapCreateCampaignRequestToEntity(req *request.CreateCampaignRequest) entity.Campaign {
+	return entity.Campaign{
+		Name:              req.Name,
+		StartsAt:          req.StartsAt,
+		EndsAt:            req.EndsAt,
+		ImpressionsBudget: req.ImpressionsBudget,
+		Countries:         req.Countries,
+		Regions:           req.Regions,
+		Platforms:         req.Platforms,
+		MinAppVersion:     req.MinAppVersion,
+		MaxAppVersion:     req.MaxAppVersion,
+	}
+}
+
+func MapUpdateCampaignRequestToEntity(req *request.UpdateCampaignRequest) entity.Campaign {
+	// zero dates remove schedule
+	startsAt, endsAt := req.StartsAt, req.EndsAt
+
+	if req.RemoveStartsAt {
+		startsAt = &time.Time{}
+	}
+
+	if req.RemoveEndsAt {
+		endsAt = &time.Time{}
+	}
+
+	return entity.Campaign{
+		Name:              req.Name,
+		StartsAt:          startsAt,
+		EndsAt:            endsAt,
+		ImpressionsBudget: req.ImpressionsBudget,
+		Countries:         req.Countries,
+		Regions:           req.Regions,
+		Platforms:         req.Platforms,
+		MinAppVersion:     req.MinAppVersion,
+		MaxAppVersion:     req.MaxAppVersion,
+	}
+}
@@ -0,0 +1,68 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapFeatureToGetFeatureResponse(feature *entity.Feature) response.GetFeatureResponse {
+	return response.GetFeatureResponse{
+		ID:           feature.ID,
+		Name:         feature.Name,
+		Slug:         feature.Slug,
+		Description:  feature.Description,
+		Team:         feature.Team,
+		Contact:      feature.Contact,
+		Placement:    feature.Placement,
+		Labels:       feature.Labels,
+		IsEnabled:    feature.IsEnabled,
+		BannersCount: feature.BannersCount,
+		CreatedAt:    feature.CreatedAt,
+		UpdatedAt:    feature.UpdatedAt,
+	}
+}
+
+func MapFeatureToCreateFeatureResponse(feature *entity.Feature) response.CreateFeatureResponse {
+	return response.CreateFeatureResponse{ID: feature.ID}
+}
+
+func MapCreateFeatureRequestToEntity(req *request.CreateFeatureRequest) entity.Feature {
+	return entity.Feature{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		Team:        req.Team,
+		Contact:     req.Contact,
+		Placement:   req.Placement,
+		Labels:      req.Labels,
+	}
+}
+
+func MapUpdateFeatureRequestToEntity(req *request.UpdateFeatureRequest) entity.Feature {
+	return entity.Feature{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		Team:        req.Team,
+		Contact:     req.Contact,
+		Placement:   req.Placement,
+		Labels:      req.Labels,
+	}
+}
+
+func MapFeatureToBannerFeatureResponse(feature *entity.Feature) *response.GetBannerFeatureResponse {
+	if feature == nil {
+		return nil
+	}
+
+	return &response.GetBannerFeatureResponse{
+		Name:        feature.Name,
+		Slug:        feature.Slug,
+		Description: feature.Description,
+		Team:        feature.Team,
+		Contact:     feature.Contact,
+		Placement:   feature.Placement,
+		Labels:      feature.Labels,
+	}
+}
@@ -0,0 +1,18 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapLinkCheckToGetLinkCheckResponse(check *entity.LinkCheck) response.GetLinkCheckResponse {
+	return response.GetLinkCheckResponse{
+		BannerID:   check.BannerID,
+		Url:        check.Url,
+		StatusCode: check.StatusCode,
+		Error:      check.Error,
+		Redirects:  check.Redirects,
+		IsBroken:   check.IsBroken,
+		CheckedAt:  check.CheckedAt,
+	}
+}
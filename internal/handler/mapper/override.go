@@ -0,0 +1,17 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapOverrideToGetOverrideResponse(override *entity.Override) response.GetOverrideResponse {
+	return response.GetOverrideResponse{
+		ID:         override.ID,
+		BannerID:   override.BannerID,
+		FeatureIDs: override.FeatureIDs,
+		UserID:     override.UserID,
+		ExpiresAt:  override.ExpiresAt,
+		CreatedAt:  override.CreatedAt,
+	}
+}
@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapScheduledEditToGetScheduledEditResponse(edit *entity.ScheduledEdit) response.GetScheduledEditResponse {
+	// zero end date removes it
+	endsAt, removeEndsAt := edit.Patch.EndsAt, edit.Patch.EndsAt != nil && edit.Patch.EndsAt.IsZero()
+
+	if removeEndsAt {
+		endsAt = nil
+	}
+
+	minAppVersion, maxAppVersion := edit.Patch.MinAppVersion, edit.Patch.MaxAppVersion
+	removeMinAppVersion, removeMaxAppVersion := minAppVersion == entity.NoAppVersion, maxAppVersion == entity.NoAppVersion
+
+	if removeMinAppVersion {
+		minAppVersion = ""
+	}
+
+	if removeMaxAppVersion {
+		maxAppVersion = ""
+	}
+
+	return response.GetScheduledEditResponse{
+		ID:       edit.ID,
+		BannerID: edit.BannerID,
+		Patch: response.BannerPatchResponse{
+			TagIDs:              edit.Patch.TagIDs,
+			FeatureIDs:          edit.Patch.FeatureIDs,
+			SegmentIDs:          edit.Patch.SegmentIDs,
+			Countries:           edit.Patch.Countries,
+			Regions:             edit.Patch.Regions,
+			Platforms:           edit.Patch.Platforms,
+			MinAppVersion:       minAppVersion,
+			MaxAppVersion:       maxAppVersion,
+			RemoveMinAppVersion: removeMinAppVersion,
+			RemoveMaxAppVersion: removeMaxAppVersion,
+			CampaignID:          edit.Patch.CampaignID,
+			Priority:            edit.Patch.Priority,
+			Title:               edit.Patch.Content.Title,
+			Text:                edit.Patch.Content.Text,
+			Url:                 edit.Patch.Content.Url,
+			IsActive:            edit.IsActive,
+			EndsAt:              endsAt,
+			RemoveEndsAt:        removeEndsAt,
+		},
+		ApplyAt:   edit.ApplyAt,
+		Status:    edit.Status,
+		Attempts:  edit.Attempts,
+		LastError: edit.LastError,
+		CreatedAt: edit.CreatedAt,
+	}
+}
+
+func MapScheduledEditToCreateScheduledEditResponse(edit *entity.ScheduledEdit) response.CreateScheduledEditResponse {
+	return response.CreateScheduledEditResponse{ID: edit.ID}
+}
+
+func MapScheduleEditRequestToEntity(req *request.ScheduleEditRequest) entity.ScheduledEdit {
+	return entity.ScheduledEdit{
+		BannerID: req.BannerID,
+		Patch:    MapUpdateBannerRequestToEntity(&req.Patch.UpdateBannerRequest),
+		IsActive: req.Patch.IsActive,
+		ApplyAt:  req.ApplyAt,
+	}
+}
@@ -0,0 +1,30 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapSegmentToGetSegmentResponse(segment *entity.Segment) response.GetSegmentResponse {
+	return response.GetSegmentResponse{
+		ID:         segment.ID,
+		Name:       segment.Name,
+		UsersCount: segment.UsersCount,
+		CreatedAt:  segment.CreatedAt,
+		UpdatedAt:  segment.UpdatedAt,
+	}
+}
+
+func MapSegmentToCreateSegmentResponse(segment *entity.Segment) response.CreateSegmentResponse {
+	return response.CreateSegmentResponse{
+		ID:         segment.ID,
+		UsersCount: segment.UsersCount,
+	}
+}
+
+func MapCreateSegmentRequestToEntity(req *request.CreateSegmentRequest) entity.Segment {
+	return entity.Segment{
+		Name: req.Name,
+	}
+}
@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapTagToGetTagResponse(tag *entity.Tag) response.GetTagResponse {
+	return response.GetTagResponse{
+		ID:           tag.ID,
+		Name:         tag.Name,
+		Slug:         tag.Slug,
+		ParentID:     tag.ParentID,
+		AliasOfID:    tag.AliasOfID,
+		BannersCount: tag.BannersCount,
+		CreatedAt:    tag.CreatedAt,
+		UpdatedAt:    tag.UpdatedAt,
+	}
+}
+
+func MapTagToCreateTagResponse(tag *entity.Tag) response.CreateTagResponse {
+	return response.CreateTagResponse{ID: tag.ID}
+}
+
+func MapCreateTagRequestToEntity(req *request.CreateTagRequest) entity.Tag {
+	return entity.Tag{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+	}
+}
+
+func MapUpdateTagRequestToEntity(req *request.UpdateTagRequest) entity.Tag {
+	return entity.Tag{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+	}
+}
+
+func MapTagMergeToMergeTagsResponse(merge *entity.TagMerge) response.MergeTagsResponse {
+	return response.MergeTagsResponse{
+		SourceID:          merge.SourceID,
+		TargetID:          merge.TargetID,
+		KeepAlias:         merge.KeepAlias,
+		MovedBanners:      merge.MovedBanners,
+		RemovedDuplicates: merge.RemovedDuplicates,
+	}
+}
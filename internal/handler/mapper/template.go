@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/request"
+	"avito-backend-trainee-2024/internal/handler/response"
+)
+
+func MapTemplateToGetTemplateResponse(template *entity.BannerTemplate) response.GetTemplateResponse {
+	return response.GetTemplateResponse{
+		ID:           template.ID,
+		Name:         template.Name,
+		Title:        template.Content.Title,
+		Text:         template.Content.Text,
+		Url:          template.Content.Url,
+		Placeholders: template.Placeholders,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}
+}
+
+func MapTemplateToCreateTemplateResponse(template *entity.BannerTemplate) response.CreateTemplateResponse {
+	return response.CreateTemplateResponse{
+		ID:           template.ID,
+		Placeholders: template.Placeholders,
+	}
+}
+
+func MapCreateTemplateRequestToEntity(req *request.CreateTemplateRequest) entity.BannerTemplate {
+	return entity.BannerTemplate{
+		Name: req.Name,
+		Content: entity.Content{
+			Title: req.Title,
+			Text:  req.Text,
+			Url:   req.Url,
+		},
+	}
+}
+
+func MapUpdateTemplateRequestToEntity(req *request.UpdateTemplateRequest) entity.BannerTemplate {
+	return entity.BannerTemplate{
+		Name: req.Name,
+		Content: entity.Content{
+			Title: req.Title,
+			Text:  req.Text,
+			Url:   req.Url,
+		},
+	}
+}
+
+func MapInstantiateRequestToEntity(req *request.InstantiateRequest) entity.Banner {
+	return entity.Banner{
+		TagIDs:        req.TagIDs,
+		FeatureIDs:    req.FeatureIDs,
+		SegmentIDs:    req.SegmentIDs,
+		Countries:     req.Countries,
+		Regions:       req.Regions,
+		Platforms:     req.Platforms,
+		MinAppVersion: req.MinAppVersion,
+		MaxAppVersion: req.MaxAppVersion,
+		CampaignID:    req.CampaignID,
+		IsActive:      req.IsActive,
+		EndsAt:        req.EndsAt,
+	}
+}
@@ -1,28 +1,188 @@
 package middleware
 
 import (
+	"avito-backend-trainee-2024/internal/domain/entity"
 	"avito-backend-trainee-2024/internal/handler/response"
 	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
-	urlutils "avito-backend-trainee-2024/pkg/utils/url"
 	"context"
 	"fmt"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
-	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type MiddlewareData = map[string]any
 
-func InMemUserBannerCache(cache *cache.Cache, logger *logrus.Logger) Handler {
+// UserBannerLookupRoute is route of single banner lookup within user banner routes
+const UserBannerLookupRoute = "/"
+
+// UserBannerCacheKey returns key for caching banners returned by route of user banner routes to query of requester,
+// banners may be targeted to users, locations and platforms, so they are part of the key
+func UserBannerCacheKey(route string, query url.Values, requester entity.Requester) string {
+	return fmt.Sprintf("%v?%v#user_id=%v&country=%v&region=%v&platform=%v&app_version=%v",
+		route, query.Encode(), requester.UserID, requester.Country, requester.Region,
+		requester.Platform, requester.AppVersion)
+}
+
+// RequestCacheKey returns key banners returned to request are cached with, route is taken relative to user banner
+// routes, 'use_last_revision' param only tells whether cache is bypassed, so it is not part of the key
+func RequestCacheKey(req *http.Request) string {
+	route := req.URL.Path
+
+	if routeCtx := chi.RouteContext(req.Context()); routeCtx != nil && routeCtx.RoutePath != "" {
+		route = routeCtx.RoutePath
+	}
+
+	query := req.URL.Query()
+	query.Del("use_last_revision")
+
+	// id header is set by authentication middleware, location and platform headers by geo location and client platform ones
+	userID, _ := strconv.Atoi(req.Header.Get("id"))
+
+	return UserBannerCacheKey(route, query, entity.Requester{
+		UserID:     userID,
+		Country:    req.Header.Get("country"),
+		Region:     req.Header.Get("region"),
+		Platform:   req.Header.Get("platform"),
+		AppVersion: req.Header.Get("app_version"),
+	})
+}
+
+type FeatureSwitch interface {
+	IsFeatureEnabled(ctx context.Context, id int) (bool, error)
+}
+
+type Cache interface {
+	Get(k string) (any, bool)
+	Set(k string, x any, d time.Duration)
+}
+
+// ImpressionTracker claims impressions of campaign banners against budgets of their campaigns
+type ImpressionTracker interface {
+	ClaimImpressions(ctx context.Context, campaignIDs []int) (bool, error)
+}
+
+// CachedUserBanner is response of user banner routes cached with data needed to serve it from cache
+type CachedUserBanner struct {
+	Response any
+	// IsActive is false if banner is inactive, so it is served only to admins
+	IsActive bool
+	// CampaignIDs are campaigns impressions are claimed against each time response is served
+	CampaignIDs []int
+	// ExpiresAt is set if banners are shown only until some moment, so response is not served after it
+	ExpiresAt *time.Time
+}
+
+// NewCachedUserBanner returns response of banners shown to requester prepared to be cached
+func NewCachedUserBanner(resp any, isActive bool, banners ...*entity.Banner) CachedUserBanner {
+	cached := CachedUserBanner{
+		Response:    resp,
+		IsActive:    isActive,
+		CampaignIDs: entity.ShownCampaignIDs(banners...),
+	}
+
+	for _, banner := range banners {
+		if banner.ShownUntil != nil && (cached.ExpiresAt == nil || banner.ShownUntil.Before(*cached.ExpiresAt)) {
+			cached.ExpiresAt = banner.ShownUntil
+		}
+	}
+
+	return cached
+}
+
+// CacheUserBanner caches response of user banner routes with key
+func CacheUserBanner(cache Cache, key string, cached CachedUserBanner) {
+	cache.Set(key, cached, 0)
+}
+
+// GetCachedUserBanner returns response of banners of the feature cached with key and claims impressions of its
+// campaign banners, response of disabled feature, expired one or one with campaign budgets spent is not found,
+// featureID is 0 if banners are not of single feature
+func GetCachedUserBanner(ctx context.Context, cache Cache, featureSwitch FeatureSwitch, impressionTracker ImpressionTracker,
+	key string, featureID int) (CachedUserBanner, bool, error) {
+	enabled, err := isFeatureEnabled(ctx, featureSwitch, featureID)
+	if err != nil || !enabled {
+		return CachedUserBanner{}, false, err
+	}
+
+	return getCachedUserBanner(ctx, cache, impressionTracker, key)
+}
+
+// PeekCachedUserBanner returns response cached with key unless it is expired, feature of the response is not checked
+// and impressions of its campaign banners are not claimed
+func PeekCachedUserBanner(cache Cache, key string) (CachedUserBanner, bool) {
+	value, found := cache.Get(key)
+	if !found {
+		return CachedUserBanner{}, false
+	}
+
+	cached, ok := value.(CachedUserBanner)
+	if !ok || (cached.ExpiresAt != nil && !time.Now().Before(*cached.ExpiresAt)) {
+		return CachedUserBanner{}, false
+	}
+
+	return cached, true
+}
+
+// getCachedUserBanner is GetCachedUserBanner with feature already checked to be enabled
+func getCachedUserBanner(ctx context.Context, cache Cache, impressionTracker ImpressionTracker, key string) (CachedUserBanner, bool, error) {
+	cached, found := PeekCachedUserBanner(cache, key)
+	if !found {
+		return CachedUserBanner{}, false, nil
+	}
+
+	if len(cached.CampaignIDs) != 0 {
+		claimed, err := impressionTracker.ClaimImpressions(ctx, cached.CampaignIDs)
+		if err != nil || !claimed {
+			return CachedUserBanner{}, false, err
+		}
+	}
+
+	return cached, true, nil
+}
+
+// isFeatureEnabled reports whether banners of the feature may be served, featureID is 0 if banners are not
+// of single feature
+func isFeatureEnabled(ctx context.Context, featureSwitch FeatureSwitch, featureID int) (bool, error) {
+	if featureID == 0 {
+		return true, nil
+	}
+
+	return featureSwitch.IsFeatureEnabled(ctx, featureID)
+}
+
+// InMemUserBannerCache caches banners retrieved by handler, cached banners of disabled features are not served,
+// so turning feature off takes effect immediately, campaign banners are served while budgets of their campaigns allow
+func InMemUserBannerCache(cache Cache, featureSwitch FeatureSwitch, impressionTracker ImpressionTracker, logger *logrus.Logger) Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 			if req.Method != "GET" {
 				next.ServeHTTP(rw, req) // cache only get requests
+				return
+			}
+
+			// invalid feature id is reported by handler
+			featureID, _ := strconv.Atoi(req.URL.Query().Get("feature_id"))
+
+			enabled, err := isFeatureEnabled(req.Context(), featureSwitch, featureID)
+			if err != nil {
+				msg := fmt.Sprintf("error occurred checking if feature is enabled: %v", err)
+
+				handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusInternalServerError, msg, "")
+				return
+			}
+
+			if !enabled {
+				next.ServeHTTP(rw, req) // bypass cache, handler responds there is no banner
+				return
 			}
 
-			// take requested uri as key without 'use_last_revision' query param to cache data retrieved from db
-			key := urlutils.RemoveQueryParamByKey(*req.URL, "use_last_revision").RequestURI()
+			// key to cache data retrieved from db
+			key := RequestCacheKey(req)
 
 			// add map[string]any to request context, so handler can add some data to it
 			req = req.WithContext(context.WithValue(req.Context(), key, make(MiddlewareData)))
@@ -42,14 +202,8 @@ func InMemUserBannerCache(cache *cache.Cache, logger *logrus.Logger) Handler {
 					data = val.(MiddlewareData)
 				}
 
-				banner, exists := data["banner"]
-				if exists {
-					cache.Set(key, banner, 0)
-				}
-
-				isActive, exists := data["is_active"]
-				if exists {
-					cache.Set(key+"?is_active=", isActive, 0)
+				if cached, ok := data["banner"].(CachedUserBanner); ok {
+					CacheUserBanner(cache, key, cached)
 				}
 			}
 
@@ -61,31 +215,45 @@ func InMemUserBannerCache(cache *cache.Cache, logger *logrus.Logger) Handler {
 				return
 			}
 
-			if cached, found := cache.Get(key); found {
-				banner, ok := cached.(response.GetUserBannerResponse)
-				if !ok {
-					msg := fmt.Sprintf("error occurred casting cached value to GetUserBannerResponse struct: %v", err)
+			// feature is checked to be enabled above
+			cached, found, err := getCachedUserBanner(req.Context(), cache, impressionTracker, key)
+			if err != nil {
+				msg := fmt.Sprintf("error occurred claiming impressions of cached banners: %v", err)
 
-					handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusInternalServerError, msg, "")
-					return
-				}
+				handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusInternalServerError, msg, "")
+				return
+			}
+
+			if found {
+				resp := cached.Response
+
+				// list of banners is cached if 'limit' query param is provided, banners of all features are cached
+				// as they were fetched, so features disabled since then are filtered out
+				switch banners := resp.(type) {
+				case response.GetUserBannerResponse, []response.GetUserBannerResponse:
+				case []response.GetFeatureBannersResponse:
+					resp, err = filterDisabledFeatures(req.Context(), featureSwitch, banners)
+					if err != nil {
+						msg := fmt.Sprintf("error occurred checking if feature is enabled: %v", err)
 
-				isActive, exists := cache.Get(key + "?is_active=")
-				if !exists {
-					msg := "no key 'is_active' in MiddlewareData"
+						handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusInternalServerError, msg, "")
+						return
+					}
+				default:
+					msg := fmt.Sprintf("error occurred casting cached value to GetUserBannerResponse struct: %T", resp)
 
 					handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusInternalServerError, msg, "")
 					return
 				}
 
-				if isActive != true && req.Header.Get("is_admin") != "true" {
+				if !cached.IsActive && req.Header.Get("is_admin") != "true" {
 					msg := "banner is inactive"
 
 					handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusNoContent, msg, msg)
 					return
 				}
 
-				render.JSON(rw, req, banner)
+				render.JSON(rw, req, resp)
 				rw.WriteHeader(http.StatusOK)
 
 				return
@@ -99,3 +267,21 @@ func InMemUserBannerCache(cache *cache.Cache, logger *logrus.Logger) Handler {
 		})
 	}
 }
+
+func filterDisabledFeatures(ctx context.Context, featureSwitch FeatureSwitch,
+	featureBanners []response.GetFeatureBannersResponse) ([]response.GetFeatureBannersResponse, error) {
+	filtered := make([]response.GetFeatureBannersResponse, 0, len(featureBanners))
+
+	for _, banners := range featureBanners {
+		enabled, err := featureSwitch.IsFeatureEnabled(ctx, banners.FeatureID)
+		if err != nil {
+			return nil, err
+		}
+
+		if enabled {
+			filtered = append(filtered, banners)
+		}
+	}
+
+	return filtered, nil
+}
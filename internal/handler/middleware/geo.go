@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"avito-backend-trainee-2024/pkg/geoip"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	iputils "avito-backend-trainee-2024/pkg/utils/ip"
+)
+
+type GeoResolver interface {
+	Lookup(ip net.IP) (geoip.Location, error)
+}
+
+// GeoLocation resolves location of client by its ip and sets 'country' and 'region' headers,
+// headers are always overwritten, so client can not set them by itself, nil resolver leaves them empty
+func GeoLocation(resolver GeoResolver, trustedProxies []*net.IPNet, logger *logrus.Logger) Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			req.Header.Del("country")
+			req.Header.Del("region")
+
+			if ip := iputils.ClientIP(req, trustedProxies); resolver != nil && ip != nil {
+				location, err := resolver.Lookup(ip)
+
+				switch {
+				case err == nil:
+					req.Header.Set("country", location.Country)
+					req.Header.Set("region", location.Region)
+				case !errors.Is(err, geoip.ErrLocationNotFound):
+					// banner still may be resolved without location, so request is not failed
+					logger.Errorf("error occurred resolving location of %v: %v", ip, err)
+				}
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	useragentutils "avito-backend-trainee-2024/pkg/utils/useragent"
+	versionutils "avito-backend-trainee-2024/pkg/utils/version"
+)
+
+var platforms = []string{useragentutils.PlatformAndroid, useragentutils.PlatformIOS, useragentutils.PlatformWeb}
+
+// ClientPlatform sets 'platform' and 'app_version' headers parsed from User-Agent, explicit X-Platform
+// and X-App-Version headers take precedence, invalid values are treated as unknown
+func ClientPlatform() Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			platform, appVersion := useragentutils.Parse(req.UserAgent())
+
+			if explicit := strings.ToLower(req.Header.Get("X-Platform")); explicit != "" {
+				platform = explicit
+			}
+
+			if explicit := req.Header.Get("X-App-Version"); explicit != "" {
+				appVersion = explicit
+			}
+
+			if !slices.Contains(platforms, platform) {
+				platform = ""
+			}
+
+			if !versionutils.IsValid(appVersion) {
+				appVersion = ""
+			}
+
+			req.Header.Set("platform", platform)
+			req.Header.Set("app_version", appVersion)
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
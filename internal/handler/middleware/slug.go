@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type SlugResolver interface {
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+}
+
+// SlugResolution replaces 'feature_slug' and 'tag_slugs' query params with 'feature_id' and 'tag_ids' ones,
+// so handlers and cache work with ids only, slugs take precedence over ids, resolved ids are cached
+func SlugResolution(resolver SlugResolver, cache *cache.Cache, logger *logrus.Logger) Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			q := req.URL.Query()
+
+			if featureSlug := q.Get("feature_slug"); featureSlug != "" {
+				featureID, err := resolveFeatureSlug(req.Context(), resolver, cache, featureSlug)
+				if err != nil {
+					msg := fmt.Sprintf("error occurred resolving 'feature_slug' query param: %v", err)
+
+					handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusBadRequest, msg, msg)
+					return
+				}
+
+				q.Del("feature_slug")
+				q.Set("feature_id", strconv.Itoa(featureID))
+			}
+
+			if tagSlugs := q.Get("tag_slugs"); tagSlugs != "" {
+				tagIDs, err := resolveTagSlugs(req.Context(), resolver, cache, strings.Split(tagSlugs, ","))
+				if err != nil {
+					msg := fmt.Sprintf("error occurred resolving 'tag_slugs' query param: %v", err)
+
+					handlerutils.WriteErrResponseAndLog(rw, logger, http.StatusBadRequest, msg, msg)
+					return
+				}
+
+				tagIDsStr := make([]string, 0, len(tagIDs))
+
+				for _, id := range tagIDs {
+					tagIDsStr = append(tagIDsStr, strconv.Itoa(id))
+				}
+
+				q.Del("tag_slugs")
+				q.Set("tag_ids", strings.Join(tagIDsStr, ","))
+			}
+
+			req.URL.RawQuery = q.Encode()
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// ForgetFeatureSlugs drops cached ids of the feature resolved from its slugs, so slug of renamed or deleted feature
+// is not resolved to it anymore
+func ForgetFeatureSlugs(cache *cache.Cache, featureID int) {
+	forgetSlugs(cache, "feature_slug=", []int{featureID})
+}
+
+// ForgetTagSlugs drops cached ids of the tags resolved from their slugs, so slugs of renamed, deleted or merged
+// tags are not resolved to them anymore
+func ForgetTagSlugs(cache *cache.Cache, tagIDs ...int) {
+	forgetSlugs(cache, "tag_slug=", tagIDs)
+}
+
+func forgetSlugs(cache *cache.Cache, keyPrefix string, IDs []int) {
+	for key, item := range cache.Items() {
+		if id, ok := item.Object.(int); ok && strings.HasPrefix(key, keyPrefix) && slices.Contains(IDs, id) {
+			cache.Delete(key)
+		}
+	}
+}
+
+func resolveFeatureSlug(ctx context.Context, resolver SlugResolver, cache *cache.Cache, slug string) (int, error) {
+	key := "feature_slug=" + slug
+
+	if cached, found := cache.Get(key); found {
+		return cached.(int), nil
+	}
+
+	id, err := resolver.GetFeatureIDBySlug(ctx, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	cache.Set(key, id, 0)
+
+	return id, nil
+}
+
+func resolveTagSlugs(ctx context.Context, resolver SlugResolver, cache *cache.Cache, slugs []string) ([]int, error) {
+	IDs := make([]int, len(slugs))
+
+	// indexes of slugs which ids are not cached
+	var missing []int
+
+	for i, slug := range slugs {
+		if cached, found := cache.Get("tag_slug=" + slug); found {
+			IDs[i] = cached.(int)
+		} else {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) == 0 {
+		return IDs, nil
+	}
+
+	missingSlugs := make([]string, 0, len(missing))
+
+	for _, i := range missing {
+		missingSlugs = append(missingSlugs, slugs[i])
+	}
+
+	resolved, err := resolver.GetTagIDsBySlugs(ctx, missingSlugs)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missing {
+		IDs[i] = resolved[j]
+
+		cache.Set("tag_slug="+slugs[i], resolved[j], 0)
+	}
+
+	return IDs, nil
+}
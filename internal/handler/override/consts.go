@@ -0,0 +1,6 @@
+package override
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
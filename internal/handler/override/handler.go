@@ -0,0 +1,180 @@
+package override
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllOverrides(ctx context.Context, offset, limit int) ([]*entity.Override, error)
+	CreateOverrides(ctx context.Context, bannerID int, userIDs []int, expiresAt time.Time) ([]*entity.Override, error)
+	DeleteOverride(ctx context.Context, id int) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllOverrides)
+		r.Post("/", h.CreateOverrides)
+		r.Delete("/{id}", h.DeleteOverride)
+	})
+
+	return router
+}
+
+// GetAllOverrides godoc
+//
+//	@Summary		Get all overrides
+//	@Description	Get all not expired overrides pinning banners to users
+//	@Security		JWT
+//	@Tags			Override
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetOverrideResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/override [get]
+func (h *Handler) GetAllOverrides(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	overrides, err := h.Service.GetAllOverrides(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching overrides: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(overrides, mapper.MapOverrideToGetOverrideResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CreateOverrides godoc
+//
+//	@Summary		Pin banner to users
+//	@Description	Pin banner to users for banner's feature until expiration time, users get it regardless of tags, targeting and activity of banner
+//	@Security		JWT
+//	@Tags			Override
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.CreateOverrideRequest	true	"create override schema"
+//	@Success		200		{object}	[]response.GetOverrideResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/override [post]
+func (h *Handler) CreateOverrides(rw http.ResponseWriter, req *http.Request) {
+	var overrideReq request.CreateOverrideRequest
+
+	if err := render.DecodeJSON(req.Body, &overrideReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateOverrideRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := overrideReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateOverrideRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CreateOverrides(req.Context(), overrideReq.BannerID, overrideReq.UserIDs, overrideReq.ExpiresAt)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating overrides: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(created, mapper.MapOverrideToGetOverrideResponse))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// DeleteOverride godoc
+//
+//	@Summary		Delete override
+//	@Description	Delete override, user gets banner resolved by tags again
+//	@Security		JWT
+//	@Tags			Override
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the override"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/override/{id} [delete]
+func (h *Handler) DeleteOverride(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.DeleteOverride(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred deleting override: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
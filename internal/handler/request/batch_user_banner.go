@@ -0,0 +1,15 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type UserBannerQueryRequest struct {
+	Key       string `json:"key"`
+	FeatureID int    `json:"feature_id" validate:"required,min=1"`
+	TagIDs    []int  `json:"tag_ids" validate:"required,min=1"`
+}
+
+type BatchUserBannerRequest struct {
+	Queries []UserBannerQueryRequest `json:"queries" validate:"required,min=1,max=50,dive"`
+}
+
+func (br *BatchUserBannerRequest) Validate(valid *validator.Validate) error { return valid.Struct(br) }
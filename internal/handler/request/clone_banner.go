@@ -0,0 +1,27 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CloneBannerRequest holds fields replacing copied ones, empty fields are copied from the cloned banner
+type CloneBannerRequest struct {
+	TagIDs        []int    `json:"tag_ids"`
+	FeatureIDs    []int    `json:"feature_ids"`
+	TagSlugs      []string `json:"tag_slugs" validate:"omitempty,dive,min=1"`
+	FeatureSlugs  []string `json:"feature_slugs" validate:"omitempty,dive,min=1"`
+	SegmentIDs    []int    `json:"segment_ids"`
+	Countries     []string `json:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Regions       []string `json:"regions" validate:"omitempty,dive,iso3166_2"`
+	Platforms     []string `json:"platforms" validate:"omitempty,dive,oneof=android ios web"`
+	MinAppVersion string   `json:"min_app_version"`
+	MaxAppVersion string   `json:"max_app_version"`
+	CampaignID    *int     `json:"campaign_id" validate:"omitempty,min=0"` // 0 creates clone out of any campaign
+	Priority      *int     `json:"priority"`
+	UpdateContentRequest
+	EndsAt *time.Time `json:"ends_at"`
+}
+
+func (br *CloneBannerRequest) Validate(valid *validator.Validate) error { return valid.Struct(br) }
@@ -1,6 +1,10 @@
 package request
 
-import "github.com/go-playground/validator/v10"
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type CreateContentRequest struct {
 	Title string `json:"title" validate:"required,min=1"`
@@ -9,10 +13,21 @@ type CreateContentRequest struct {
 }
 
 type CreateBannerRequest struct {
-	TagIDs    []int `json:"tag_ids" validate:"required,min=1"`
-	FeatureID int   `json:"feature_id" validate:"required,min=0"`
+	TagIDs        []int    `json:"tag_ids" validate:"required,min=1"`
+	FeatureIDs    []int    `json:"feature_ids" validate:"required,min=1"`
+	TagSlugs      []string `json:"tag_slugs" validate:"omitempty,dive,min=1"`
+	FeatureSlugs  []string `json:"feature_slugs" validate:"omitempty,dive,min=1"`
+	SegmentIDs    []int    `json:"segment_ids"`
+	Countries     []string `json:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Regions       []string `json:"regions" validate:"omitempty,dive,iso3166_2"`
+	Platforms     []string `json:"platforms" validate:"omitempty,dive,oneof=android ios web"`
+	MinAppVersion string   `json:"min_app_version"`
+	MaxAppVersion string   `json:"max_app_version"`
+	CampaignID    *int     `json:"campaign_id" validate:"omitempty,min=1"`
+	Priority      int      `json:"priority"` // banners matching the same request are ranked by priority, higher first
 	CreateContentRequest
-	IsActive bool `json:"is_active"`
+	IsActive bool       `json:"is_active"`
+	EndsAt   *time.Time `json:"ends_at"`
 }
 
 func (br *CreateBannerRequest) Validate(valid *validator.Validate) error { return valid.Struct(br) }
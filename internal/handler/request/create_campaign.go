@@ -0,0 +1,21 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateCampaignRequest struct {
+	Name              string     `json:"name" validate:"required,min=1"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	ImpressionsBudget int        `json:"impressions_budget" validate:"min=0"`
+	Countries         []string   `json:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Regions           []string   `json:"regions" validate:"omitempty,dive,iso3166_2"`
+	Platforms         []string   `json:"platforms" validate:"omitempty,dive,oneof=android ios web"`
+	MinAppVersion     string     `json:"min_app_version"`
+	MaxAppVersion     string     `json:"max_app_version"`
+}
+
+func (cr *CreateCampaignRequest) Validate(valid *validator.Validate) error { return valid.Struct(cr) }
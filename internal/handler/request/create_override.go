@@ -0,0 +1,15 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateOverrideRequest struct {
+	BannerID  int       `json:"banner_id" validate:"required,min=1"`
+	UserIDs   []int     `json:"user_ids" validate:"required,min=1"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
+
+func (or *CreateOverrideRequest) Validate(valid *validator.Validate) error { return valid.Struct(or) }
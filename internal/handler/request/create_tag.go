@@ -0,0 +1,11 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type CreateTagRequest struct {
+	Name     string `json:"name" validate:"required,min=1"`
+	Slug     string `json:"slug"`
+	ParentID *int   `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+func (tr *CreateTagRequest) Validate(valid *validator.Validate) error { return valid.Struct(tr) }
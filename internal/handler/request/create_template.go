@@ -0,0 +1,12 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type CreateTemplateRequest struct {
+	Name  string `json:"name" validate:"required,min=1"`
+	Title string `json:"title" validate:"required,min=1"`
+	Text  string `json:"text" validate:"required,min=1"`
+	Url   string `json:"url" validate:"required,min=1"` // url is validated once placeholders are filled
+}
+
+func (tr *CreateTemplateRequest) Validate(valid *validator.Validate) error { return valid.Struct(tr) }
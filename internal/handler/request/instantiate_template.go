@@ -0,0 +1,28 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// InstantiateRequest holds values of template placeholders and fields of banner created from template,
+// banner content is taken from template
+type InstantiateRequest struct {
+	Values        map[string]string `json:"values" validate:"omitempty,dive,keys,min=1,endkeys,min=1"`
+	TagIDs        []int             `json:"tag_ids" validate:"required,min=1"`
+	FeatureIDs    []int             `json:"feature_ids" validate:"required,min=1"`
+	TagSlugs      []string          `json:"tag_slugs" validate:"omitempty,dive,min=1"`
+	FeatureSlugs  []string          `json:"feature_slugs" validate:"omitempty,dive,min=1"`
+	SegmentIDs    []int             `json:"segment_ids"`
+	Countries     []string          `json:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Regions       []string          `json:"regions" validate:"omitempty,dive,iso3166_2"`
+	Platforms     []string          `json:"platforms" validate:"omitempty,dive,oneof=android ios web"`
+	MinAppVersion string            `json:"min_app_version"`
+	MaxAppVersion string            `json:"max_app_version"`
+	CampaignID    *int              `json:"campaign_id" validate:"omitempty,min=1"`
+	IsActive      bool              `json:"is_active"`
+	EndsAt        *time.Time        `json:"ends_at"`
+}
+
+func (ir *InstantiateRequest) Validate(valid *validator.Validate) error { return valid.Struct(ir) }
@@ -0,0 +1,10 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type MergeTagsRequest struct {
+	TargetID  int  `json:"target_id" validate:"required,min=1"`
+	KeepAlias bool `json:"keep_alias"`
+}
+
+func (tr *MergeTagsRequest) Validate(valid *validator.Validate) error { return valid.Struct(tr) }
@@ -0,0 +1,21 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ScheduleEditRequest struct {
+	BannerID int                `json:"banner_id" validate:"required,min=1"`
+	ApplyAt  time.Time          `json:"apply_at" validate:"required"`
+	Patch    BannerPatchRequest `json:"patch"`
+}
+
+// BannerPatchRequest is banner update applied by scheduled edit, activity of banner is kept if is_active is omitted
+type BannerPatchRequest struct {
+	UpdateBannerRequest
+	IsActive *bool `json:"is_active"`
+}
+
+func (er *ScheduleEditRequest) Validate(valid *validator.Validate) error { return valid.Struct(er) }
@@ -0,0 +1,11 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type SetFeatureEnabledRequest struct {
+	IsEnabled *bool `json:"is_enabled" validate:"required"`
+}
+
+func (fr *SetFeatureEnabledRequest) Validate(valid *validator.Validate) error {
+	return valid.Struct(fr)
+}
@@ -1,6 +1,10 @@
 package request
 
-import "github.com/go-playground/validator/v10"
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type UpdateContentRequest struct {
 	Title string `json:"title"`
@@ -9,10 +13,24 @@ type UpdateContentRequest struct {
 }
 
 type UpdateBannerRequest struct {
-	TagIDs    []int `json:"tag_ids"`
-	FeatureID int   `json:"feature_id"`
+	TagIDs              []int    `json:"tag_ids"`
+	FeatureIDs          []int    `json:"feature_ids"`
+	TagSlugs            []string `json:"tag_slugs" validate:"omitempty,dive,min=1"`
+	FeatureSlugs        []string `json:"feature_slugs" validate:"omitempty,dive,min=1"`
+	SegmentIDs          []int    `json:"segment_ids"`
+	Countries           []string `json:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Regions             []string `json:"regions" validate:"omitempty,dive,iso3166_2"`
+	Platforms           []string `json:"platforms" validate:"omitempty,dive,oneof=android ios web"`
+	MinAppVersion       string   `json:"min_app_version"`
+	MaxAppVersion       string   `json:"max_app_version"`
+	RemoveMinAppVersion bool     `json:"remove_min_app_version" validate:"excluded_with=MinAppVersion"` // banner is shown to any older app
+	RemoveMaxAppVersion bool     `json:"remove_max_app_version" validate:"excluded_with=MaxAppVersion"` // banner is shown to any newer app
+	CampaignID          *int     `json:"campaign_id" validate:"omitempty,min=0"`                        // 0 removes banner from its campaign
+	Priority            *int     `json:"priority"`
 	UpdateContentRequest
-	IsActive bool `json:"is_active"`
+	IsActive     bool       `json:"is_active"`
+	EndsAt       *time.Time `json:"ends_at"`
+	RemoveEndsAt bool       `json:"remove_ends_at" validate:"excluded_with=EndsAt"` // banner has no end date
 }
 
 func (br *UpdateBannerRequest) Validate(valid *validator.Validate) error { return valid.Struct(br) }
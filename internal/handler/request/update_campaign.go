@@ -0,0 +1,23 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type UpdateCampaignRequest struct {
+	Name              string     `json:"name" validate:"omitempty,min=1"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	RemoveStartsAt    bool       `json:"remove_starts_at" validate:"excluded_with=StartsAt"` // campaign is started at once
+	RemoveEndsAt      bool       `json:"remove_ends_at" validate:"excluded_with=EndsAt"`     // campaign has no end date
+	ImpressionsBudget int        `json:"impressions_budget" validate:"min=0"`
+	Countries         []string   `json:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Regions           []string   `json:"regions" validate:"omitempty,dive,iso3166_2"`
+	Platforms         []string   `json:"platforms" validate:"omitempty,dive,oneof=android ios web"`
+	MinAppVersion     string     `json:"min_app_version"`
+	MaxAppVersion     string     `json:"max_app_version"`
+}
+
+func (cr *UpdateCampaignRequest) Validate(valid *validator.Validate) error { return valid.Struct(cr) }
@@ -0,0 +1,15 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type UpdateFeatureRequest struct {
+	Name        string   `json:"name" validate:"omitempty,min=1"`
+	Slug        string   `json:"slug"`
+	Description string   `json:"description"`
+	Team        string   `json:"team"`
+	Contact     string   `json:"contact"`
+	Placement   string   `json:"placement"`
+	Labels      []string `json:"labels"`
+}
+
+func (fr *UpdateFeatureRequest) Validate(valid *validator.Validate) error { return valid.Struct(fr) }
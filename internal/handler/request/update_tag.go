@@ -0,0 +1,12 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type UpdateTagRequest struct {
+	Name string `json:"name" validate:"omitempty,min=1"`
+	Slug string `json:"slug"`
+	// ParentID set to 0 makes tag a root of hierarchy
+	ParentID *int `json:"parent_id" validate:"omitempty,min=0"`
+}
+
+func (tr *UpdateTagRequest) Validate(valid *validator.Validate) error { return valid.Struct(tr) }
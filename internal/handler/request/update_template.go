@@ -0,0 +1,12 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type UpdateTemplateRequest struct {
+	Name  string `json:"name" validate:"omitempty,min=1"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	Url   string `json:"url"`
+}
+
+func (tr *UpdateTemplateRequest) Validate(valid *validator.Validate) error { return valid.Struct(tr) }
@@ -0,0 +1,7 @@
+package response
+
+// BatchUserBannerResultResponse is banner found for the query of a batch or error describing why it is not found
+type BatchUserBannerResultResponse struct {
+	Banner *GetUserBannerResponse `json:"banner,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
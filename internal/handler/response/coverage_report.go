@@ -0,0 +1,22 @@
+package response
+
+import "time"
+
+type BannerMissResponse struct {
+	FeatureID    int       `json:"feature_id"`
+	TagIDs       []int     `json:"tag_ids"`
+	MissesCount  int       `json:"misses_count"`
+	LastMissedAt time.Time `json:"last_missed_at"`
+}
+
+type InactiveCombinationResponse struct {
+	FeatureID int    `json:"feature_id"`
+	TagIDs    []int  `json:"tag_ids"`
+	BannerIDs []int  `json:"banner_ids"`
+	Reason    string `json:"reason" enums:"inactive,expired"`
+}
+
+type CoverageReportResponse struct {
+	Uncovered []BannerMissResponse          `json:"uncovered"`
+	Inactive  []InactiveCombinationResponse `json:"inactive"`
+}
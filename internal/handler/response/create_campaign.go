@@ -0,0 +1,5 @@
+package response
+
+type CreateCampaignResponse struct {
+	ID int `json:"campaign_id"`
+}
@@ -0,0 +1,5 @@
+package response
+
+type CreateFeatureResponse struct {
+	ID int `json:"feature_id"`
+}
@@ -0,0 +1,5 @@
+package response
+
+type CreateScheduledEditResponse struct {
+	ID int `json:"scheduled_edit_id"`
+}
@@ -0,0 +1,6 @@
+package response
+
+type CreateSegmentResponse struct {
+	ID         int `json:"segment_id"`
+	UsersCount int `json:"users_count"`
+}
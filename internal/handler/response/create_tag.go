@@ -0,0 +1,5 @@
+package response
+
+type CreateTagResponse struct {
+	ID int `json:"tag_id"`
+}
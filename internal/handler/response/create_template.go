@@ -0,0 +1,6 @@
+package response
+
+type CreateTemplateResponse struct {
+	ID           int      `json:"template_id"`
+	Placeholders []string `json:"placeholders"`
+}
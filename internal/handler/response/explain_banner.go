@@ -0,0 +1,22 @@
+package response
+
+type BannerCandidateResponse struct {
+	BannerID int    `json:"banner_id"`
+	TagIDs   []int  `json:"tag_ids"`
+	IsActive bool   `json:"is_active"`
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason"`
+}
+
+type ExplainBannerResponse struct {
+	FeatureID  int    `json:"feature_id"`
+	TagIDs     []int  `json:"tag_ids"`
+	UserID     int    `json:"user_id"`
+	Country    string `json:"country"`
+	Region     string `json:"region"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+	// CacheHit is set if user currently gets cached banner instead of resolved one
+	CacheHit   bool                      `json:"cache_hit"`
+	Candidates []BannerCandidateResponse `json:"candidates"`
+}
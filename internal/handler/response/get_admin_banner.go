@@ -9,11 +9,33 @@ type GetContentResponse struct {
 }
 
 type GetAdminBannerResponse struct {
-	ID        int   `json:"banner_id"`
-	TagIDs    []int `json:"tag_ids"`
-	FeatureID int   `json:"feature_id"`
+	ID            int      `json:"banner_id"`
+	TagIDs        []int    `json:"tag_ids"`
+	FeatureIDs    []int    `json:"feature_ids"`
+	SegmentIDs    []int    `json:"segment_ids"`
+	Countries     []string `json:"countries"`
+	Regions       []string `json:"regions"`
+	Platforms     []string `json:"platforms"`
+	MinAppVersion string   `json:"min_app_version"`
+	MaxAppVersion string   `json:"max_app_version"`
+	CampaignID    *int     `json:"campaign_id"`
+	Priority      *int     `json:"priority"`
 	GetContentResponse
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	IsActive   bool                        `json:"is_active"`
+	EndsAt     *time.Time                  `json:"ends_at"`
+	ArchivedAt *time.Time                  `json:"archived_at,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	UpdatedAt  time.Time                   `json:"updated_at"`
+	Features   []*GetBannerFeatureResponse `json:"features,omitempty"`
+}
+
+// GetBannerFeatureResponse describes feature of the banner, so admins can see which screens banner is shown on
+type GetBannerFeatureResponse struct {
+	Name        string   `json:"name"`
+	Slug        string   `json:"slug"`
+	Description string   `json:"description"`
+	Team        string   `json:"team"`
+	Contact     string   `json:"contact"`
+	Placement   string   `json:"placement"`
+	Labels      []string `json:"labels"`
 }
@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+type GetAssetResponse struct {
+	ID        int       `json:"asset_id"`
+	Hash      string    `json:"hash"`
+	URL       string    `json:"url"`
+	MimeType  string    `json:"mime_type"`
+	Size      int       `json:"size"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	CreatedAt time.Time `json:"created_at"`
+}
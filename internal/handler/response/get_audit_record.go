@@ -0,0 +1,16 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GetAuditRecordResponse struct {
+	ID         int             `json:"audit_record_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Username   string          `json:"username"`
+	Details    json.RawMessage `json:"details" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package response
+
+import "time"
+
+type GetCampaignResponse struct {
+	ID                int        `json:"campaign_id"`
+	Name              string     `json:"name"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	ImpressionsBudget int        `json:"impressions_budget"`
+	ImpressionsCount  int        `json:"impressions_count"`
+	Countries         []string   `json:"countries"`
+	Regions           []string   `json:"regions"`
+	Platforms         []string   `json:"platforms"`
+	MinAppVersion     string     `json:"min_app_version"`
+	MaxAppVersion     string     `json:"max_app_version"`
+	IsRunning         bool       `json:"is_running"`
+	BannersCount      int        `json:"banners_count"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
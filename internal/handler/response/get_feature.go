@@ -0,0 +1,18 @@
+package response
+
+import "time"
+
+type GetFeatureResponse struct {
+	ID           int       `json:"feature_id"`
+	Name         string    `json:"name"`
+	Slug         string    `json:"slug"`
+	Description  string    `json:"description"`
+	Team         string    `json:"team"`
+	Contact      string    `json:"contact"`
+	Placement    string    `json:"placement"`
+	Labels       []string  `json:"labels"`
+	IsEnabled    bool      `json:"is_enabled"`
+	BannersCount int       `json:"banners_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,7 @@
+package response
+
+// GetFeatureBannersResponse describes banners shown to user in the feature
+type GetFeatureBannersResponse struct {
+	FeatureID int                     `json:"feature_id"`
+	Banners   []GetUserBannerResponse `json:"banners"`
+}
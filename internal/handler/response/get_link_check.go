@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+type GetLinkCheckResponse struct {
+	BannerID   int       `json:"banner_id"`
+	Url        string    `json:"url"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	Redirects  []string  `json:"redirects"`
+	IsBroken   bool      `json:"is_broken"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
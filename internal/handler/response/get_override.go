@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type GetOverrideResponse struct {
+	ID         int       `json:"override_id"`
+	BannerID   int       `json:"banner_id"`
+	FeatureIDs []int     `json:"feature_ids"`
+	UserID     int       `json:"user_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
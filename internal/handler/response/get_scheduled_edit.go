@@ -0,0 +1,37 @@
+package response
+
+import "time"
+
+type GetScheduledEditResponse struct {
+	ID        int                 `json:"scheduled_edit_id"`
+	BannerID  int                 `json:"banner_id"`
+	Patch     BannerPatchResponse `json:"patch"`
+	ApplyAt   time.Time           `json:"apply_at"`
+	Status    string              `json:"status"`
+	Attempts  int                 `json:"attempts"`
+	LastError string              `json:"last_error,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// BannerPatchResponse has the same shape as banner update request, omitted fields are not updated,
+// null targeting is not updated and empty one is removed
+type BannerPatchResponse struct {
+	TagIDs              []int      `json:"tag_ids,omitempty"`
+	FeatureIDs          []int      `json:"feature_ids,omitempty"`
+	SegmentIDs          []int      `json:"segment_ids,omitempty"`
+	Countries           []string   `json:"countries"`
+	Regions             []string   `json:"regions"`
+	Platforms           []string   `json:"platforms"`
+	MinAppVersion       string     `json:"min_app_version,omitempty"`
+	MaxAppVersion       string     `json:"max_app_version,omitempty"`
+	RemoveMinAppVersion bool       `json:"remove_min_app_version,omitempty"`
+	RemoveMaxAppVersion bool       `json:"remove_max_app_version,omitempty"`
+	CampaignID          *int       `json:"campaign_id,omitempty"`
+	Priority            *int       `json:"priority,omitempty"`
+	Title               string     `json:"title,omitempty"`
+	Text                string     `json:"text,omitempty"`
+	Url                 string     `json:"url,omitempty"`
+	IsActive            *bool      `json:"is_active,omitempty"`
+	EndsAt              *time.Time `json:"ends_at,omitempty"`
+	RemoveEndsAt        bool       `json:"remove_ends_at,omitempty"`
+}
@@ -0,0 +1,11 @@
+package response
+
+import "time"
+
+type GetSegmentResponse struct {
+	ID         int       `json:"segment_id"`
+	Name       string    `json:"name"`
+	UsersCount int       `json:"users_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+type GetTagResponse struct {
+	ID           int       `json:"tag_id"`
+	Name         string    `json:"name"`
+	Slug         string    `json:"slug"`
+	ParentID     *int      `json:"parent_id"`
+	AliasOfID    *int      `json:"alias_of_id"`
+	BannersCount int       `json:"banners_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+type GetTemplateResponse struct {
+	ID           int       `json:"template_id"`
+	Name         string    `json:"name"`
+	Title        string    `json:"title"`
+	Text         string    `json:"text"`
+	Url          string    `json:"url"`
+	Placeholders []string  `json:"placeholders"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,9 @@
+package response
+
+type MergeTagsResponse struct {
+	SourceID          int  `json:"source_tag_id"`
+	TargetID          int  `json:"target_tag_id"`
+	KeepAlias         bool `json:"keep_alias"`
+	MovedBanners      int  `json:"moved_banners"`
+	RemovedDuplicates int  `json:"removed_duplicates"`
+}
@@ -0,0 +1,8 @@
+package segment
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+
+	MaxUploadSize = 32 << 20 // 32 MB
+)
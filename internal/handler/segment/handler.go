@@ -0,0 +1,201 @@
+package segment
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	csvutils "avito-backend-trainee-2024/pkg/utils/csv"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllSegments(ctx context.Context, offset, limit int) ([]*entity.Segment, error)
+	CreateSegment(ctx context.Context, segment entity.Segment, userIDs []int) (*entity.Segment, error)
+	DeleteSegment(ctx context.Context, id int) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllSegments)
+		r.Post("/", h.CreateSegment)
+		r.Delete("/{id}", h.DeleteSegment)
+	})
+
+	return router
+}
+
+// GetAllSegments godoc
+//
+//	@Summary		Get all segments
+//	@Description	Get all user segments with count of users in each
+//	@Security		JWT
+//	@Tags			Segment
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetSegmentResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/segment [get]
+func (h *Handler) GetAllSegments(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	segments, err := h.Service.GetAllSegments(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching segments: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(segments, mapper.MapSegmentToGetSegmentResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CreateSegment godoc
+//
+//	@Summary		Upload new segment
+//	@Description	Create named segment from csv file, first column of which contains user ids
+//	@Security		JWT
+//	@Tags			Segment
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			name	formData	string	true	"name of the segment"
+//	@Param			file	formData	file	true	"csv file with user ids"
+//	@Success		200		{object}	response.CreateSegmentResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/segment [post]
+func (h *Handler) CreateSegment(rw http.ResponseWriter, req *http.Request) {
+	if err := req.ParseMultipartForm(MaxUploadSize); err != nil {
+		msg := fmt.Sprintf("error occurred parsing multipart form: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	segmentReq := request.CreateSegmentRequest{
+		Name: req.FormValue("name"),
+	}
+
+	if err := segmentReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateSegmentRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting 'file' from form: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	defer file.Close()
+
+	userIDs, err := csvutils.ReadIntColumn(file)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred reading user ids from csv: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CreateSegment(req.Context(), mapper.MapCreateSegmentRequestToEntity(&segmentReq), userIDs)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating segment: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapSegmentToCreateSegmentResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// DeleteSegment godoc
+//
+//	@Summary		Delete segment
+//	@Description	Delete segment which is not used by any banner
+//	@Security		JWT
+//	@Tags			Segment
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the segment"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/segment/{id} [delete]
+func (h *Handler) DeleteSegment(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.DeleteSegment(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred deleting segment: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,363 @@
+package tag
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/go-playground/validator/v10"
+
+	"avito-backend-trainee-2024/internal/handler/middleware"
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllTags(ctx context.Context, name string, offset, limit int) ([]*entity.Tag, error)
+	GetTagByID(ctx context.Context, id int) (*entity.Tag, error)
+	CreateTag(ctx context.Context, tag entity.Tag) (*entity.Tag, error)
+	UpdateTag(ctx context.Context, id int, updateModel entity.Tag) error
+	DeleteTag(ctx context.Context, id int, detach bool) error
+	MergeTags(ctx context.Context, sourceID, targetID int, keepAlias bool, username string) (*entity.TagMerge, error)
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	// slugCache holds ids resolved from slugs by user banner routes, ids of changed tags are dropped from it
+	slugCache *cache.Cache
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, slugCache *cache.Cache, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		slugCache:   slugCache,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllTags)
+		r.Post("/", h.CreateTag)
+		r.Get("/{id}", h.GetTag)
+		r.Patch("/{id}", h.UpdateTag)
+		r.Delete("/{id}", h.DeleteTag)
+		r.Post("/{id}/merge", h.MergeTags)
+	})
+
+	return router
+}
+
+// GetAllTags godoc
+//
+//	@Summary		Get all tags
+//	@Description	Get all tags sorting by id, tags can be searched by part of the name
+//	@Security		JWT
+//	@Tags			Tag
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			name	query		string	false	"Part of the tag name"
+//	@Param			offset	query		int		false	"Offset"
+//	@Param			limit	query		int		false	"Limit"
+//	@Success		200		{object}	[]response.GetTagResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/tag [get]
+func (h *Handler) GetAllTags(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	tags, err := h.Service.GetAllTags(req.Context(), req.URL.Query().Get("name"), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching tags: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(tags, mapper.MapTagToGetTagResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetTag godoc
+//
+//	@Summary		Get tag
+//	@Description	Get tag with count of its banners
+//	@Security		JWT
+//	@Tags			Tag
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path		int	true	"id of the tag"
+//	@Success		200	{object}	response.GetTagResponse
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/tag/{id} [get]
+func (h *Handler) GetTag(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	tag, err := h.Service.GetTagByID(req.Context(), id)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching tag: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapTagToGetTagResponse(tag))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CreateTag godoc
+//
+//	@Summary		Create new tag
+//	@Description	Create new tag, banners tagged with its ancestors are matched by it
+//	@Security		JWT
+//	@Tags			Tag
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.CreateTagRequest	true	"create tag schema"
+//	@Success		200		{object}	response.CreateTagResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/tag [post]
+func (h *Handler) CreateTag(rw http.ResponseWriter, req *http.Request) {
+	var tagReq request.CreateTagRequest
+
+	if err := render.DecodeJSON(req.Body, &tagReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateTagRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := tagReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateTagRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CreateTag(req.Context(), mapper.MapCreateTagRequestToEntity(&tagReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating tag: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapTagToCreateTagResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// UpdateTag godoc
+//
+//	@Summary		Update tag
+//	@Description	Rename existing tag or move it in tags hierarchy, parent_id = 0 makes tag a root one
+//	@Security		JWT
+//	@Tags			Tag
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body	request.UpdateTagRequest	true	"update tag schema"
+//	@Param			id		path	int								true	"id of the updating tag"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/tag/{id} [patch]
+func (h *Handler) UpdateTag(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var updateReq request.UpdateTagRequest
+
+	if err = render.DecodeJSON(req.Body, &updateReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to UpdateTagRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = updateReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating UpdateTagRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.UpdateTag(req.Context(), id, mapper.MapUpdateTagRequestToEntity(&updateReq)); err != nil {
+		msg := fmt.Sprintf("error occurred updating tag: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	middleware.ForgetTagSlugs(h.slugCache, id)
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// DeleteTag godoc
+//
+//	@Summary		Delete tag
+//	@Description	Delete tag which is not used by any banner, with detach=true tag is removed from its banners first unless banners would conflict without it
+//	@Security		JWT
+//	@Tags			Tag
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id		path	int		true	"id of the tag"
+//	@Param			detach	query	bool	false	"detach tag from banners using it"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		409	{string}	conflict	banners
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/tag/{id} [delete]
+func (h *Handler) DeleteTag(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	detach := false
+
+	if detachStr := req.URL.Query().Get("detach"); detachStr != "" {
+		detach, err = strconv.ParseBool(detachStr)
+		if err != nil {
+			msg := fmt.Sprintf("inavlid query param for detach provided: %v", err)
+
+			handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+			return
+		}
+	}
+
+	if err = h.Service.DeleteTag(req.Context(), id, detach); err != nil {
+		msg := fmt.Sprintf("error occurred deleting tag: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, handlerinternalutils.GetBannerChangeErrStatus(err), msg, msg)
+		return
+	}
+
+	middleware.ForgetTagSlugs(h.slugCache, id)
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// MergeTags godoc
+//
+//	@Summary		Merge tag into another one
+//	@Description	Move banners of the tag to target tag and delete the tag or leave it as alias of target tag, merge is recorded to audit log, merge leaving banners sharing feature, tags, targeting and priority is rejected
+//	@Security		JWT
+//	@Tags			Tag
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.MergeTagsRequest	true	"merge tags schema"
+//	@Param			id		path		int							true	"id of the merged tag"
+//	@Success		200		{object}	response.MergeTagsResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		409		{string}	conflict	banners
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/tag/{id}/merge [post]
+func (h *Handler) MergeTags(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	username, err := handlerutils.GetStringHeaderByKey(req, "username")
+	if err != nil {
+		msg := fmt.Sprintf("error occurred getting username: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusUnauthorized, msg, msg)
+		return
+	}
+
+	var mergeReq request.MergeTagsRequest
+
+	if err = render.DecodeJSON(req.Body, &mergeReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to MergeTagsRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = mergeReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating MergeTagsRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	merge, err := h.Service.MergeTags(req.Context(), id, mergeReq.TargetID, mergeReq.KeepAlias, username)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred merging tags: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, handlerinternalutils.GetBannerChangeErrStatus(err), msg, msg)
+		return
+	}
+
+	middleware.ForgetTagSlugs(h.slugCache, id)
+
+	render.JSON(rw, req, mapper.MapTagMergeToMergeTagsResponse(merge))
+	rw.WriteHeader(http.StatusOK)
+}
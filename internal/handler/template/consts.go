@@ -0,0 +1,6 @@
+package template
+
+const (
+	DefaultOffset = 0
+	DefaultLimit  = 100
+)
@@ -0,0 +1,368 @@
+package template
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/mapper"
+	"avito-backend-trainee-2024/internal/handler/request"
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/go-playground/validator/v10"
+
+	handlerinternalutils "avito-backend-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
+)
+
+type Service interface {
+	GetAllTemplates(ctx context.Context, offset, limit int) ([]*entity.BannerTemplate, error)
+	GetTemplateByID(ctx context.Context, id int) (*entity.BannerTemplate, error)
+	CreateTemplate(ctx context.Context, template entity.BannerTemplate) (*entity.BannerTemplate, error)
+	UpdateTemplate(ctx context.Context, id int, updateModel entity.BannerTemplate) error
+	DeleteTemplate(ctx context.Context, id int) error
+	InstantiateTemplate(ctx context.Context, id int, values map[string]string, banner entity.Banner) (*entity.Banner, error)
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAllTemplates)
+		r.Post("/", h.CreateTemplate)
+		r.Get("/{id}", h.GetTemplate)
+		r.Patch("/{id}", h.UpdateTemplate)
+		r.Delete("/{id}", h.DeleteTemplate)
+		r.Post("/{id}/banner", h.InstantiateTemplate)
+	})
+
+	return router
+}
+
+// GetAllTemplates godoc
+//
+//	@Summary		Get all banner templates
+//	@Description	Get all banner templates sorting by id with placeholders found in their content
+//	@Security		JWT
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			offset	query		int	false	"Offset"
+//	@Param			limit	query		int	false	"Limit"
+//	@Success		200		{object}	[]response.GetTemplateResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/template [get]
+func (h *Handler) GetAllTemplates(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, DefaultOffset, DefaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	templates, err := h.Service.GetAllTemplates(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching templates: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(templates, mapper.MapTemplateToGetTemplateResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GetTemplate godoc
+//
+//	@Summary		Get banner template
+//	@Description	Get banner template with placeholders found in its content
+//	@Security		JWT
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path		int	true	"id of the template"
+//	@Success		200	{object}	response.GetTemplateResponse
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/template/{id} [get]
+func (h *Handler) GetTemplate(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	template, err := h.Service.GetTemplateByID(req.Context(), id)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching template: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapTemplateToGetTemplateResponse(template))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CreateTemplate godoc
+//
+//	@Summary		Create new banner template
+//	@Description	Create new banner template, its content may contain placeholders like {{discount}} filled when banner is created from template
+//	@Security		JWT
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.CreateTemplateRequest	true	"create template schema"
+//	@Success		201		{object}	response.CreateTemplateResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/template [post]
+func (h *Handler) CreateTemplate(rw http.ResponseWriter, req *http.Request) {
+	var templateReq request.CreateTemplateRequest
+
+	if err := render.DecodeJSON(req.Body, &templateReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateTemplateRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err := templateReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateTemplateRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.CreateTemplate(req.Context(), mapper.MapCreateTemplateRequestToEntity(&templateReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating template: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapTemplateToCreateTemplateResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// UpdateTemplate godoc
+//
+//	@Summary		Update banner template
+//	@Description	Update name or content of banner template, empty fields are not updated, banners created from template are not changed
+//	@Security		JWT
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body	request.UpdateTemplateRequest	true	"update template schema"
+//	@Param			id		path	int								true	"id of the updating template"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/template/{id} [patch]
+func (h *Handler) UpdateTemplate(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var updateReq request.UpdateTemplateRequest
+
+	if err = render.DecodeJSON(req.Body, &updateReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to UpdateTemplateRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = updateReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating UpdateTemplateRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.UpdateTemplate(req.Context(), id, mapper.MapUpdateTemplateRequestToEntity(&updateReq)); err != nil {
+		msg := fmt.Sprintf("error occurred updating template: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// DeleteTemplate godoc
+//
+//	@Summary		Delete banner template
+//	@Description	Delete banner template, banners created from it are kept
+//	@Security		JWT
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			id	path	int	true	"id of the template"
+//	@Success		200
+//	@Failure		401	{string}	Unauthorized
+//	@Failure		403	{string}	Forbidden
+//	@Failure		400	{string}	invalid		request
+//	@Failure		500	{string}	internal	error
+//	@Router			/avito-trainee/api/v1/template/{id} [delete]
+func (h *Handler) DeleteTemplate(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = h.Service.DeleteTemplate(req.Context(), id); err != nil {
+		msg := fmt.Sprintf("error occurred deleting template: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// InstantiateTemplate godoc
+//
+//	@Summary		Create banner from template
+//	@Description	Create banner with content of template, each placeholder of template must be filled with not blank value,
+//	@Description	features and tags may be referred by slugs instead of ids
+//	@Security		JWT
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param token 	header string true "admin auth token"
+//	@Param			input	body		request.InstantiateRequest	true	"instantiate template schema"
+//	@Param			id		path		int							true	"id of the template"
+//	@Success		201		{object}	response.CreateBannerResponse
+//	@Failure		401		{string}	Unauthorized
+//	@Failure		403		{string}	Forbidden
+//	@Failure		400		{string}	invalid		request
+//	@Failure		500		{string}	internal	error
+//	@Router			/avito-trainee/api/v1/template/{id}/banner [post]
+func (h *Handler) InstantiateTemplate(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		msg := fmt.Sprintf("inavlid url param for id provided: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	var instantiateReq request.InstantiateRequest
+
+	if err = render.DecodeJSON(req.Body, &instantiateReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to InstantiateRequest srtuct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	err = h.resolveSlugs(req.Context(), instantiateReq.FeatureSlugs, instantiateReq.TagSlugs, &instantiateReq.FeatureIDs, &instantiateReq.TagIDs)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred resolving slugs of InstantiateRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	if err = instantiateReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating InstantiateRequest struct: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	created, err := h.Service.InstantiateTemplate(req.Context(), id, instantiateReq.Values, mapper.MapInstantiateRequestToEntity(&instantiateReq))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred creating banner from template: %v", err)
+
+		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapBannerToCreateBannerResponse(created))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// resolveSlugs replaces feature ids and tag ids with ids of features and tags referred by slugs if they are provided
+func (h *Handler) resolveSlugs(ctx context.Context, featureSlugs, tagSlugs []string, featureIDs, tagIDs *[]int) error {
+	if len(featureSlugs) != 0 {
+		IDs := make([]int, 0, len(featureSlugs))
+
+		for _, slug := range featureSlugs {
+			id, err := h.Service.GetFeatureIDBySlug(ctx, slug)
+			if err != nil {
+				return err
+			}
+
+			IDs = append(IDs, id)
+		}
+
+		*featureIDs = IDs
+	}
+
+	if len(tagSlugs) != 0 {
+		IDs, err := h.Service.GetTagIDsBySlugs(ctx, tagSlugs)
+		if err != nil {
+			return err
+		}
+
+		*tagIDs = IDs
+	}
+
+	return nil
+}
@@ -3,8 +3,8 @@ package entity
 import "avito-backend-trainee-2024/internal/domain/entity"
 
 func InitNilFieldsOfBanner(banner1 *entity.Banner, banner2 *entity.Banner) {
-	if banner1.FeatureID == 0 {
-		banner1.FeatureID = banner2.FeatureID
+	if banner1.FeatureIDs == nil {
+		banner1.FeatureIDs = banner2.FeatureIDs
 	}
 
 	if banner1.TagIDs == nil {
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"avito-backend-trainee-2024/internal/domain/entity"
 	"avito-backend-trainee-2024/internal/handler/request"
 
 	handlerutils "avito-backend-trainee-2024/pkg/utils/handler"
@@ -26,3 +28,15 @@ func GetPaginationOptsFromQuery(req *http.Request, defaultOffset int, defaultLim
 
 	return paginationOpts
 }
+
+// GetBannerChangeErrStatus returns status of failed change of banners, change leaving banners indistinguishable
+// by lookup is a conflict, any other failure is treated as invalid request
+func GetBannerChangeErrStatus(err error) int {
+	var conflict *entity.BannerConflictError
+
+	if errors.As(err, &conflict) {
+		return http.StatusConflict
+	}
+
+	return http.StatusBadRequest
+}
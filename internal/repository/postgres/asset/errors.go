@@ -0,0 +1,7 @@
+package asset
+
+import "errors"
+
+var (
+	ErrNoSuchAsset = errors.New("no such asset")
+)
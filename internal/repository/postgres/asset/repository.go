@@ -0,0 +1,94 @@
+package asset
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+const selectAssetsQuery = `SELECT id, hash, mime_type, size, width, height, created_at FROM asset`
+
+// GetAllAssets returns assets, the latest uploaded first
+func (r *Repo) GetAllAssets(ctx context.Context, offset, limit int) ([]*entity.Asset, error) {
+	query := fmt.Sprintf(`%v ORDER BY id DESC`, selectAssetsQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	var assets []*entity.Asset
+
+	if err := r.DB.SelectContext(ctx, &assets, query); err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// GetAssetByHash returns asset with hash, nil is returned if there is no such asset
+func (r *Repo) GetAssetByHash(ctx context.Context, hash string) (*entity.Asset, error) {
+	var asset entity.Asset
+
+	err := r.DB.GetContext(ctx, &asset, fmt.Sprintf("%v WHERE hash = $1", selectAssetsQuery), hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// CreateAsset creates asset, asset with the same hash is returned if it was uploaded before
+func (r *Repo) CreateAsset(ctx context.Context, asset entity.Asset) (*entity.Asset, error) {
+	var created entity.Asset
+
+	err := r.DB.GetContext(
+		ctx,
+		&created,
+		`INSERT INTO asset (hash, mime_type, size, width, height) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (hash) DO UPDATE SET hash = excluded.hash
+RETURNING id, hash, mime_type, size, width, height, created_at`,
+		asset.Hash, asset.MimeType, asset.Size, asset.Width, asset.Height,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// DeleteAsset deletes asset and returns it, so its content can be removed from storage
+func (r *Repo) DeleteAsset(ctx context.Context, id int) (*entity.Asset, error) {
+	var deleted entity.Asset
+
+	err := r.DB.GetContext(ctx, &deleted, `DELETE FROM asset WHERE id = $1
+RETURNING id, hash, mime_type, size, width, height, created_at`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoSuchAsset
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// GetAuditRecords returns audit records of the entity type, the newest first, all records are returned if entityType is empty
+func (r *Repo) GetAuditRecords(ctx context.Context, entityType string, offset, limit int) ([]*entity.AuditRecord, error) {
+	query := `SELECT id, action, entity_type, entity_id, username, details::text AS details, created_at
+FROM audit_log
+WHERE $1 = '' OR entity_type = $1
+ORDER BY id DESC`
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	var records []*entity.AuditRecord
+
+	if err := r.DB.SelectContext(ctx, &records, query, entityType); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
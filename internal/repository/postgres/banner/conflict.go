@@ -0,0 +1,87 @@
+package banner
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
+)
+
+// lockFeaturesQuery serializes changes of banners shown in the same features until the end of transaction,
+// so concurrent transactions cannot both pass conflict check and create conflicting banners
+const lockFeaturesQuery = `SELECT pg_advisory_xact_lock(hashtext('banner_feature'), f.feature_id)
+FROM (SELECT DISTINCT feature_id FROM banner_feature WHERE banner_id = ANY ($1::integer[]) ORDER BY feature_id) f`
+
+// conflictsQuery selects pairs of active not archived banners sharing a feature, tags, targeting and priority,
+// at least one banner of the pair is one of the checked ones, banners of different priorities are ranked by it
+const conflictsQuery = `WITH signature AS (SELECT banner.id,
+                          bf.feature_id,
+                          ARRAY(SELECT bt.tag_id FROM banner_tag bt WHERE bt.banner_id = banner.id ORDER BY bt.tag_id) AS tag_ids,
+                          ARRAY(SELECT bs.segment_id
+                                FROM banner_segment bs
+                                WHERE bs.banner_id = banner.id
+                                ORDER BY bs.segment_id)                                                            AS segment_ids,
+                          ARRAY(SELECT c FROM unnest(banner.countries) c ORDER BY c)                           AS countries,
+                          ARRAY(SELECT r FROM unnest(banner.regions) r ORDER BY r)                             AS regions,
+                          ARRAY(SELECT p FROM unnest(banner.platforms) p ORDER BY p)                           AS platforms,
+                          banner.min_app_version,
+                          banner.max_app_version,
+                          banner.priority
+                   FROM banner
+                            JOIN banner_feature bf ON bf.banner_id = banner.id
+                   WHERE banner.is_active
+                     AND banner.archived_at IS NULL
+                     AND bf.feature_id IN (SELECT feature_id FROM banner_feature WHERE banner_id = ANY ($1::integer[])))
+SELECT DISTINCT checked.id AS checked_id, other.id AS other_id
+FROM signature checked
+         JOIN signature other ON other.id <> checked.id
+    AND other.feature_id = checked.feature_id
+    AND other.tag_ids = checked.tag_ids
+    AND other.segment_ids = checked.segment_ids
+    AND other.countries = checked.countries
+    AND other.regions = checked.regions
+    AND other.platforms = checked.platforms
+    AND other.min_app_version = checked.min_app_version
+    AND other.max_app_version = checked.max_app_version
+    AND other.priority = checked.priority
+WHERE checked.id = ANY ($1::integer[])`
+
+// CheckConflicts returns *entity.BannerConflictError if some of banners changed in transaction conflicts with another
+// banner, features of the banners stay locked until transaction ends, so the check holds until commit
+func CheckConflicts(ctx context.Context, tx *sqlx.Tx, bannerIDs []int) error {
+	if len(bannerIDs) == 0 {
+		return nil
+	}
+
+	IDs := stringutils.IntSliceToPostgresArray(bannerIDs)
+
+	if _, err := tx.ExecContext(ctx, lockFeaturesQuery, IDs); err != nil {
+		return err
+	}
+
+	var pairs []struct {
+		CheckedID int `db:"checked_id"`
+		OtherID   int `db:"other_id"`
+	}
+
+	if err := tx.SelectContext(ctx, &pairs, conflictsQuery, IDs); err != nil {
+		return err
+	}
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	var conflicting []int
+
+	for _, pair := range pairs {
+		conflicting = append(conflicting, pair.CheckedID, pair.OtherID)
+	}
+
+	slices.Sort(conflicting)
+
+	return &entity.BannerConflictError{BannerIDs: slices.Compact(conflicting)}
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"math"
+	"slices"
+	"strings"
 	"time"
 
 	stringutils "avito-backend-trainee-2024/pkg/utils/string"
@@ -23,236 +25,328 @@ func New(db *sqlx.DB) *Repo {
 	}
 }
 
-func setQueryForUpdateModel(updateModel entity.Banner) string {
-	setQuery := ""
-	isCommaNeeded := false
-
-	if updateModel.Content.Title != "" {
-		setQuery += fmt.Sprintf("title = '%v'", updateModel.Content.Title)
-		isCommaNeeded = true
-	}
-
-	if updateModel.Content.Text != "" {
-		if isCommaNeeded {
-			setQuery += ", "
-		}
-
-		setQuery += fmt.Sprintf("text = '%v'", updateModel.Content.Text)
-		isCommaNeeded = true
-	}
+// setQueryForUpdateModel returns set clause updating not empty content fields and values bound to it
+func setQueryForUpdateModel(updateModel entity.Banner) (string, []any) {
+	var (
+		sets []string
+		args []any
+	)
 
-	if updateModel.Content.Url != "" {
-		if isCommaNeeded {
-			setQuery += ", "
+	for _, field := range []struct {
+		column string
+		value  string
+	}{
+		{"title", updateModel.Content.Title},
+		{"text", updateModel.Content.Text},
+		{"url", updateModel.Content.Url},
+	} {
+		if field.value != "" {
+			args = append(args, field.value)
+			sets = append(sets, fmt.Sprintf("%v = $%v", field.column, len(args)))
 		}
-
-		setQuery += fmt.Sprintf("url = '%v'", updateModel.Content.Url)
-		isCommaNeeded = true
 	}
 
-	return setQuery
+	return strings.Join(sets, ", "), args
 }
 
-func (r *Repo) GetAllBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error) {
-	query := `SELECT banner.id,
-       feature_id,
+// selectBannersQuery selects banners with their content, features, tags and targeting,
+// must be followed by GROUP BY banner.id, c.content_id
+const selectBannersQuery = `SELECT banner.id,
+       COALESCE((SELECT array_agg(bf.feature_id ORDER BY bf.feature_id)
+                 FROM banner_feature bf
+                 WHERE bf.banner_id = banner.id), '{}') AS feature_ids,
        is_active,
        created_at,
        updated_at,
        title,
        text,
        url,
-       array_agg(bt.tag_id ORDER BY bt.tag_id) AS tag_ids
+       array_agg(bt.tag_id ORDER BY bt.tag_id) AS tag_ids,
+       COALESCE((SELECT array_agg(bs.segment_id ORDER BY bs.segment_id)
+                 FROM banner_segment bs
+                 WHERE bs.banner_id = banner.id), '{}') AS segment_ids,
+       countries,
+       regions,
+       platforms,
+       min_app_version,
+       max_app_version,
+       campaign_id,
+       priority,
+       ends_at,
+       archived_at
 FROM banner
          JOIN public.content c ON c.content_id = banner.content_id
-         JOIN public.banner_tag bt ON banner.id = bt.banner_id
-GROUP BY c.content_id, banner.id, feature_id
-ORDER BY feature_id`
+         JOIN public.banner_tag bt ON banner.id = bt.banner_id`
+
+type bannerRow struct {
+	ID            int        `db:"id"`
+	FeatureIDsStr string     `db:"feature_ids"`
+	TagIDsStr     string     `db:"tag_ids"`
+	SegmentIDsStr string     `db:"segment_ids"`
+	CountriesStr  string     `db:"countries"`
+	RegionsStr    string     `db:"regions"`
+	PlatformsStr  string     `db:"platforms"`
+	MinAppVersion string     `db:"min_app_version"`
+	MaxAppVersion string     `db:"max_app_version"`
+	CampaignID    *int       `db:"campaign_id"`
+	Priority      int        `db:"priority"`
+	IsActive      bool       `db:"is_active"`
+	EndsAt        *time.Time `db:"ends_at"`
+	ArchivedAt    *time.Time `db:"archived_at"`
+	Title         string     `db:"title"`
+	Text          string     `db:"text"`
+	Url           string     `db:"url"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
 
-	if limit == math.MaxInt64 {
-		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
-	} else {
-		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+func (row *bannerRow) toEntity() (*entity.Banner, error) {
+	// arrays have structure {1,2,...}
+	featureIDs, err := stringutils.FillIntSliceFromPostgresArray(row.FeatureIDsStr)
+	if err != nil {
+		return nil, err
 	}
 
-	type Row struct {
-		ID        int       `db:"id"`
-		FeatureID int       `db:"feature_id"`
-		TagIDsStr string    `db:"tag_ids"`
-		IsActive  bool      `db:"is_active"`
-		Title     string    `db:"title"`
-		Text      string    `db:"text"`
-		Url       string    `db:"url"`
-		CreatedAt time.Time `db:"created_at"`
-		UpdatedAt time.Time `db:"updated_at"`
+	tagIDs, err := stringutils.FillIntSliceFromPostgresArray(row.TagIDsStr)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentIDs, err := stringutils.FillIntSliceFromPostgresArray(row.SegmentIDsStr)
+	if err != nil {
+		return nil, err
+	}
 
-		TagIDsInt []int
+	content := entity.Content{
+		Title: row.Title,
+		Text:  row.Text,
+		Url:   row.Url,
 	}
 
-	rows, err := r.DB.Queryx(query)
+	return &entity.Banner{
+		ID:            row.ID,
+		TagIDs:        tagIDs,
+		FeatureIDs:    featureIDs,
+		SegmentIDs:    segmentIDs,
+		Countries:     stringutils.FillStringSliceFromPostgresArray(row.CountriesStr),
+		Regions:       stringutils.FillStringSliceFromPostgresArray(row.RegionsStr),
+		Platforms:     stringutils.FillStringSliceFromPostgresArray(row.PlatformsStr),
+		MinAppVersion: row.MinAppVersion,
+		MaxAppVersion: row.MaxAppVersion,
+		CampaignID:    row.CampaignID,
+		Priority:      &row.Priority,
+		Content:       content,
+		IsActive:      row.IsActive,
+		EndsAt:        row.EndsAt,
+		ArchivedAt:    row.ArchivedAt,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}, nil
+}
+
+// selectBanners executes query built on top of selectBannersQuery and maps rows to entities
+func (r *Repo) selectBanners(ctx context.Context, query string, args ...any) ([]*entity.Banner, error) {
+	rows, err := r.DB.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var banners []*entity.Banner
 
 	for rows.Next() {
-		var row Row
+		var row bannerRow
 
 		if err = rows.StructScan(&row); err != nil {
 			return nil, err
 		}
 
-		// row.TagIDsStr have structure {1,2,...}
-		row.TagIDsInt, err = stringutils.FillIntSliceFromString(row.TagIDsStr[1 : len(row.TagIDsStr)-1])
+		banner, err := row.toEntity()
 		if err != nil {
 			return nil, err
 		}
 
-		content := entity.Content{
-			Title: row.Title,
-			Text:  row.Text,
-			Url:   row.Url,
-		}
+		banners = append(banners, banner)
+	}
 
-		banner := entity.Banner{
-			ID:        row.ID,
-			TagIDs:    row.TagIDsInt,
-			FeatureID: row.FeatureID,
-			Content:   content,
-			IsActive:  row.IsActive,
-			CreatedAt: row.CreatedAt,
-			UpdatedAt: row.UpdatedAt,
-		}
+	return banners, rows.Err()
+}
+
+// GetAllBanners returns not archived banners shown in the feature sorting by their features, banners of all features
+// are returned if featureID is 0
+func (r *Repo) GetAllBanners(ctx context.Context, featureID, offset, limit int) ([]*entity.Banner, error) {
+	query := fmt.Sprintf(`%v
+WHERE banner.archived_at IS NULL
+  AND ($1 = 0 OR EXISTS(SELECT 1 FROM banner_feature bf WHERE bf.banner_id = banner.id AND bf.feature_id = $1))
+GROUP BY c.content_id, banner.id
+ORDER BY feature_ids, banner.id`, selectBannersQuery)
 
-		banners = append(banners, &banner)
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
 	}
 
-	return banners, nil
+	return r.selectBanners(ctx, query, featureID)
 }
 
-func (r *Repo) GetBannerByID(ctx context.Context, id int) (*entity.Banner, error) {
-	query := fmt.Sprintf(`SELECT banner.id,
-       is_active,
-       title,
-       text,
-       url,
-       array_agg(bt.tag_id ORDER BY bt.tag_id) AS tag_ids
-FROM banner
-         JOIN public.content c ON c.content_id = banner.content_id
-         JOIN public.banner_tag bt ON banner.id = bt.banner_id
-WHERE banner.id = %v
-GROUP BY banner.id, c.content_id`,
-		id,
-	)
+// GetArchivedBanners returns archived banners, the latest archived first
+func (r *Repo) GetArchivedBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error) {
+	query := fmt.Sprintf(`%v
+WHERE banner.archived_at IS NOT NULL
+GROUP BY c.content_id, banner.id
+ORDER BY banner.archived_at DESC, banner.id`, selectBannersQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectBanners(ctx, query)
+}
 
-	rows, err := r.DB.QueryxContext(ctx, query)
+func (r *Repo) GetBannerByID(ctx context.Context, id int) (*entity.Banner, error) {
+	banners, err := r.selectBanners(ctx, fmt.Sprintf(`%v
+WHERE banner.id = $1
+GROUP BY banner.id, c.content_id`, selectBannersQuery), id)
 	if err != nil {
 		return nil, err
 	}
 
-	type Row struct {
-		ID        int    `db:"id"`
-		IsActive  bool   `db:"is_active"`
-		Title     string `db:"title"`
-		Text      string `db:"text"`
-		Url       string `db:"url"`
-		TagIDsStr string `db:"tag_ids"`
-		TagIDsInt []int
+	if len(banners) == 0 {
+		return nil, ErrNoSuchBanner
 	}
 
-	var row Row
-
-	if rows.Next() {
-		if err = rows.StructScan(&row); err != nil {
-			return nil, err
-		}
-
-		// row.TagIDsStr have structure {1,2,...}
-		row.TagIDsInt, err = stringutils.FillIntSliceFromString(row.TagIDsStr[1 : len(row.TagIDsStr)-1])
-		if err != nil {
-			return nil, err
-		}
-	}
+	return banners[0], nil
+}
 
-	content := entity.Content{
-		Title: row.Title,
-		Text:  row.Text,
-		Url:   row.Url,
+// GetShownBannerByID returns banner if it is not archived and not ended, so it may be shown to users,
+// nil is returned otherwise
+func (r *Repo) GetShownBannerByID(ctx context.Context, id int) (*entity.Banner, error) {
+	banners, err := r.selectBanners(ctx, fmt.Sprintf(`%v
+WHERE banner.id = $1
+  AND banner.archived_at IS NULL
+  AND (banner.ends_at IS NULL OR banner.ends_at > now())
+GROUP BY banner.id, c.content_id`, selectBannersQuery), id)
+	if err != nil || len(banners) == 0 {
+		return nil, err
 	}
 
-	return &entity.Banner{
-			Content:  content,
-			IsActive: row.IsActive,
-		},
-		nil
+	return banners[0], nil
 }
 
-func (r *Repo) GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int) (*entity.Banner, error) {
-	query := fmt.Sprintf(`SELECT banner.id,
-       is_active,
-       title,
-       text,
-       url,
-       array_agg(bt.tag_id ORDER BY bt.tag_id) AS tag_ids
-FROM banner
-         JOIN public.content c ON c.content_id = banner.content_id
-         JOIN public.banner_tag bt ON banner.id = bt.banner_id
-WHERE feature_id = %v
+// GetBannersByFeature returns not archived and not ended banners shown in the feature, banner may be shown in other
+// features too, ended banners are filtered here, so they are not shown before they are deactivated by sweep
+func (r *Repo) GetBannersByFeature(ctx context.Context, featureID int) ([]*entity.Banner, error) {
+	return r.selectBanners(ctx, fmt.Sprintf(`%v
+WHERE banner.archived_at IS NULL
+  AND (banner.ends_at IS NULL OR banner.ends_at > now())
+  AND EXISTS(SELECT 1 FROM banner_feature bf WHERE bf.banner_id = banner.id AND bf.feature_id = $1)
 GROUP BY banner.id, c.content_id
-`, featureID)
-
-	dbRows, err := r.DB.QueryxContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
+ORDER BY banner.id`, selectBannersQuery), featureID)
+}
 
-	type Row struct {
-		ID        int    `db:"id"`
-		IsActive  bool   `db:"is_active"`
-		Title     string `db:"title"`
-		Text      string `db:"text"`
-		Url       string `db:"url"`
-		TagIDsStr string `db:"tag_ids"`
-		TagIDsInt []int
-	}
+// GetBannerCandidates returns not archived and not ended banners of the feature with activity isActive which tags
+// are all among tagIDs, so banners which can not match requested tags are not fetched on lookup
+func (r *Repo) GetBannerCandidates(ctx context.Context, featureID int, tagIDs []int, isActive bool) ([]*entity.Banner, error) {
+	return r.selectBanners(ctx, fmt.Sprintf(`%v
+WHERE banner.archived_at IS NULL
+  AND (banner.ends_at IS NULL OR banner.ends_at > now())
+  AND banner.is_active = $3
+  AND EXISTS(SELECT 1 FROM banner_feature bf WHERE bf.banner_id = banner.id AND bf.feature_id = $1)
+  AND NOT EXISTS(SELECT 1 FROM banner_tag cbt WHERE cbt.banner_id = banner.id AND cbt.tag_id <> ALL ($2::integer[]))
+GROUP BY banner.id, c.content_id
+ORDER BY banner.id`, selectBannersQuery), featureID, stringutils.IntSliceToPostgresArray(tagIDs), isActive)
+}
 
-	var rows []*Row
+// GetBannersByFeatures returns not archived and not ended banners shown in any of the features
+func (r *Repo) GetBannersByFeatures(ctx context.Context, featureIDs []int) ([]*entity.Banner, error) {
+	return r.selectBanners(ctx, fmt.Sprintf(`%v
+WHERE banner.archived_at IS NULL
+  AND (banner.ends_at IS NULL OR banner.ends_at > now())
+  AND EXISTS(SELECT 1
+             FROM banner_feature bf
+             WHERE bf.banner_id = banner.id
+               AND bf.feature_id = ANY ($1::integer[]))
+GROUP BY banner.id, c.content_id
+ORDER BY banner.id`, selectBannersQuery), stringutils.IntSliceToPostgresArray(featureIDs))
+}
 
-	for dbRows.Next() {
-		var row Row
+// shownBannersCondition matches not archived and not ended banners pinned to user by ids $3 and active banners
+// which tags are all among $1 and which are not targeted to segments or targeted to some of $2
+const shownBannersCondition = `banner.archived_at IS NULL
+  AND (banner.ends_at IS NULL OR banner.ends_at > now())
+  AND (banner.id = ANY ($3::integer[])
+    OR (banner.is_active
+      AND NOT EXISTS(SELECT 1 FROM banner_tag sbt WHERE sbt.banner_id = banner.id AND sbt.tag_id <> ALL ($1::integer[]))
+      AND (NOT EXISTS(SELECT 1 FROM banner_segment sbs WHERE sbs.banner_id = banner.id)
+        OR EXISTS(SELECT 1 FROM banner_segment sbs WHERE sbs.banner_id = banner.id AND sbs.segment_id = ANY ($2::integer[])))))`
+
+// GetShownFeatureIDs returns sorted ids of enabled features having banners which may be shown to user with tags
+// among tagIDs and segments segmentIDs or pinned to user by ids pinnedIDs
+func (r *Repo) GetShownFeatureIDs(ctx context.Context, tagIDs, segmentIDs, pinnedIDs []int, offset, limit int) ([]int, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT bf.feature_id
+FROM banner
+         JOIN banner_feature bf ON bf.banner_id = banner.id
+         JOIN feature f ON f.id = bf.feature_id
+WHERE f.is_enabled
+  AND %v
+ORDER BY bf.feature_id`, shownBannersCondition)
 
-		if err = dbRows.StructScan(&row); err != nil {
-			return nil, err
-		}
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
 
-		// row.TagIDs have structure {1,2,...}
-		row.TagIDsInt, err = stringutils.FillIntSliceFromString(row.TagIDsStr[1 : len(row.TagIDsStr)-1])
-		if err != nil {
-			return nil, err
-		}
+	var featureIDs []int
 
-		rows = append(rows, &row)
+	err := r.DB.SelectContext(
+		ctx,
+		&featureIDs,
+		query,
+		stringutils.IntSliceToPostgresArray(tagIDs),
+		stringutils.IntSliceToPostgresArray(segmentIDs),
+		stringutils.IntSliceToPostgresArray(pinnedIDs),
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	// each row represents banner with banner.feature_id = featureID => find banner with banner.tag_ids = tagIDs
-	for _, row := range rows {
-		if sliceutils.Equals(row.TagIDsInt, tagIDs) { // here tagIDs gotta be sorted by asc, row.TagIDs already sorted
-			content := entity.Content{
-				Title: row.Title,
-				Text:  row.Text,
-				Url:   row.Url,
-			}
+	return featureIDs, nil
+}
 
-			return &entity.Banner{
-					Content:  content,
-					IsActive: row.IsActive,
-				},
-				nil
-		}
+// GetShownBanners returns banners of any of the features which may be shown to user with tags among tagIDs
+// and segments segmentIDs or pinned to user by ids pinnedIDs, banners keep all their features
+func (r *Repo) GetShownBanners(ctx context.Context, featureIDs, tagIDs, segmentIDs, pinnedIDs []int) ([]*entity.Banner, error) {
+	return r.selectBanners(ctx, fmt.Sprintf(`%v
+WHERE %v
+  AND EXISTS(SELECT 1
+             FROM banner_feature bf
+             WHERE bf.banner_id = banner.id
+               AND bf.feature_id = ANY ($4::integer[]))
+GROUP BY banner.id, c.content_id
+ORDER BY banner.id`, selectBannersQuery, shownBannersCondition),
+		stringutils.IntSliceToPostgresArray(tagIDs),
+		stringutils.IntSliceToPostgresArray(segmentIDs),
+		stringutils.IntSliceToPostgresArray(pinnedIDs),
+		stringutils.IntSliceToPostgresArray(featureIDs),
+	)
+}
+
+// GetBannersByFeatureAndTags returns all banners of the feature which tags are exactly tagIDs
+func (r *Repo) GetBannersByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int) ([]*entity.Banner, error) {
+	banners, err := r.GetBannersByFeature(ctx, featureID)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, nil
+	// each banner is shown in the feature => find banners with banner.tag_ids = tagIDs
+	return sliceutils.Filter(banners, func(banner *entity.Banner) bool {
+		return sliceutils.Equals(banner.TagIDs, tagIDs) // here tagIDs gotta be sorted by asc, banner.TagIDs already sorted
+	}), nil
 }
 
 func (r *Repo) CreateBanner(ctx context.Context, banner entity.Banner) (*entity.Banner, error) {
@@ -285,12 +379,22 @@ func (r *Repo) CreateBanner(ctx context.Context, banner entity.Banner) (*entity.
 	}
 
 	// then insert new banner into banner table
-	query := fmt.Sprintf(`INSERT INTO banner (feature_id,is_active, content_id) 
-VALUES (:feature_id, :is_active, %v) 
-RETURNING id, feature_id, is_active, created_at, updated_at`,
-		content.ID)
-
-	rows, err = tx.NamedQuery(query, &banner)
+	rows, err = tx.QueryxContext(
+		ctx,
+		`INSERT INTO banner (is_active, content_id, countries, regions, platforms, min_app_version, max_app_version, campaign_id, ends_at, priority) 
+VALUES ($1, $2, COALESCE($3::text[], '{}'), COALESCE($4::text[], '{}'), COALESCE($5::text[], '{}'), $6, $7, $8, $9, COALESCE($10, 0)) 
+RETURNING id, is_active, campaign_id, priority, ends_at, created_at, updated_at`,
+		banner.IsActive,
+		content.ID,
+		banner.Countries,
+		banner.Regions,
+		banner.Platforms,
+		banner.MinAppVersion,
+		banner.MaxAppVersion,
+		banner.CampaignID,
+		banner.EndsAt,
+		banner.Priority,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -306,6 +410,14 @@ RETURNING id, feature_id, is_active, created_at, updated_at`,
 		return nil, err
 	}
 
+	// banner is shown in each of its features
+	for _, feature := range banner.FeatureIDs {
+		_, err = tx.ExecContext(ctx, "INSERT INTO banner_feature (banner_id, feature_id) VALUES ($1, $2)", banner.ID, feature)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// for each tag id in entity.banner create new row (banner.ID, tag.ID) in BannerTag table
 	for _, tag := range banner.TagIDs {
 		_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO banner_tag (banner_id, tag_id) VALUES (%v, %v)", banner.ID, tag))
@@ -314,6 +426,19 @@ RETURNING id, feature_id, is_active, created_at, updated_at`,
 		}
 	}
 
+	// same for segments the banner is targeted to
+	for _, segment := range banner.SegmentIDs {
+		_, err = tx.ExecContext(ctx, "INSERT INTO banner_segment (banner_id, segment_id) VALUES ($1, $2)", banner.ID, segment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// active banner must be distinguishable from banners shown in the same features
+	if err = CheckConflicts(ctx, tx, []int{banner.ID}); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -325,19 +450,86 @@ RETURNING id, feature_id, is_active, created_at, updated_at`,
 
 func (r *Repo) UpdateBanner(ctx context.Context, id int, updateModel entity.Banner) error {
 	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
 
 	defer tx.Rollback()
 
-	// update some fields in banner table
-	setQuery := fmt.Sprintf("is_active = %v, updated_at = now()", updateModel.IsActive)
+	if err = UpdateBannerTx(ctx, tx, id, updateModel); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-	if updateModel.FeatureID != 0 {
-		setQuery += fmt.Sprintf(", feature_id = %v", updateModel.FeatureID)
+// UpdateBannerTx is UpdateBanner executed in tx, so banner can be updated together with changes of other entities
+func UpdateBannerTx(ctx context.Context, tx *sqlx.Tx, id int, updateModel entity.Banner) error {
+	// update some fields in banner table, updated banner is not archived anymore
+	setQuery := fmt.Sprintf("is_active = %v, updated_at = now(), archived_at = NULL", updateModel.IsActive)
+
+	var args []any
+
+	// nil slices mean geo targeting is not updated, empty slices remove it
+	if updateModel.Countries != nil {
+		args = append(args, updateModel.Countries)
+		setQuery += fmt.Sprintf(", countries = $%v::text[]", len(args))
+	}
+
+	if updateModel.Regions != nil {
+		args = append(args, updateModel.Regions)
+		setQuery += fmt.Sprintf(", regions = $%v::text[]", len(args))
+	}
+
+	// same for platforms, app versions are updated only if not empty, NoAppVersion removes them
+	if updateModel.Platforms != nil {
+		args = append(args, updateModel.Platforms)
+		setQuery += fmt.Sprintf(", platforms = $%v::text[]", len(args))
+	}
+
+	if updateModel.MinAppVersion == entity.NoAppVersion {
+		setQuery += ", min_app_version = ''"
+	} else if updateModel.MinAppVersion != "" {
+		args = append(args, updateModel.MinAppVersion)
+		setQuery += fmt.Sprintf(", min_app_version = $%v", len(args))
+	}
+
+	if updateModel.MaxAppVersion == entity.NoAppVersion {
+		setQuery += ", max_app_version = ''"
+	} else if updateModel.MaxAppVersion != "" {
+		args = append(args, updateModel.MaxAppVersion)
+		setQuery += fmt.Sprintf(", max_app_version = $%v", len(args))
+	}
+
+	// nil campaign id means campaign is not updated, 0 removes banner from its campaign
+	if updateModel.CampaignID != nil {
+		if *updateModel.CampaignID == 0 {
+			setQuery += ", campaign_id = NULL"
+		} else {
+			args = append(args, *updateModel.CampaignID)
+			setQuery += fmt.Sprintf(", campaign_id = $%v", len(args))
+		}
+	}
+
+	// nil end date means it is not updated, zero end date removes it
+	if updateModel.EndsAt != nil {
+		if updateModel.EndsAt.IsZero() {
+			setQuery += ", ends_at = NULL"
+		} else {
+			args = append(args, *updateModel.EndsAt)
+			setQuery += fmt.Sprintf(", ends_at = $%v", len(args))
+		}
+	}
+
+	if updateModel.Priority != nil {
+		args = append(args, *updateModel.Priority)
+		setQuery += fmt.Sprintf(", priority = $%v", len(args))
 	}
 
 	rows, err := tx.QueryxContext(
 		ctx,
 		fmt.Sprintf("UPDATE banner SET %v WHERE id = %v RETURNING content_id", setQuery, id),
+		args...,
 	)
 	if err != nil {
 		return err
@@ -360,16 +552,33 @@ func (r *Repo) UpdateBanner(ctx context.Context, id int, updateModel entity.Bann
 	}
 
 	// update content associated with this banner
-	setQuery = setQueryForUpdateModel(updateModel)
+	setQuery, args = setQueryForUpdateModel(updateModel)
 
 	// execute query only if updating something
 	if setQuery != "" {
-		_, err = tx.QueryxContext(ctx, fmt.Sprintf(`UPDATE content SET %v WHERE content_id = %v`, setQuery, contentIdStruct.ContentID))
+		args = append(args, contentIdStruct.ContentID)
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE content SET %v WHERE content_id = $%v`, setQuery, len(args)), args...)
 		if err != nil {
 			return err
 		}
 	}
 
+	// features are replaced the same way as tags below
+	if len(updateModel.FeatureIDs) != 0 {
+		_, err = tx.ExecContext(ctx, "DELETE FROM banner_feature WHERE banner_id = $1", id)
+		if err != nil {
+			return err
+		}
+
+		for _, feature := range updateModel.FeatureIDs {
+			_, err = tx.ExecContext(ctx, "INSERT INTO banner_feature (banner_id, feature_id) VALUES ($1, $2)", id, feature)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	/* update tag ids in banner_tag table:
 	to do this we need firstly delete all rows from banner_tag where banner_id = id,
 	then add new rows in this table of form (banner_id = id, tag_id = updateModel.tagIds[i])
@@ -392,22 +601,102 @@ func (r *Repo) UpdateBanner(ctx context.Context, id int, updateModel entity.Bann
 		}
 	}
 
-	return tx.Commit()
+	// nil segment ids mean segments are not updated, empty slice removes targeting by segments
+	if updateModel.SegmentIDs != nil {
+		_, err = tx.ExecContext(ctx, "DELETE FROM banner_segment WHERE banner_id = $1", id)
+		if err != nil {
+			return err
+		}
+
+		for _, segment := range updateModel.SegmentIDs {
+			_, err = tx.ExecContext(ctx, "INSERT INTO banner_segment (banner_id, segment_id) VALUES ($1, $2)", id, segment)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// banner is checked in its final state, so update of any of features, tags, targeting or activity is covered
+	return CheckConflicts(ctx, tx, []int{id})
 }
 
+// DeleteBanner deletes banner and returns its own columns, features, tags, segments and content are not returned
 func (r *Repo) DeleteBanner(ctx context.Context, id int) (*entity.Banner, error) {
-	row, err := r.DB.QueryxContext(ctx, "DELETE FROM banner WHERE id = $1 RETURNING *", id)
+	rows, err := r.DB.QueryxContext(ctx, `DELETE
+FROM banner
+WHERE id = $1
+RETURNING id, countries, regions, platforms, min_app_version, max_app_version, campaign_id, priority, is_active,
+    ends_at, archived_at, created_at, updated_at`, id)
 	if err != nil {
 		return nil, err
 	}
 
-	var banner entity.Banner
+	defer rows.Close()
 
-	if row.Next() {
-		if err = row.StructScan(&banner); err != nil {
+	var row bannerRow
+
+	if rows.Next() {
+		if err = rows.StructScan(&row); err != nil {
 			return nil, err
 		}
 	}
 
-	return &banner, nil
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return row.toEntity()
+}
+
+// SweepBanners deactivates active banners ended by now and archives inactive banners not updated since untouchedSince,
+// sweep with some banners changed is written to audit log, updated_at is kept, so banners are archived after
+// the period passes since admins touched them
+func (r *Repo) SweepBanners(ctx context.Context, now, untouchedSince time.Time) (*entity.BannerSweep, error) {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	var sweep entity.BannerSweep
+
+	err = tx.SelectContext(ctx, &sweep.DeactivatedIDs, "UPDATE banner SET is_active = false WHERE is_active AND ends_at <= $1 RETURNING id", now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.SelectContext(
+		ctx,
+		&sweep.ArchivedIDs,
+		"UPDATE banner SET archived_at = $1 WHERE archived_at IS NULL AND NOT is_active AND updated_at <= $2 RETURNING id",
+		now, untouchedSince,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sweep.DeactivatedIDs) == 0 && len(sweep.ArchivedIDs) == 0 {
+		return &sweep, nil
+	}
+
+	slices.Sort(sweep.DeactivatedIDs)
+	slices.Sort(sweep.ArchivedIDs)
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO audit_log (action, entity_type, entity_id, username, details)
+VALUES ($1, $2, 0, $3, jsonb_build_object('deactivated_ids', $4::integer[], 'archived_ids', $5::integer[]))`,
+		entity.AuditActionSweep, entity.AuditEntityBanner, entity.AuditUsernameSystem,
+		stringutils.IntSliceToPostgresArray(sweep.DeactivatedIDs), stringutils.IntSliceToPostgresArray(sweep.ArchivedIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &sweep, nil
 }
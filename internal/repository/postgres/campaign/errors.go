@@ -0,0 +1,8 @@
+package campaign
+
+import "errors"
+
+var (
+	ErrNoSuchCampaign     = errors.New("no such campaign")
+	ErrCampaignNameExists = errors.New("campaign with this name already exists")
+)
@@ -0,0 +1,318 @@
+package campaign
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+const selectCampaignsQuery = `SELECT campaign.id,
+       name,
+       starts_at,
+       ends_at,
+       impressions_budget,
+       impressions_count,
+       countries,
+       regions,
+       platforms,
+       min_app_version,
+       max_app_version,
+       created_at,
+       updated_at,
+       (SELECT count(*) FROM banner WHERE banner.campaign_id = campaign.id) AS banners_count
+FROM campaign`
+
+type campaignRow struct {
+	ID                int        `db:"id"`
+	Name              string     `db:"name"`
+	StartsAt          *time.Time `db:"starts_at"`
+	EndsAt            *time.Time `db:"ends_at"`
+	ImpressionsBudget int        `db:"impressions_budget"`
+	ImpressionsCount  int        `db:"impressions_count"`
+	CountriesStr      string     `db:"countries"`
+	RegionsStr        string     `db:"regions"`
+	PlatformsStr      string     `db:"platforms"`
+	MinAppVersion     string     `db:"min_app_version"`
+	MaxAppVersion     string     `db:"max_app_version"`
+	BannersCount      int        `db:"banners_count"`
+	CreatedAt         time.Time  `db:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+}
+
+func (row *campaignRow) toEntity() *entity.Campaign {
+	// arrays have structure {a,b,...}
+	return &entity.Campaign{
+		ID:                row.ID,
+		Name:              row.Name,
+		StartsAt:          row.StartsAt,
+		EndsAt:            row.EndsAt,
+		ImpressionsBudget: row.ImpressionsBudget,
+		ImpressionsCount:  row.ImpressionsCount,
+		Countries:         stringutils.FillStringSliceFromPostgresArray(row.CountriesStr),
+		Regions:           stringutils.FillStringSliceFromPostgresArray(row.RegionsStr),
+		Platforms:         stringutils.FillStringSliceFromPostgresArray(row.PlatformsStr),
+		MinAppVersion:     row.MinAppVersion,
+		MaxAppVersion:     row.MaxAppVersion,
+		BannersCount:      row.BannersCount,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}
+}
+
+func (r *Repo) selectCampaigns(ctx context.Context, query string, args ...any) ([]*entity.Campaign, error) {
+	var rows []*campaignRow
+
+	if err := r.DB.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	campaigns := make([]*entity.Campaign, 0, len(rows))
+
+	for _, row := range rows {
+		campaigns = append(campaigns, row.toEntity())
+	}
+
+	return campaigns, nil
+}
+
+func (r *Repo) GetAllCampaigns(ctx context.Context, offset, limit int) ([]*entity.Campaign, error) {
+	query := fmt.Sprintf(`%v ORDER BY id`, selectCampaignsQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectCampaigns(ctx, query)
+}
+
+func (r *Repo) GetCampaignsWithIDs(ctx context.Context, IDs []int) ([]*entity.Campaign, error) {
+	return r.selectCampaigns(ctx, fmt.Sprintf("%v WHERE id = ANY ($1::integer[]) ORDER BY id", selectCampaignsQuery),
+		stringutils.IntSliceToPostgresArray(IDs))
+}
+
+func (r *Repo) GetCampaignByID(ctx context.Context, id int) (*entity.Campaign, error) {
+	campaigns, err := r.selectCampaigns(ctx, fmt.Sprintf("%v WHERE id = $1", selectCampaignsQuery), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(campaigns) == 0 {
+		return nil, ErrNoSuchCampaign
+	}
+
+	return campaigns[0], nil
+}
+
+func (r *Repo) CreateCampaign(ctx context.Context, campaign entity.Campaign) (*entity.Campaign, error) {
+	row := r.DB.QueryRowxContext(
+		ctx,
+		`INSERT INTO campaign (name, starts_at, ends_at, impressions_budget, countries, regions, platforms, min_app_version, max_app_version)
+VALUES ($1, $2, $3, $4, COALESCE($5::text[], '{}'), COALESCE($6::text[], '{}'), COALESCE($7::text[], '{}'), $8, $9)
+RETURNING id, name, starts_at, ends_at, impressions_budget, impressions_count, countries, regions, platforms,
+    min_app_version, max_app_version, created_at, updated_at, 0 AS banners_count`,
+		campaign.Name, campaign.StartsAt, campaign.EndsAt, campaign.ImpressionsBudget,
+		campaign.Countries,
+		campaign.Regions,
+		campaign.Platforms,
+		campaign.MinAppVersion, campaign.MaxAppVersion,
+	)
+
+	var created campaignRow
+
+	if err := row.StructScan(&created); err != nil {
+		return nil, err
+	}
+
+	return created.toEntity(), nil
+}
+
+// UpdateCampaign updates fields of the campaign which are set in updateModel, nil targeting slices mean targeting
+// is not updated, empty slices remove it
+func (r *Repo) UpdateCampaign(ctx context.Context, id int, updateModel entity.Campaign) error {
+	setQuery := "updated_at = now()"
+
+	var args []any
+
+	if updateModel.Name != "" {
+		args = append(args, updateModel.Name)
+		setQuery += fmt.Sprintf(", name = $%v", len(args))
+	}
+
+	// zero dates remove schedule
+	for column, value := range map[string]*time.Time{
+		"starts_at": updateModel.StartsAt,
+		"ends_at":   updateModel.EndsAt,
+	} {
+		if value == nil {
+			continue
+		}
+
+		if value.IsZero() {
+			setQuery += fmt.Sprintf(", %v = NULL", column)
+			continue
+		}
+
+		args = append(args, *value)
+		setQuery += fmt.Sprintf(", %v = $%v", column, len(args))
+	}
+
+	// budget can not be removed once set, it can only be changed
+	if updateModel.ImpressionsBudget != 0 {
+		args = append(args, updateModel.ImpressionsBudget)
+		setQuery += fmt.Sprintf(", impressions_budget = $%v", len(args))
+	}
+
+	for column, values := range map[string][]string{
+		"countries": updateModel.Countries,
+		"regions":   updateModel.Regions,
+		"platforms": updateModel.Platforms,
+	} {
+		if values != nil {
+			args = append(args, values)
+			setQuery += fmt.Sprintf(", %v = $%v::text[]", column, len(args))
+		}
+	}
+
+	for column, value := range map[string]string{
+		"min_app_version": updateModel.MinAppVersion,
+		"max_app_version": updateModel.MaxAppVersion,
+	} {
+		if value != "" {
+			args = append(args, value)
+			setQuery += fmt.Sprintf(", %v = $%v", column, len(args))
+		}
+	}
+
+	args = append(args, id)
+
+	res, err := r.DB.ExecContext(ctx, fmt.Sprintf("UPDATE campaign SET %v WHERE id = $%v", setQuery, len(args)), args...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNoSuchCampaign
+	}
+
+	return nil
+}
+
+// DeleteCampaign deletes campaign, its banners stay and are shown on their own
+func (r *Repo) DeleteCampaign(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM campaign WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNoSuchCampaign
+	}
+
+	return nil
+}
+
+// ReserveImpressions reserves up to count impressions from budget of the campaign and returns how many of them
+// are reserved, campaign without budget reserves all of them, deleted campaign reserves none
+func (r *Repo) ReserveImpressions(ctx context.Context, id, count int) (int, error) {
+	var reserved int
+
+	// campaign row is locked, so concurrent reservations do not exceed budget
+	err := r.DB.GetContext(
+		ctx,
+		&reserved,
+		`WITH reservation AS (
+    SELECT id,
+           CASE
+               WHEN impressions_budget = 0 THEN $2
+               ELSE LEAST($2, impressions_budget - impressions_count)
+               END AS reserved
+    FROM campaign
+    WHERE id = $1
+      AND (impressions_budget = 0 OR impressions_count < impressions_budget)
+        FOR UPDATE)
+UPDATE campaign
+SET impressions_count = impressions_count + reservation.reserved
+FROM reservation
+WHERE campaign.id = reservation.id
+RETURNING reservation.reserved`,
+		id, count,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return reserved, nil
+}
+
+// ReleaseImpressions returns counts of reserved impressions to budgets of campaigns by their ids,
+// deleted campaigns are skipped
+func (r *Repo) ReleaseImpressions(ctx context.Context, counts map[int]int) error {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for id, count := range counts {
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE campaign SET impressions_count = GREATEST(impressions_count - $1, 0) WHERE id = $2",
+			count, id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CheckUniqueConstraints checks if name is not used by campaign other than the one with id, zero id is used for new campaign
+func (r *Repo) CheckUniqueConstraints(ctx context.Context, id int, name string) error {
+	var exists bool
+
+	err := r.DB.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM campaign WHERE name = $1 AND id <> $2)", name, id)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrCampaignNameExists
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package feature
+
+import "errors"
+
+var (
+	ErrNoSuchFeature = errors.New("no such feature")
+	ErrFeatureInUse  = errors.New("feature is used by banners")
+
+	ErrFeatureSlugExists = errors.New("feature with such slug already exists")
+)
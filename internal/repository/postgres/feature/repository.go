@@ -3,7 +3,14 @@ package feature
 import (
 	"avito-backend-trainee-2024/internal/domain/entity"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
 )
 
 type Repo struct {
@@ -16,18 +23,262 @@ func New(db *sqlx.DB) *Repo {
 	}
 }
 
+const selectFeaturesQuery = `SELECT feature.id,
+       name,
+       slug,
+       description,
+       team,
+       contact,
+       placement,
+       labels,
+       is_enabled,
+       created_at,
+       updated_at,
+       (SELECT count(*) FROM banner_feature bf WHERE bf.feature_id = feature.id) AS banners_count
+FROM feature`
+
+type featureRow struct {
+	ID           int       `db:"id"`
+	Name         string    `db:"name"`
+	Slug         string    `db:"slug"`
+	Description  string    `db:"description"`
+	Team         string    `db:"team"`
+	Contact      string    `db:"contact"`
+	Placement    string    `db:"placement"`
+	LabelsStr    string    `db:"labels"`
+	IsEnabled    bool      `db:"is_enabled"`
+	BannersCount int       `db:"banners_count"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+func (row *featureRow) toEntity() *entity.Feature {
+	return &entity.Feature{
+		ID:           row.ID,
+		Name:         row.Name,
+		Slug:         row.Slug,
+		Description:  row.Description,
+		Team:         row.Team,
+		Contact:      row.Contact,
+		Placement:    row.Placement,
+		Labels:       stringutils.FillStringSliceFromPostgresArray(row.LabelsStr), // array has structure {a,b,...}
+		IsEnabled:    row.IsEnabled,
+		BannersCount: row.BannersCount,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
+
+func (r *Repo) selectFeatures(ctx context.Context, query string, args ...any) ([]*entity.Feature, error) {
+	var rows []*featureRow
+
+	if err := r.DB.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	features := make([]*entity.Feature, 0, len(rows))
+
+	for _, row := range rows {
+		features = append(features, row.toEntity())
+	}
+
+	return features, nil
+}
+
+func (r *Repo) GetAllFeatures(ctx context.Context, offset, limit int) ([]*entity.Feature, error) {
+	query := fmt.Sprintf(`%v ORDER BY id`, selectFeaturesQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectFeatures(ctx, query)
+}
+
+func (r *Repo) GetFeaturesWithIDs(ctx context.Context, IDs []int) ([]*entity.Feature, error) {
+	return r.selectFeatures(ctx, fmt.Sprintf("%v WHERE id = ANY ($1::integer[]) ORDER BY id", selectFeaturesQuery),
+		stringutils.IntSliceToPostgresArray(IDs))
+}
+
 func (r *Repo) GetFeatureByID(ctx context.Context, id int) (*entity.Feature, error) {
-	row := r.DB.QueryRowxContext(ctx, "SELECT * FROM feature WHERE id = $1", id)
+	features, err := r.selectFeatures(ctx, fmt.Sprintf("%v WHERE id = $1", selectFeaturesQuery), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(features) == 0 {
+		return nil, ErrNoSuchFeature
+	}
+
+	return features[0], nil
+}
 
-	if err := row.Err(); err != nil {
+func (r *Repo) CreateFeature(ctx context.Context, feature entity.Feature) (*entity.Feature, error) {
+	row := r.DB.QueryRowxContext(
+		ctx,
+		`INSERT INTO feature (name, slug, description, team, contact, placement, labels)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::text[], '{}'))
+RETURNING id, name, slug, description, team, contact, placement, labels, is_enabled, created_at, updated_at`,
+		feature.Name, feature.Slug, feature.Description, feature.Team, feature.Contact, feature.Placement,
+		feature.Labels,
+	)
+
+	var created featureRow
+
+	if err := row.StructScan(&created); err != nil {
 		return nil, err
 	}
 
-	var feature entity.Feature
+	return created.toEntity(), nil
+}
+
+// UpdateFeature updates name, slug and metadata of the feature if they are not empty
+func (r *Repo) UpdateFeature(ctx context.Context, id int, updateModel entity.Feature) error {
+	setQuery := "updated_at = now()"
+
+	var args []any
+
+	if updateModel.Name != "" {
+		args = append(args, updateModel.Name)
+		setQuery += fmt.Sprintf(", name = $%v", len(args))
+	}
+
+	if updateModel.Slug != "" {
+		args = append(args, updateModel.Slug)
+		setQuery += fmt.Sprintf(", slug = $%v", len(args))
+	}
+
+	for column, value := range map[string]string{
+		"description": updateModel.Description,
+		"team":        updateModel.Team,
+		"contact":     updateModel.Contact,
+		"placement":   updateModel.Placement,
+	} {
+		if value != "" {
+			args = append(args, value)
+			setQuery += fmt.Sprintf(", %v = $%v", column, len(args))
+		}
+	}
+
+	// nil labels mean labels are not updated, empty slice removes them
+	if updateModel.Labels != nil {
+		args = append(args, updateModel.Labels)
+		setQuery += fmt.Sprintf(", labels = $%v::text[]", len(args))
+	}
+
+	args = append(args, id)
+
+	res, err := r.DB.ExecContext(ctx, fmt.Sprintf("UPDATE feature SET %v WHERE id = $%v", setQuery, len(args)), args...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNoSuchFeature
+	}
+
+	return nil
+}
+
+// DeleteFeature deletes feature if no banner references it, otherwise its banners would be deleted by cascade,
+// feature is locked before the check, so banner can not be attached to it until it is deleted
+func (r *Repo) DeleteFeature(ctx context.Context, id int) error {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var locked int
+
+	if err = tx.GetContext(ctx, &locked, "SELECT id FROM feature WHERE id = $1 FOR UPDATE", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoSuchFeature
+		}
+
+		return err
+	}
+
+	var inUse bool
+
+	err = tx.GetContext(ctx, &inUse, "SELECT EXISTS(SELECT 1 FROM banner_feature WHERE feature_id = $1)", id)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return ErrFeatureInUse
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM feature WHERE id = $1", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repo) GetFeatureIDBySlug(ctx context.Context, slug string) (int, error) {
+	var id int
+
+	if err := r.DB.GetContext(ctx, &id, "SELECT id FROM feature WHERE slug = $1", slug); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoSuchFeature
+		}
+
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CheckUniqueConstraints checks if slug is not used by feature other than the one with id, zero id is used for new feature
+func (r *Repo) CheckUniqueConstraints(ctx context.Context, id int, slug string) error {
+	var slugExists bool
+
+	err := r.DB.GetContext(ctx, &slugExists, "SELECT EXISTS(SELECT 1 FROM feature WHERE slug = $1 AND id <> $2)", slug, id)
+	if err != nil {
+		return err
+	}
+
+	if slugExists {
+		return ErrFeatureSlugExists
+	}
+
+	return nil
+}
+
+func (r *Repo) SetFeatureEnabled(ctx context.Context, id int, enabled bool) error {
+	res, err := r.DB.ExecContext(ctx, "UPDATE feature SET is_enabled = $1, updated_at = now() WHERE id = $2", enabled, id)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNoSuchFeature
+	}
+
+	return nil
+}
+
+func (r *Repo) GetDisabledFeatureIDs(ctx context.Context) ([]int, error) {
+	var IDs []int
 
-	if err := row.StructScan(&feature); err != nil {
+	if err := r.DB.SelectContext(ctx, &IDs, "SELECT id FROM feature WHERE NOT is_enabled"); err != nil {
 		return nil, err
 	}
 
-	return &feature, nil
+	return IDs, nil
 }
@@ -0,0 +1,123 @@
+package linkcheck
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+type linkCheckRow struct {
+	BannerID     int       `db:"banner_id"`
+	Url          string    `db:"url"`
+	StatusCode   int       `db:"status_code"`
+	Error        string    `db:"error"`
+	RedirectsRaw []byte    `db:"redirects"`
+	IsBroken     bool      `db:"is_broken"`
+	CheckedAt    time.Time `db:"checked_at"`
+}
+
+func (row *linkCheckRow) toEntity() (*entity.LinkCheck, error) {
+	var redirects []string
+
+	if err := json.Unmarshal(row.RedirectsRaw, &redirects); err != nil {
+		return nil, err
+	}
+
+	return &entity.LinkCheck{
+		BannerID:   row.BannerID,
+		Url:        row.Url,
+		StatusCode: row.StatusCode,
+		Error:      row.Error,
+		Redirects:  redirects,
+		IsBroken:   row.IsBroken,
+		CheckedAt:  row.CheckedAt,
+	}, nil
+}
+
+// GetBrokenLinks returns the last checks of active not archived banners which urls are broken, the latest checked
+// first, so banners deactivated since they were checked are not reported
+func (r *Repo) GetBrokenLinks(ctx context.Context, offset, limit int) ([]*entity.LinkCheck, error) {
+	query := `SELECT banner_id, url, status_code, error, redirects, is_broken, checked_at
+FROM banner_link_check
+         JOIN banner ON banner.id = banner_link_check.banner_id
+WHERE is_broken
+  AND banner.is_active
+  AND banner.archived_at IS NULL
+ORDER BY checked_at DESC, banner_id`
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	var rows []*linkCheckRow
+
+	if err := r.DB.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	checks := make([]*entity.LinkCheck, 0, len(rows))
+
+	for _, row := range rows {
+		check, err := row.toEntity()
+		if err != nil {
+			return nil, err
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// SaveLinkChecks replaces the last checks of banners with new ones, checks of deleted banners are skipped
+func (r *Repo) SaveLinkChecks(ctx context.Context, checks []*entity.LinkCheck) error {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, check := range checks {
+		redirects, err := json.Marshal(check.Redirects)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO banner_link_check (banner_id, url, status_code, error, redirects, is_broken, checked_at)
+SELECT $1::integer, $2::text, $3::integer, $4::text, $5::jsonb, $6::boolean, $7::timestamptz
+WHERE EXISTS(SELECT 1 FROM banner WHERE id = $1)
+ON CONFLICT (banner_id) DO UPDATE SET url         = excluded.url,
+                                      status_code = excluded.status_code,
+                                      error       = excluded.error,
+                                      redirects   = excluded.redirects,
+                                      is_broken   = excluded.is_broken,
+                                      checked_at  = excluded.checked_at`,
+			check.BannerID, check.Url, check.StatusCode, check.Error, redirects, check.IsBroken, check.CheckedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
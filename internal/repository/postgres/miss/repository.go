@@ -0,0 +1,147 @@
+package miss
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+type missRow struct {
+	FeatureID    int       `db:"feature_id"`
+	TagIDsStr    string    `db:"tag_ids"`
+	MissesCount  int       `db:"misses_count"`
+	LastMissedAt time.Time `db:"last_missed_at"`
+}
+
+// GetMisses returns misses of the feature with pending ones not persisted yet added, misses are sorted by their count,
+// misses of all features are returned if featureID is 0, pending misses gotta be of the feature and have tag ids
+// sorted by asc
+func (r *Repo) GetMisses(ctx context.Context, featureID int, pending []*entity.BannerMiss, offset, limit int) ([]*entity.BannerMiss, error) {
+	pendingFeatureIDs := make([]int, 0, len(pending))
+	pendingTagIDs := make([]string, 0, len(pending))
+	pendingCounts := make([]int, 0, len(pending))
+	pendingMissedAt := make([]time.Time, 0, len(pending))
+
+	for _, miss := range pending {
+		pendingFeatureIDs = append(pendingFeatureIDs, miss.FeatureID)
+		pendingTagIDs = append(pendingTagIDs, stringutils.IntSliceToPostgresArray(miss.TagIDs))
+		pendingCounts = append(pendingCounts, miss.MissesCount)
+		pendingMissedAt = append(pendingMissedAt, miss.LastMissedAt)
+	}
+
+	// pending misses are added to persisted ones before they are sorted and paginated
+	query := `WITH pending AS (SELECT p.feature_id, p.tag_ids::integer[] AS tag_ids, p.misses_count, p.last_missed_at
+                 FROM unnest($2::integer[], $3::text[], $4::bigint[], $5::timestamptz[])
+                          AS p(feature_id, tag_ids, misses_count, last_missed_at)),
+     persisted AS (SELECT feature_id, tag_ids, misses_count, last_missed_at
+                   FROM banner_miss
+                   WHERE $1 = 0 OR feature_id = $1)
+SELECT coalesce(m.feature_id, p.feature_id)                         AS feature_id,
+       coalesce(m.tag_ids, p.tag_ids)                               AS tag_ids,
+       coalesce(m.misses_count, 0) + coalesce(p.misses_count, 0)    AS misses_count,
+       greatest(m.last_missed_at, p.last_missed_at)                 AS last_missed_at
+FROM persisted m
+         FULL JOIN pending p ON p.feature_id = m.feature_id AND p.tag_ids = m.tag_ids
+ORDER BY misses_count DESC, feature_id, tag_ids`
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	var rows []*missRow
+
+	err := r.DB.SelectContext(
+		ctx,
+		&rows,
+		query,
+		featureID,
+		stringutils.IntSliceToPostgresArray(pendingFeatureIDs),
+		pendingTagIDs,
+		stringutils.IntSliceToPostgresArray(pendingCounts),
+		pendingMissedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	misses := make([]*entity.BannerMiss, 0, len(rows))
+
+	for _, row := range rows {
+		// arrays have structure {1,2,...}
+		tagIDs, err := stringutils.FillIntSliceFromPostgresArray(row.TagIDsStr)
+		if err != nil {
+			return nil, err
+		}
+
+		misses = append(misses, &entity.BannerMiss{
+			FeatureID:    row.FeatureID,
+			TagIDs:       tagIDs,
+			MissesCount:  row.MissesCount,
+			LastMissedAt: row.LastMissedAt,
+		})
+	}
+
+	return misses, nil
+}
+
+// SaveMisses adds counts of misses to persisted ones, misses of unknown features or tags are skipped,
+// tag ids of misses gotta be sorted by asc
+func (r *Repo) SaveMisses(ctx context.Context, misses []*entity.BannerMiss) error {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, miss := range misses {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO banner_miss (feature_id, tag_ids, misses_count, last_missed_at)
+SELECT $1::integer, $2::integer[], $3::bigint, $4::timestamptz
+WHERE EXISTS(SELECT 1 FROM feature WHERE id = $1::integer)
+  AND (SELECT count(*) FROM tag WHERE id = ANY ($2::integer[])) = cardinality($2::integer[])
+ON CONFLICT (feature_id, tag_ids) DO UPDATE SET misses_count   = banner_miss.misses_count + excluded.misses_count,
+                                                last_missed_at = greatest(banner_miss.last_missed_at, excluded.last_missed_at)`,
+			miss.FeatureID, stringutils.IntSliceToPostgresArray(miss.TagIDs), miss.MissesCount, miss.LastMissedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteMisses deletes misses last missed before lastMissedBefore and returns their count
+func (r *Repo) DeleteMisses(ctx context.Context, lastMissedBefore time.Time) (int, error) {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM banner_miss WHERE last_missed_at < $1", lastMissedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
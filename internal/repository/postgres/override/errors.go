@@ -0,0 +1,7 @@
+package override
+
+import "errors"
+
+var (
+	ErrNoSuchOverride = errors.New("no such override")
+)
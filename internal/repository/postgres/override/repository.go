@@ -0,0 +1,162 @@
+package override
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// featureIDsQuery selects features of overridden banner, banner is pinned to user in each of them
+const featureIDsQuery = `COALESCE((SELECT array_agg(bf.feature_id ORDER BY bf.feature_id)
+                 FROM banner_feature bf
+                 WHERE bf.banner_id = b.id), '{}') AS feature_ids`
+
+var selectOverridesQuery = fmt.Sprintf(`SELECT banner_override.id,
+       banner_id,
+       %v,
+       user_id,
+       expires_at,
+       banner_override.created_at
+FROM banner_override
+         JOIN banner b ON b.id = banner_override.banner_id`, featureIDsQuery)
+
+type overrideRow struct {
+	ID            int       `db:"id"`
+	BannerID      int       `db:"banner_id"`
+	FeatureIDsStr string    `db:"feature_ids"`
+	UserID        int       `db:"user_id"`
+	ExpiresAt     time.Time `db:"expires_at"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+func (row *overrideRow) toEntity() (*entity.Override, error) {
+	// array has structure {1,2,...}
+	featureIDs, err := stringutils.FillIntSliceFromPostgresArray(row.FeatureIDsStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Override{
+		ID:         row.ID,
+		BannerID:   row.BannerID,
+		FeatureIDs: featureIDs,
+		UserID:     row.UserID,
+		ExpiresAt:  row.ExpiresAt,
+		CreatedAt:  row.CreatedAt,
+	}, nil
+}
+
+func (r *Repo) selectOverrides(ctx context.Context, query string, args ...any) ([]*entity.Override, error) {
+	var rows []*overrideRow
+
+	if err := r.DB.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	overrides := make([]*entity.Override, 0, len(rows))
+
+	for _, row := range rows {
+		override, err := row.toEntity()
+		if err != nil {
+			return nil, err
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	return overrides, nil
+}
+
+// GetAllOverrides returns not expired overrides
+func (r *Repo) GetAllOverrides(ctx context.Context, offset, limit int) ([]*entity.Override, error) {
+	query := fmt.Sprintf(`%v WHERE expires_at > now() ORDER BY banner_override.id`, selectOverridesQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectOverrides(ctx, query)
+}
+
+// GetUserOverride returns the latest not expired override of user for feature or nil if there is no such override
+func (r *Repo) GetUserOverride(ctx context.Context, userID, featureID int) (*entity.Override, error) {
+	row := r.DB.QueryRowxContext(ctx, fmt.Sprintf(`%v
+WHERE user_id = $1
+  AND EXISTS(SELECT 1 FROM banner_feature bf WHERE bf.banner_id = b.id AND bf.feature_id = $2)
+  AND expires_at > now()
+ORDER BY banner_override.created_at DESC, banner_override.id DESC
+LIMIT 1`, selectOverridesQuery), userID, featureID)
+
+	var override overrideRow
+
+	if err := row.StructScan(&override); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return override.toEntity()
+}
+
+// GetUserOverrides returns not expired overrides of user, the latest go first
+func (r *Repo) GetUserOverrides(ctx context.Context, userID int) ([]*entity.Override, error) {
+	return r.selectOverrides(ctx, fmt.Sprintf(`%v
+WHERE user_id = $1 AND expires_at > now()
+ORDER BY banner_override.created_at DESC, banner_override.id DESC`, selectOverridesQuery), userID)
+}
+
+// CreateOverrides pins banner to each of users until expiresAt
+func (r *Repo) CreateOverrides(ctx context.Context, bannerID int, userIDs []int, expiresAt time.Time) ([]*entity.Override, error) {
+	return r.selectOverrides(ctx, `WITH inserted AS (
+    INSERT INTO banner_override (banner_id, user_id, expires_at)
+        SELECT $1, user_id, $3 FROM unnest($2::integer[]) AS user_id
+        RETURNING *)
+SELECT inserted.id,
+       banner_id,
+       `+featureIDsQuery+`,
+       user_id,
+       expires_at,
+       inserted.created_at
+FROM inserted
+         JOIN banner b ON b.id = inserted.banner_id
+ORDER BY inserted.id`, bannerID, stringutils.IntSliceToPostgresArray(userIDs), expiresAt)
+}
+
+func (r *Repo) DeleteOverride(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM banner_override WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNoSuchOverride
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package schedule
+
+import "errors"
+
+var (
+	ErrNoSuchScheduledEdit = errors.New("no such scheduled edit")
+)
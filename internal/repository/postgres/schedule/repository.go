@@ -0,0 +1,317 @@
+package schedule
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	bannerrepo "avito-backend-trainee-2024/internal/repository/postgres/banner"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// bannerPatch is stored shape of banner update model, so renaming entity fields does not break stored edits
+type bannerPatch struct {
+	TagIDs        []int      `json:"tag_ids,omitempty"`
+	FeatureIDs    []int      `json:"feature_ids,omitempty"`
+	SegmentIDs    []int      `json:"segment_ids,omitempty"`
+	Countries     []string   `json:"countries"`
+	Regions       []string   `json:"regions"`
+	Platforms     []string   `json:"platforms"`
+	MinAppVersion string     `json:"min_app_version,omitempty"`
+	MaxAppVersion string     `json:"max_app_version,omitempty"`
+	CampaignID    *int       `json:"campaign_id,omitempty"`
+	Priority      *int       `json:"priority,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	Text          string     `json:"text,omitempty"`
+	Url           string     `json:"url,omitempty"`
+	IsActive      *bool      `json:"is_active,omitempty"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+}
+
+func toPatch(edit entity.ScheduledEdit) bannerPatch {
+	banner := edit.Patch
+
+	return bannerPatch{
+		TagIDs:        banner.TagIDs,
+		FeatureIDs:    banner.FeatureIDs,
+		SegmentIDs:    banner.SegmentIDs,
+		Countries:     banner.Countries,
+		Regions:       banner.Regions,
+		Platforms:     banner.Platforms,
+		MinAppVersion: banner.MinAppVersion,
+		MaxAppVersion: banner.MaxAppVersion,
+		CampaignID:    banner.CampaignID,
+		Priority:      banner.Priority,
+		Title:         banner.Content.Title,
+		Text:          banner.Content.Text,
+		Url:           banner.Content.Url,
+		IsActive:      edit.IsActive,
+		EndsAt:        banner.EndsAt,
+	}
+}
+
+func (patch *bannerPatch) toEntity() entity.Banner {
+	return entity.Banner{
+		TagIDs:        patch.TagIDs,
+		FeatureIDs:    patch.FeatureIDs,
+		SegmentIDs:    patch.SegmentIDs,
+		Countries:     patch.Countries,
+		Regions:       patch.Regions,
+		Platforms:     patch.Platforms,
+		MinAppVersion: patch.MinAppVersion,
+		MaxAppVersion: patch.MaxAppVersion,
+		CampaignID:    patch.CampaignID,
+		Priority:      patch.Priority,
+		Content: entity.Content{
+			Title: patch.Title,
+			Text:  patch.Text,
+			Url:   patch.Url,
+		},
+		EndsAt: patch.EndsAt,
+	}
+}
+
+const selectScheduledEditsQuery = `SELECT id,
+       banner_id,
+       patch,
+       apply_at,
+       status,
+       attempts,
+       last_error,
+       applied_at,
+       created_at
+FROM banner_scheduled_edit`
+
+type scheduledEditRow struct {
+	ID        int        `db:"id"`
+	BannerID  int        `db:"banner_id"`
+	Patch     []byte     `db:"patch"`
+	ApplyAt   time.Time  `db:"apply_at"`
+	Status    string     `db:"status"`
+	Attempts  int        `db:"attempts"`
+	LastError string     `db:"last_error"`
+	AppliedAt *time.Time `db:"applied_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func (row *scheduledEditRow) toEntity() (*entity.ScheduledEdit, error) {
+	var patch bannerPatch
+
+	if err := json.Unmarshal(row.Patch, &patch); err != nil {
+		return nil, err
+	}
+
+	return &entity.ScheduledEdit{
+		ID:        row.ID,
+		BannerID:  row.BannerID,
+		Patch:     patch.toEntity(),
+		IsActive:  patch.IsActive,
+		ApplyAt:   row.ApplyAt,
+		Status:    row.Status,
+		Attempts:  row.Attempts,
+		LastError: row.LastError,
+		AppliedAt: row.AppliedAt,
+		CreatedAt: row.CreatedAt,
+	}, nil
+}
+
+func (r *Repo) selectScheduledEdits(ctx context.Context, query string, args ...any) ([]*entity.ScheduledEdit, error) {
+	var rows []*scheduledEditRow
+
+	if err := r.DB.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	edits := make([]*entity.ScheduledEdit, 0, len(rows))
+
+	for _, row := range rows {
+		edit, err := row.toEntity()
+		if err != nil {
+			return nil, err
+		}
+
+		edits = append(edits, edit)
+	}
+
+	return edits, nil
+}
+
+// GetScheduledEdits returns not applied edits of the banner in the order they are applied, edits of all banners
+// are returned if bannerID is 0
+func (r *Repo) GetScheduledEdits(ctx context.Context, bannerID, offset, limit int) ([]*entity.ScheduledEdit, error) {
+	query := fmt.Sprintf(`%v WHERE status <> '%v' AND ($1 = 0 OR banner_id = $1) ORDER BY apply_at, id`,
+		selectScheduledEditsQuery, entity.ScheduledEditApplied)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectScheduledEdits(ctx, query, bannerID)
+}
+
+// GetDueEdits returns pending edits which time to be applied has come in the order they are applied
+func (r *Repo) GetDueEdits(ctx context.Context, now time.Time) ([]*entity.ScheduledEdit, error) {
+	return r.selectScheduledEdits(
+		ctx,
+		fmt.Sprintf(`%v WHERE status = '%v' AND apply_at <= $1 ORDER BY apply_at, id`, selectScheduledEditsQuery, entity.ScheduledEditPending),
+		now,
+	)
+}
+
+func (r *Repo) CreateScheduledEdit(ctx context.Context, edit entity.ScheduledEdit) (*entity.ScheduledEdit, error) {
+	patch, err := json.Marshal(toPatch(edit))
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.DB.QueryRowxContext(
+		ctx,
+		`INSERT INTO banner_scheduled_edit (banner_id, patch, apply_at)
+VALUES ($1, $2, $3)
+RETURNING id, banner_id, patch, apply_at, status, attempts, last_error, applied_at, created_at`,
+		edit.BannerID, patch, edit.ApplyAt,
+	)
+
+	var created scheduledEditRow
+
+	if err = row.StructScan(&created); err != nil {
+		return nil, err
+	}
+
+	return created.toEntity()
+}
+
+// ApplyEdit applies pending edit to its banner and marks it as applied in one transaction with the edit locked,
+// so edit is applied once however many instances apply edits and cancel of the edit waits until it is applied.
+// Edit locked by other instance, already applied or canceled is skipped and nil is returned. If validate or
+// update of the banner fails, the edit is kept pending with failed attempt counted and marked as failed once
+// maxAttempts are reached, returned edit holds the outcome
+func (r *Repo) ApplyEdit(ctx context.Context, id int, validate func(ctx context.Context, bannerID int, updateModel entity.Banner) error,
+	maxAttempts int) (*entity.ScheduledEdit, error) {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	var row scheduledEditRow
+
+	err = tx.GetContext(
+		ctx,
+		&row,
+		fmt.Sprintf(`%v WHERE id = $1 AND status = $2 FOR UPDATE SKIP LOCKED`, selectScheduledEditsQuery),
+		id, entity.ScheduledEditPending,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	edit, err := row.toEntity()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "SAVEPOINT apply_edit"); err != nil {
+		return nil, err
+	}
+
+	if applyErr := applyEdit(ctx, tx, edit, validate); applyErr != nil {
+		// changes of the banner made before the failure are dropped, the edit is still locked
+		if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT apply_edit"); err != nil {
+			return nil, err
+		}
+
+		err = tx.QueryRowxContext(
+			ctx,
+			`UPDATE banner_scheduled_edit
+SET attempts   = attempts + 1,
+    last_error = $1,
+    status     = CASE WHEN attempts + 1 >= $2 THEN $3 ELSE status END
+WHERE id = $4
+RETURNING attempts, last_error, status`,
+			applyErr.Error(), maxAttempts, entity.ScheduledEditFailed, id,
+		).Scan(&edit.Attempts, &edit.LastError, &edit.Status)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err = tx.QueryRowxContext(
+			ctx,
+			"UPDATE banner_scheduled_edit SET status = $1, applied_at = now() WHERE id = $2 RETURNING status, applied_at",
+			entity.ScheduledEditApplied, id,
+		).Scan(&edit.Status, &edit.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return edit, nil
+}
+
+// applyEdit validates patch of the edit and updates the banner with it in tx, banner keeps its activity
+// unless the edit sets it
+func applyEdit(ctx context.Context, tx *sqlx.Tx, edit *entity.ScheduledEdit,
+	validate func(ctx context.Context, bannerID int, updateModel entity.Banner) error) error {
+	updateModel := edit.Patch
+
+	if edit.IsActive != nil {
+		updateModel.IsActive = *edit.IsActive
+	} else if err := tx.GetContext(
+		ctx, &updateModel.IsActive, "SELECT is_active FROM banner WHERE id = $1 FOR UPDATE", edit.BannerID,
+	); err != nil {
+		return err
+	}
+
+	if err := validate(ctx, edit.BannerID, updateModel); err != nil {
+		return err
+	}
+
+	return bannerrepo.UpdateBannerTx(ctx, tx, edit.BannerID, updateModel)
+}
+
+// DeleteScheduledEdit cancels edit which is not applied yet
+func (r *Repo) DeleteScheduledEdit(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(
+		ctx,
+		"DELETE FROM banner_scheduled_edit WHERE id = $1 AND status <> $2",
+		id, entity.ScheduledEditApplied,
+	)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNoSuchScheduledEdit
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package segment
+
+import "errors"
+
+var (
+	ErrNoSuchSegment     = errors.New("no such segment")
+	ErrSegmentNameExists = errors.New("segment with this name already exists")
+	ErrSegmentInUse      = errors.New("segment is used by banners")
+)
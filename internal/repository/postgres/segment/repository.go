@@ -0,0 +1,183 @@
+package segment
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+const selectSegmentsQuery = `SELECT segment.id,
+       name,
+       created_at,
+       updated_at,
+       (SELECT count(*) FROM segment_user su WHERE su.segment_id = segment.id) AS users_count
+FROM segment`
+
+func (r *Repo) selectSegments(ctx context.Context, query string, args ...any) ([]*entity.Segment, error) {
+	rows, err := r.DB.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var segments []*entity.Segment
+
+	for rows.Next() {
+		var segment entity.Segment
+
+		if err = rows.StructScan(&segment); err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, &segment)
+	}
+
+	return segments, rows.Err()
+}
+
+func (r *Repo) GetAllSegments(ctx context.Context, offset, limit int) ([]*entity.Segment, error) {
+	query := fmt.Sprintf(`%v ORDER BY id`, selectSegmentsQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectSegments(ctx, query)
+}
+
+func (r *Repo) GetSegmentsWithIDs(ctx context.Context, IDs []int) ([]*entity.Segment, error) {
+	return r.selectSegments(
+		ctx,
+		fmt.Sprintf(`%v WHERE id = ANY($1::integer[]) ORDER BY id`, selectSegmentsQuery),
+		stringutils.IntSliceToPostgresArray(IDs),
+	)
+}
+
+// GetUserSegmentIDs returns sorted ids of segments user is member of
+func (r *Repo) GetUserSegmentIDs(ctx context.Context, userID int) ([]int, error) {
+	var segmentIDs []int
+
+	err := r.DB.SelectContext(ctx, &segmentIDs, "SELECT segment_id FROM segment_user WHERE user_id = $1 ORDER BY segment_id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return segmentIDs, nil
+}
+
+// CreateSegment creates new segment with users which ids are userIDs
+func (r *Repo) CreateSegment(ctx context.Context, segment entity.Segment, userIDs []int) (*entity.Segment, error) {
+	// execute in transaction
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	row := tx.QueryRowxContext(ctx, "INSERT INTO segment (name) VALUES ($1) RETURNING id, name, created_at, updated_at", segment.Name)
+
+	var created entity.Segment
+
+	if err = row.StructScan(&created); err != nil {
+		return nil, err
+	}
+
+	// all users are inserted with a single statement, so large segments do not require thousands of round trips
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO segment_user (segment_id, user_id)
+SELECT $1, user_id FROM unnest($2::integer[]) AS user_id
+ON CONFLICT DO NOTHING`,
+		created.ID,
+		stringutils.IntSliceToPostgresArray(userIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	usersCount, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	created.UsersCount = int(usersCount)
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// DeleteSegment deletes segment if no banner is targeted to it, otherwise such banners would be shown to everyone,
+// segment is locked while it is checked, so banner can not be targeted to it until it is deleted
+func (r *Repo) DeleteSegment(ctx context.Context, id int) error {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var locked int
+
+	if err = tx.GetContext(ctx, &locked, "SELECT id FROM segment WHERE id = $1 FOR UPDATE", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoSuchSegment
+		}
+
+		return err
+	}
+
+	var inUse bool
+
+	err = tx.GetContext(ctx, &inUse, "SELECT EXISTS(SELECT 1 FROM banner_segment WHERE segment_id = $1)", id)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return ErrSegmentInUse
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM segment WHERE id = $1", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repo) CheckUniqueConstraints(ctx context.Context, name string) error {
+	var exists bool
+
+	err := r.DB.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM segment WHERE name = $1)", name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrSegmentNameExists
+	}
+
+	return nil
+}
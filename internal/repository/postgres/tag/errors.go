@@ -0,0 +1,11 @@
+package tag
+
+import "errors"
+
+var (
+	ErrNoSuchTag       = errors.New("no such tag")
+	ErrTagSlugExists   = errors.New("tag with such slug already exists")
+	ErrTagInUse        = errors.New("tag is used by banners")
+	ErrTagIsOnlyBanner = errors.New("tag is the only tag of some banners")
+	ErrTagHasChildren  = errors.New("tag has child or alias tags")
+)
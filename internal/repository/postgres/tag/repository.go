@@ -2,10 +2,16 @@ package tag
 
 import (
 	"avito-backend-trainee-2024/internal/domain/entity"
+	bannerrepo "avito-backend-trainee-2024/internal/repository/postgres/banner"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"math"
 	"strconv"
+
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
 )
 
 type Repo struct {
@@ -48,3 +54,381 @@ func (r *Repo) GetTagsWithIDs(ctx context.Context, IDs []int) ([]*entity.Tag, er
 
 	return tags, nil
 }
+
+const selectTagsQuery = `SELECT tag.id,
+       name,
+       slug,
+       parent_id,
+       alias_of_id,
+       created_at,
+       updated_at,
+       (SELECT count(*) FROM banner_tag bt WHERE bt.tag_id = tag.id) AS banners_count
+FROM tag`
+
+// GetAllTags returns tags which names contain name, all tags are returned if name is empty,
+// wildcards in name are matched literally
+func (r *Repo) GetAllTags(ctx context.Context, name string, offset, limit int) ([]*entity.Tag, error) {
+	query := fmt.Sprintf(`%v WHERE name ILIKE '%%' || $1 || '%%' ESCAPE '\' ORDER BY id`, selectTagsQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	var tags []*entity.Tag
+
+	if err := r.DB.SelectContext(ctx, &tags, query, stringutils.EscapePostgresLikePattern(name)); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *Repo) GetTagByID(ctx context.Context, id int) (*entity.Tag, error) {
+	row := r.DB.QueryRowxContext(ctx, fmt.Sprintf("%v WHERE id = $1", selectTagsQuery), id)
+
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var tag entity.Tag
+
+	if err := row.StructScan(&tag); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoSuchTag
+		}
+
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (r *Repo) CreateTag(ctx context.Context, tag entity.Tag) (*entity.Tag, error) {
+	row := r.DB.QueryRowxContext(
+		ctx,
+		"INSERT INTO tag (name, slug, parent_id) VALUES ($1, $2, $3) RETURNING id, name, slug, parent_id, alias_of_id, created_at, updated_at",
+		tag.Name, tag.Slug, tag.ParentID,
+	)
+
+	var created entity.Tag
+
+	if err := row.StructScan(&created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// UpdateTag updates name and slug of the tag if they are not empty and its parent if it is not nil, zero parent id makes tag a root one
+func (r *Repo) UpdateTag(ctx context.Context, id int, updateModel entity.Tag) error {
+	setQuery := "updated_at = now()"
+
+	var args []any
+
+	if updateModel.Name != "" {
+		args = append(args, updateModel.Name)
+		setQuery += fmt.Sprintf(", name = $%v", len(args))
+	}
+
+	if updateModel.Slug != "" {
+		args = append(args, updateModel.Slug)
+		setQuery += fmt.Sprintf(", slug = $%v", len(args))
+	}
+
+	if updateModel.ParentID != nil {
+		if *updateModel.ParentID == 0 {
+			setQuery += ", parent_id = NULL"
+		} else {
+			args = append(args, *updateModel.ParentID)
+			setQuery += fmt.Sprintf(", parent_id = $%v", len(args))
+		}
+	}
+
+	args = append(args, id)
+
+	res, err := r.DB.ExecContext(ctx, fmt.Sprintf("UPDATE tag SET %v WHERE id = $%v", setQuery, len(args)), args...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNoSuchTag
+	}
+
+	return nil
+}
+
+// DeleteTag deletes tag, if tag is used by banners it is either refused or tag is detached from banners,
+// tag can not be detached from banner if it is the only tag of the banner or banner would conflict with another one
+// without it, tag having children or aliases is never deleted
+func (r *Repo) DeleteTag(ctx context.Context, id int, detach bool) error {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var hasChildren bool
+
+	err = tx.GetContext(ctx, &hasChildren, "SELECT EXISTS(SELECT 1 FROM tag WHERE parent_id = $1 OR alias_of_id = $1)", id)
+	if err != nil {
+		return err
+	}
+
+	if hasChildren {
+		return ErrTagHasChildren
+	}
+
+	if !detach {
+		var inUse bool
+
+		if err = tx.GetContext(ctx, &inUse, "SELECT EXISTS(SELECT 1 FROM banner_tag WHERE tag_id = $1)", id); err != nil {
+			return err
+		}
+
+		if inUse {
+			return ErrTagInUse
+		}
+	} else {
+		var isOnlyTag bool
+
+		err = tx.GetContext(ctx, &isOnlyTag, `SELECT EXISTS(SELECT 1
+              FROM banner_tag bt
+              WHERE bt.tag_id = $1
+                AND NOT EXISTS(SELECT 1 FROM banner_tag other WHERE other.banner_id = bt.banner_id AND other.tag_id <> $1))`, id)
+		if err != nil {
+			return err
+		}
+
+		if isOnlyTag {
+			return ErrTagIsOnlyBanner
+		}
+
+		var bannerIDs []int
+
+		if err = tx.SelectContext(ctx, &bannerIDs, "DELETE FROM banner_tag WHERE tag_id = $1 RETURNING banner_id", id); err != nil {
+			return err
+		}
+
+		if err = bannerrepo.CheckConflicts(ctx, tx, bannerIDs); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM tag WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNoSuchTag
+	}
+
+	return tx.Commit()
+}
+
+// maxTagDepth limits walking up the tags hierarchy, so broken hierarchy can not loop query forever
+const maxTagDepth = 32
+
+// GetTagAncestors returns for each of tags chain of tags from it to the root of hierarchy, the tag itself goes first,
+// alias tag is replaced by the tag it was merged into
+func (r *Repo) GetTagAncestors(ctx context.Context, IDs []int) (map[int][]int, error) {
+	rows, err := r.DB.QueryxContext(
+		ctx,
+		`WITH RECURSIVE chain AS (SELECT t.id AS tag_id, c.id, c.parent_id, 0 AS depth
+                       FROM tag t
+                                JOIN tag c ON c.id = coalesce(t.alias_of_id, t.id)
+                       WHERE t.id = ANY ($1::integer[])
+                       UNION ALL
+                       SELECT c.tag_id, t.id, t.parent_id, c.depth + 1
+                       FROM chain c
+                                JOIN tag t ON t.id = c.parent_id
+                       WHERE c.depth < $2)
+SELECT tag_id, id
+FROM chain
+ORDER BY tag_id, depth`,
+		stringutils.IntSliceToPostgresArray(IDs), maxTagDepth,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ancestors := make(map[int][]int, len(IDs))
+
+	for rows.Next() {
+		var tagID, ancestorID int
+
+		if err = rows.Scan(&tagID, &ancestorID); err != nil {
+			return nil, err
+		}
+
+		ancestors[tagID] = append(ancestors[tagID], ancestorID)
+	}
+
+	return ancestors, rows.Err()
+}
+
+// MergeTags moves banners of source tag to target one and removes source tag or leaves it as alias of target one,
+// banners tagged with both tags keep only target tag, children and aliases of source tag are moved to target one,
+// merge leaving moved banners conflicting with other ones is rejected with *entity.BannerConflictError,
+// merge is recorded to audit log by username in the same transaction
+func (r *Repo) MergeTags(ctx context.Context, sourceID, targetID int, keepAlias bool, username string) (*entity.TagMerge, error) {
+	tx, err := r.DB.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	// lock both tags, so they are not changed while merging
+	var lockedIDs []int
+
+	err = tx.SelectContext(ctx, &lockedIDs, "SELECT id FROM tag WHERE id IN ($1, $2) AND alias_of_id IS NULL FOR UPDATE", sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lockedIDs) != 2 {
+		return nil, ErrNoSuchTag
+	}
+
+	merge := &entity.TagMerge{
+		SourceID:  sourceID,
+		TargetID:  targetID,
+		KeepAlias: keepAlias,
+	}
+
+	// banners of source tag are checked for conflicts once they are retagged
+	var bannerIDs []int
+
+	if err = tx.SelectContext(ctx, &bannerIDs, "SELECT banner_id FROM banner_tag WHERE tag_id = $1", sourceID); err != nil {
+		return nil, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		"DELETE FROM banner_tag WHERE tag_id = $1 AND banner_id IN (SELECT banner_id FROM banner_tag WHERE tag_id = $2)",
+		sourceID, targetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = tx.ExecContext(ctx, "UPDATE banner_tag SET tag_id = $1 WHERE tag_id = $2", targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	merge.RemovedDuplicates = int(removed)
+	merge.MovedBanners = int(moved)
+
+	if err = bannerrepo.CheckConflicts(ctx, tx, bannerIDs); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE tag SET parent_id = $1, updated_at = now() WHERE parent_id = $2", targetID, sourceID); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE tag SET alias_of_id = $1, updated_at = now() WHERE alias_of_id = $2", targetID, sourceID); err != nil {
+		return nil, err
+	}
+
+	if keepAlias {
+		_, err = tx.ExecContext(ctx, "UPDATE tag SET alias_of_id = $1, parent_id = NULL, updated_at = now() WHERE id = $2", targetID, sourceID)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM tag WHERE id = $1", sourceID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO audit_log (action, entity_type, entity_id, username, details)
+VALUES ($1, $2, $3, $4, jsonb_build_object('target_id', $5::integer, 'keep_alias', $6::boolean,
+                                          'moved_banners', $7::integer, 'removed_duplicates', $8::integer))`,
+		entity.AuditActionMerge, entity.AuditEntityTag, sourceID, username,
+		targetID, keepAlias, merge.MovedBanners, merge.RemovedDuplicates,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+// GetTagIDsBySlugs returns ids of tags with slugs in the same order, ErrNoSuchTag is returned if some of slugs is unknown
+func (r *Repo) GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error) {
+	var tags []*entity.Tag
+
+	err := r.DB.SelectContext(ctx, &tags, "SELECT id, slug FROM tag WHERE slug = ANY ($1::text[])", slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	IDsBySlug := make(map[string]int, len(tags))
+
+	for _, tag := range tags {
+		IDsBySlug[tag.Slug] = tag.ID
+	}
+
+	IDs := make([]int, 0, len(slugs))
+
+	for _, slug := range slugs {
+		id, exists := IDsBySlug[slug]
+		if !exists {
+			return nil, ErrNoSuchTag
+		}
+
+		IDs = append(IDs, id)
+	}
+
+	return IDs, nil
+}
+
+// CheckUniqueConstraints checks if slug is not used by tag other than the one with id, zero id is used for new tag
+func (r *Repo) CheckUniqueConstraints(ctx context.Context, id int, slug string) error {
+	var slugExists bool
+
+	err := r.DB.GetContext(ctx, &slugExists, "SELECT EXISTS(SELECT 1 FROM tag WHERE slug = $1 AND id <> $2)", slug, id)
+	if err != nil {
+		return err
+	}
+
+	if slugExists {
+		return ErrTagSlugExists
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package template
+
+import "errors"
+
+var (
+	ErrNoSuchTemplate     = errors.New("no such template")
+	ErrTemplateNameExists = errors.New("template with this name already exists")
+)
@@ -0,0 +1,176 @@
+package template
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"math"
+	"time"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+const selectTemplatesQuery = `SELECT id, name, title, text, url, created_at, updated_at FROM banner_template`
+
+type templateRow struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Title     string    `db:"title"`
+	Text      string    `db:"text"`
+	Url       string    `db:"url"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (row *templateRow) toEntity() *entity.BannerTemplate {
+	return &entity.BannerTemplate{
+		ID:   row.ID,
+		Name: row.Name,
+		Content: entity.Content{
+			Title: row.Title,
+			Text:  row.Text,
+			Url:   row.Url,
+		},
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+func (r *Repo) selectTemplates(ctx context.Context, query string, args ...any) ([]*entity.BannerTemplate, error) {
+	var rows []*templateRow
+
+	if err := r.DB.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	templates := make([]*entity.BannerTemplate, 0, len(rows))
+
+	for _, row := range rows {
+		templates = append(templates, row.toEntity())
+	}
+
+	return templates, nil
+}
+
+func (r *Repo) GetAllTemplates(ctx context.Context, offset, limit int) ([]*entity.BannerTemplate, error) {
+	query := fmt.Sprintf(`%v ORDER BY id`, selectTemplatesQuery)
+
+	if limit == math.MaxInt64 {
+		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
+	} else {
+		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
+	}
+
+	return r.selectTemplates(ctx, query)
+}
+
+func (r *Repo) GetTemplateByID(ctx context.Context, id int) (*entity.BannerTemplate, error) {
+	templates, err := r.selectTemplates(ctx, fmt.Sprintf("%v WHERE id = $1", selectTemplatesQuery), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(templates) == 0 {
+		return nil, ErrNoSuchTemplate
+	}
+
+	return templates[0], nil
+}
+
+func (r *Repo) CreateTemplate(ctx context.Context, template entity.BannerTemplate) (*entity.BannerTemplate, error) {
+	row := r.DB.QueryRowxContext(
+		ctx,
+		`INSERT INTO banner_template (name, title, text, url) VALUES ($1, $2, $3, $4)
+RETURNING id, name, title, text, url, created_at, updated_at`,
+		template.Name, template.Content.Title, template.Content.Text, template.Content.Url,
+	)
+
+	var created templateRow
+
+	if err := row.StructScan(&created); err != nil {
+		return nil, err
+	}
+
+	return created.toEntity(), nil
+}
+
+// UpdateTemplate updates fields of the template which are set in updateModel
+func (r *Repo) UpdateTemplate(ctx context.Context, id int, updateModel entity.BannerTemplate) error {
+	setQuery := "updated_at = now()"
+
+	var args []any
+
+	for column, value := range map[string]string{
+		"name":  updateModel.Name,
+		"title": updateModel.Content.Title,
+		"text":  updateModel.Content.Text,
+		"url":   updateModel.Content.Url,
+	} {
+		if value != "" {
+			args = append(args, value)
+			setQuery += fmt.Sprintf(", %v = $%v", column, len(args))
+		}
+	}
+
+	args = append(args, id)
+
+	res, err := r.DB.ExecContext(ctx, fmt.Sprintf("UPDATE banner_template SET %v WHERE id = $%v", setQuery, len(args)), args...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNoSuchTemplate
+	}
+
+	return nil
+}
+
+// DeleteTemplate deletes template, banners created from it are kept
+func (r *Repo) DeleteTemplate(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM banner_template WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNoSuchTemplate
+	}
+
+	return nil
+}
+
+// CheckUniqueConstraints checks if name is not used by template other than the one with id, zero id is used for new template
+func (r *Repo) CheckUniqueConstraints(ctx context.Context, id int, name string) error {
+	var exists bool
+
+	err := r.DB.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM banner_template WHERE name = $1 AND id <> $2)", name, id)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrTemplateNameExists
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package asset
+
+import "errors"
+
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type, only png, jpeg and gif images are accepted")
+	ErrInvalidImage         = errors.New("image can not be decoded")
+)
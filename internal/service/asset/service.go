@@ -0,0 +1,140 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+// supportedMediaTypes are mime types of images which dimensions can be decoded
+var supportedMediaTypes = map[string]struct{}{
+	"image/png":  {},
+	"image/jpeg": {},
+	"image/gif":  {},
+}
+
+type AssetRepo interface {
+	GetAllAssets(ctx context.Context, offset, limit int) ([]*entity.Asset, error)
+	GetAssetByHash(ctx context.Context, hash string) (*entity.Asset, error)
+	CreateAsset(ctx context.Context, asset entity.Asset) (*entity.Asset, error)
+	DeleteAsset(ctx context.Context, id int) (*entity.Asset, error)
+}
+
+// Storage stores content of assets by keys, local filesystem storage is used now, S3-compatible one may be used instead,
+// Open returns nil content if there is no object with key
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Service stores uploaded images addressed by hash of their content, so the same image is stored once
+// and its url never changes
+type Service struct {
+	AssetRepo AssetRepo
+	Storage   Storage
+
+	publicURL string
+}
+
+func New(assetRepo AssetRepo, storage Storage, publicURL string) *Service {
+	return &Service{
+		AssetRepo: assetRepo,
+		Storage:   storage,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+func (s *Service) GetAllAssets(ctx context.Context, offset, limit int) ([]*entity.Asset, error) {
+	assets, err := s.AssetRepo.GetAllAssets(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, asset := range assets {
+		s.setURL(asset)
+	}
+
+	return assets, nil
+}
+
+// UploadAsset stores image and returns its metadata, image uploaded before is returned as is
+func (s *Service) UploadAsset(ctx context.Context, data []byte) (*entity.Asset, error) {
+	mimeType := http.DetectContentType(data)
+
+	if _, ok := supportedMediaTypes[mimeType]; !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(ErrInvalidImage, err)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	// content is stored before metadata, so asset is never served without content
+	if err = s.Storage.Put(ctx, hash, data); err != nil {
+		return nil, err
+	}
+
+	asset, err := s.AssetRepo.CreateAsset(ctx, entity.Asset{
+		Hash:     hash,
+		MimeType: mimeType,
+		Size:     len(data),
+		Width:    config.Width,
+		Height:   config.Height,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.setURL(asset)
+
+	return asset, nil
+}
+
+// OpenAsset returns metadata and content of asset with hash, caller must close content, nil asset is returned
+// if there is no such asset or its content is already deleted
+func (s *Service) OpenAsset(ctx context.Context, hash string) (*entity.Asset, io.ReadSeekCloser, error) {
+	asset, err := s.AssetRepo.GetAssetByHash(ctx, hash)
+	if err != nil || asset == nil {
+		return nil, nil, err
+	}
+
+	content, err := s.Storage.Open(ctx, asset.Hash)
+	if err != nil || content == nil {
+		return nil, nil, err
+	}
+
+	s.setURL(asset)
+
+	return asset, content, nil
+}
+
+// DeleteAsset deletes asset and its content, banners referring it are not changed
+func (s *Service) DeleteAsset(ctx context.Context, id int) error {
+	asset, err := s.AssetRepo.DeleteAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.Storage.Delete(ctx, asset.Hash)
+}
+
+func (s *Service) setURL(asset *entity.Asset) {
+	asset.URL = s.publicURL + "/" + asset.Hash
+}
@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"context"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+type AuditRepo interface {
+	GetAuditRecords(ctx context.Context, entityType string, offset, limit int) ([]*entity.AuditRecord, error)
+}
+
+type Service struct {
+	AuditRepo AuditRepo
+}
+
+func New(auditRepo AuditRepo) *Service {
+	return &Service{
+		AuditRepo: auditRepo,
+	}
+}
+
+func (s *Service) GetAuditRecords(ctx context.Context, entityType string, offset, limit int) ([]*entity.AuditRecord, error) {
+	return s.AuditRepo.GetAuditRecords(ctx, entityType, offset, limit)
+}
@@ -0,0 +1,106 @@
+package banner
+
+import (
+	"context"
+	"slices"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+// GetBannersByQueries resolves each of queries the same way as GetBannerByFeatureAndTags does, banners, tags ancestors,
+// overrides and segments are fetched once for the whole batch, results are in the order of queries
+func (s *Service) GetBannersByQueries(ctx context.Context, queries []entity.BannerQuery, requester entity.Requester) ([]*entity.BannerQueryResult, error) {
+	var featureIDs, tagIDs []int
+
+	for _, query := range queries {
+		featureIDs = append(featureIDs, query.FeatureID)
+		tagIDs = append(tagIDs, query.TagIDs...)
+	}
+
+	banners, err := s.BannerRepo.GetBannersByFeatures(ctx, sliceutils.Unique(featureIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, sliceutils.Unique(tagIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.OverrideRepo.GetUserOverrides(ctx, requester.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	userSegmentIDs, err := s.getUserSegmentIDs(ctx, banners, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	bannersByID := make(map[int]*entity.Banner, len(banners))
+
+	for _, banner := range banners {
+		bannersByID[banner.ID] = banner
+	}
+
+	if banners, err = s.applyCampaigns(ctx, banners); err != nil {
+		return nil, err
+	}
+
+	results := make([]*entity.BannerQueryResult, 0, len(queries))
+
+	for _, query := range queries {
+		banner, err := s.resolveQuery(ctx, query, banners, bannersByID, ancestors, overrides, requester, userSegmentIDs)
+
+		results = append(results, &entity.BannerQueryResult{
+			Banner: banner,
+			Err:    err,
+		})
+	}
+
+	return results, nil
+}
+
+// resolveQuery returns banner of the batch query with banners of all queried features already fetched
+func (s *Service) resolveQuery(ctx context.Context, query entity.BannerQuery, banners []*entity.Banner, bannersByID map[int]*entity.Banner,
+	ancestors map[int][]int, overrides []*entity.Override, requester entity.Requester, userSegmentIDs []int) (*entity.Banner, error) {
+	enabled, err := s.FeatureSwitch.IsFeatureEnabled(ctx, query.FeatureID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, ErrNoSuchBanner
+	}
+
+	// banner pinned to user by override is returned regardless of tags and targeting
+	if pinned := getPinnedBanner(overrides, bannersByID, query.FeatureID); pinned != nil {
+		if _, err = s.claimShownBanners(ctx, []*entity.Banner{pinned}, 1); err != nil {
+			return nil, err
+		}
+
+		return pinned, nil
+	}
+
+	featureBanners := sliceutils.Filter(banners, func(banner *entity.Banner) bool {
+		return slices.Contains(banner.FeatureIDs, query.FeatureID)
+	})
+
+	ranked := rankReturnedBanners(groupBannersByTags(featureBanners, query.TagIDs, ancestors), requester, userSegmentIDs)
+
+	// banners of campaigns with spent budget are skipped
+	shown, err := s.claimShownBanners(ctx, ranked, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shown) == 0 {
+		s.recordMiss(query.FeatureID, query.TagIDs, ancestors)
+
+		return nil, ErrNoSuchBanner
+	}
+
+	return shown[0], nil
+}
@@ -0,0 +1,138 @@
+package banner
+
+import (
+	"context"
+	"time"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+// applyCampaigns returns banners which are not in campaign or which campaign is running, targeting of campaign
+// is applied to its banners in place, so banner targeted on its own keeps its targeting
+func (s *Service) applyCampaigns(ctx context.Context, banners []*entity.Banner) ([]*entity.Banner, error) {
+	campaignsByID, err := s.getCampaignsByID(ctx, banners)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(campaignsByID) == 0 {
+		return banners, nil
+	}
+
+	now := time.Now()
+
+	return sliceutils.Filter(banners, func(banner *entity.Banner) bool {
+		if banner.CampaignID == nil {
+			return true
+		}
+
+		campaign, exists := campaignsByID[*banner.CampaignID]
+		if !exists {
+			return true // campaign is deleted after banners were fetched
+		}
+
+		if !campaign.IsRunning(now) {
+			return false
+		}
+
+		applyCampaignTargeting(banner, campaign)
+
+		if campaign.EndsAt != nil {
+			banner.LimitShownUntil(*campaign.EndsAt)
+		}
+
+		return true
+	}), nil
+}
+
+// getCampaignsByID returns campaigns of banners by their ids
+func (s *Service) getCampaignsByID(ctx context.Context, banners []*entity.Banner) (map[int]*entity.Campaign, error) {
+	var campaignIDs []int
+
+	for _, banner := range banners {
+		if banner.CampaignID != nil {
+			campaignIDs = append(campaignIDs, *banner.CampaignID)
+		}
+	}
+
+	campaignsByID := make(map[int]*entity.Campaign, len(campaignIDs))
+
+	if len(campaignIDs) == 0 {
+		return campaignsByID, nil
+	}
+
+	campaigns, err := s.CampaignTracker.GetCampaignsWithIDs(ctx, sliceutils.Unique(campaignIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, campaign := range campaigns {
+		campaignsByID[campaign.ID] = campaign
+	}
+
+	return campaignsByID, nil
+}
+
+// applyCampaignTargeting fills targeting banner has not set with targeting defaults of its campaign
+func applyCampaignTargeting(banner *entity.Banner, campaign *entity.Campaign) {
+	if len(banner.Countries) == 0 {
+		banner.Countries = campaign.Countries
+	}
+
+	if len(banner.Regions) == 0 {
+		banner.Regions = campaign.Regions
+	}
+
+	if len(banner.Platforms) == 0 {
+		banner.Platforms = campaign.Platforms
+	}
+
+	if banner.MinAppVersion == "" {
+		banner.MinAppVersion = campaign.MinAppVersion
+	}
+
+	if banner.MaxAppVersion == "" {
+		banner.MaxAppVersion = campaign.MaxAppVersion
+	}
+}
+
+// ClaimImpressions counts one impression of banners of each of campaigns and reports whether budgets of all of them
+// allow it, so banners served from cache are not shown beyond budgets of their campaigns
+func (s *Service) ClaimImpressions(ctx context.Context, campaignIDs []int) (bool, error) {
+	if len(campaignIDs) == 0 {
+		return true, nil
+	}
+
+	return s.CampaignTracker.ClaimImpressions(ctx, campaignIDs)
+}
+
+// claimShownBanners returns up to limit of ranked banners which impressions are claimed against budgets
+// of their campaigns, banners of spent campaigns are skipped except for banner pinned to user, since override
+// pins banner regardless of its campaign
+func (s *Service) claimShownBanners(ctx context.Context, ranked []*entity.Banner, limit int) ([]*entity.Banner, error) {
+	var shown []*entity.Banner
+
+	for _, banner := range ranked {
+		if len(shown) == limit {
+			break
+		}
+
+		claimed, err := s.ClaimImpressions(ctx, entity.ShownCampaignIDs(banner))
+		if err != nil {
+			return nil, err
+		}
+
+		if claimed || banner.IsOverridden {
+			// cached banner is not served after its end date
+			if banner.EndsAt != nil {
+				banner.LimitShownUntil(*banner.EndsAt)
+			}
+
+			shown = append(shown, banner)
+		}
+	}
+
+	return shown, nil
+}
@@ -0,0 +1,89 @@
+package banner
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"errors"
+)
+
+// CloneBanner creates inactive copy of the banner, non-empty fields of overrides replace copied ones the same way
+// they do on update, so regional variants of banner may be created at once
+func (s *Service) CloneBanner(ctx context.Context, id int, overrides entity.Banner) (*entity.Banner, error) {
+	banner, err := s.BannerRepo.GetBannerByID(ctx, id)
+	if err != nil {
+		return nil, errors.Join(ErrNoSuchBanner, err)
+	}
+
+	clone := *banner
+
+	applyOverrides(&clone, overrides)
+
+	// clone is reviewed before it is shown to users
+	clone.IsActive = false
+
+	return s.CreateBanner(ctx, clone)
+}
+
+// applyOverrides replaces fields of banner with non-empty fields of overrides
+func applyOverrides(banner *entity.Banner, overrides entity.Banner) {
+	if len(overrides.TagIDs) != 0 {
+		banner.TagIDs = overrides.TagIDs
+	}
+
+	if len(overrides.FeatureIDs) != 0 {
+		banner.FeatureIDs = overrides.FeatureIDs
+	}
+
+	if len(overrides.SegmentIDs) != 0 {
+		banner.SegmentIDs = overrides.SegmentIDs
+	}
+
+	if len(overrides.Countries) != 0 {
+		banner.Countries = overrides.Countries
+	}
+
+	if len(overrides.Regions) != 0 {
+		banner.Regions = overrides.Regions
+	}
+
+	if len(overrides.Platforms) != 0 {
+		banner.Platforms = overrides.Platforms
+	}
+
+	if overrides.MinAppVersion != "" {
+		banner.MinAppVersion = overrides.MinAppVersion
+	}
+
+	if overrides.MaxAppVersion != "" {
+		banner.MaxAppVersion = overrides.MaxAppVersion
+	}
+
+	if overrides.CampaignID != nil {
+		banner.CampaignID = overrides.CampaignID
+
+		// 0 removes clone from campaign of the banner
+		if *overrides.CampaignID == 0 {
+			banner.CampaignID = nil
+		}
+	}
+
+	if overrides.Priority != nil {
+		banner.Priority = overrides.Priority
+	}
+
+	if overrides.Content.Title != "" {
+		banner.Content.Title = overrides.Content.Title
+	}
+
+	if overrides.Content.Text != "" {
+		banner.Content.Text = overrides.Content.Text
+	}
+
+	if overrides.Content.Url != "" {
+		banner.Content.Url = overrides.Content.Url
+	}
+
+	if overrides.EndsAt != nil {
+		banner.EndsAt = overrides.EndsAt
+	}
+}
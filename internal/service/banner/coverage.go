@@ -0,0 +1,134 @@
+package banner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+// GetCoverageReport returns combinations of the feature and tags requested by users which still have no served banner
+// and combinations which banners are all inactive or expired, combinations of all features are returned if featureID is 0
+func (s *Service) GetCoverageReport(ctx context.Context, featureID int) (*entity.CoverageReport, error) {
+	misses, err := s.MissRecorder.GetMisses(ctx, featureID, 0, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	banners, err := s.BannerRepo.GetAllBanners(ctx, featureID, 0, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	// banner shown in several features covers combinations of each of them
+	bannersByFeature := make(map[int][]*entity.Banner)
+
+	for _, banner := range banners {
+		for _, id := range banner.FeatureIDs {
+			if featureID == 0 || id == featureID {
+				bannersByFeature[id] = append(bannersByFeature[id], banner)
+			}
+		}
+	}
+
+	// ancestors of all requested tags are fetched at once
+	var tagIDs []int
+
+	for _, miss := range misses {
+		tagIDs = append(tagIDs, miss.TagIDs...)
+	}
+
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, sliceutils.Unique(tagIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	report := &entity.CoverageReport{
+		Inactive: getInactiveCombinations(bannersByFeature, now),
+	}
+
+	// banner may be created after miss was recorded, so only misses still having no served banner are reported
+	for _, miss := range misses {
+		matching := flattenGroups(groupBannersByTags(bannersByFeature[miss.FeatureID], miss.TagIDs, ancestors))
+
+		if !slices.ContainsFunc(matching, func(banner *entity.Banner) bool { return isServed(banner, now) }) {
+			report.Uncovered = append(report.Uncovered, miss)
+		}
+	}
+
+	return report, nil
+}
+
+// isExpired reports if end date of the banner has passed by now
+func isExpired(banner *entity.Banner, now time.Time) bool {
+	return banner.EndsAt != nil && !banner.EndsAt.After(now)
+}
+
+// isServed reports if banner is active and not expired, so it may be shown to users
+func isServed(banner *entity.Banner, now time.Time) bool {
+	return banner.IsActive && !isExpired(banner, now)
+}
+
+// getInactiveCombinations returns combinations of feature and tags which banners are all inactive or expired,
+// combination is expired if some of its banners is active but expired, so it is served again once end date is moved
+func getInactiveCombinations(bannersByFeature map[int][]*entity.Banner, now time.Time) []*entity.InactiveCombination {
+	var combinations []*entity.InactiveCombination
+
+	byKey := make(map[string]*entity.InactiveCombination)
+	hasServed := make(map[string]bool)
+
+	// features are iterated in order, so report does not change between requests
+	featureIDs := make([]int, 0, len(bannersByFeature))
+
+	for featureID := range bannersByFeature {
+		featureIDs = append(featureIDs, featureID)
+	}
+
+	slices.Sort(featureIDs)
+
+	for _, featureID := range featureIDs {
+		for _, banner := range bannersByFeature[featureID] {
+			key := fmt.Sprintf("%v#%v", featureID, banner.TagIDs) // banner.TagIDs are sorted
+
+			if isServed(banner, now) {
+				hasServed[key] = true
+				continue
+			}
+
+			combination, exists := byKey[key]
+			if !exists {
+				combination = &entity.InactiveCombination{
+					FeatureID: featureID,
+					TagIDs:    banner.TagIDs,
+					Reason:    entity.CoverageReasonInactive,
+				}
+
+				byKey[key] = combination
+				combinations = append(combinations, combination)
+			}
+
+			combination.BannerIDs = append(combination.BannerIDs, banner.ID)
+
+			if banner.IsActive {
+				combination.Reason = entity.CoverageReasonExpired
+			}
+		}
+	}
+
+	return sliceutils.Filter(combinations, func(combination *entity.InactiveCombination) bool {
+		return !hasServed[fmt.Sprintf("%v#%v", combination.FeatureID, combination.TagIDs)]
+	})
+}
+
+// GetMisses returns combinations of the feature and tags requested by users with no banner found sorted by frequency,
+// combinations of all features are returned if featureID is 0
+func (s *Service) GetMisses(ctx context.Context, featureID, offset, limit int) ([]*entity.BannerMiss, error) {
+	return s.MissRecorder.GetMisses(ctx, featureID, offset, limit)
+}
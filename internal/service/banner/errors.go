@@ -5,5 +5,27 @@ import "errors"
 var (
 	ErrNoSuchFeature = errors.New("no such feature")
 	ErrNoSuchTag     = errors.New("no such tag")
+	ErrNoSuchSegment = errors.New("no such segment")
 	ErrNoSuchBanner  = errors.New("no such banner")
+
+	ErrNoSuchCampaign = errors.New("no such campaign")
+
+	ErrInvalidAppVersion = errors.New("invalid app version")
+	ErrTagIsAlias        = errors.New("tag is an alias of another tag")
+
+	ErrFeatureDisabled = errors.New("feature is disabled")
+	ErrTagMismatch     = errors.New("banner tags differ from requested ones")
+	ErrBannerInactive  = errors.New("banner is inactive")
+	ErrBannerEnded     = errors.New("end date of banner has passed")
+	ErrCampaignPending = errors.New("campaign of banner has not started yet")
+	ErrCampaignEnded   = errors.New("campaign of banner has ended")
+	ErrCampaignSpent   = errors.New("impressions budget of banner campaign is spent")
+	ErrBannerShadowed  = errors.New("another banner matching request is preferred")
+	ErrBannerPinned    = errors.New("another banner is pinned to user by override")
+	ErrSegmentMismatch = errors.New("user is not in banner segments")
+	ErrCountryMismatch = errors.New("user country is not in banner countries")
+	ErrRegionMismatch  = errors.New("user region is not in banner regions")
+
+	ErrPlatformMismatch   = errors.New("user platform is not in banner platforms")
+	ErrAppVersionMismatch = errors.New("user app version is not supported by banner")
 )
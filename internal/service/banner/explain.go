@@ -0,0 +1,157 @@
+package banner
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+const (
+	ReasonPinnedByOverride = "banner is pinned to user by override"
+	ReasonMatches          = "banner matches feature, tags and targeting"
+)
+
+// ExplainBannerResolution returns every banner of the feature with the reason it is returned to requester or not,
+// the same rules as in GetBannerByFeatureAndTags are applied
+func (s *Service) ExplainBannerResolution(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester) ([]*entity.BannerCandidate, error) {
+	enabled, err := s.FeatureSwitch.IsFeatureEnabled(ctx, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	// override of archived or ended banner is ignored by lookup, so it is ignored here too
+	pinned, err := s.getOverriddenBanner(ctx, requester.UserID, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ended banners are not looked up by users, so they are fetched apart to be explained
+	banners, err := s.BannerRepo.GetAllBanners(ctx, featureID, 0, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	campaignsByID, err := s.getCampaignsByID(ctx, banners)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := s.applyCampaigns(ctx, sliceutils.Filter(banners, func(banner *entity.Banner) bool {
+		return !isExpired(banner, now)
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.getBannersMatchingTags(ctx, running, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := flattenGroups(groups)
+
+	userSegmentIDs, err := s.getUserSegmentIDs(ctx, matching, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := selectBannerFromGroups(groups, requester, userSegmentIDs)
+
+	return sliceutils.Map(banners, func(banner *entity.Banner) *entity.BannerCandidate {
+		candidate := &entity.BannerCandidate{
+			Banner: banner,
+		}
+
+		if !enabled {
+			candidate.Reason = ErrFeatureDisabled.Error()
+
+			return candidate
+		}
+
+		var campaign *entity.Campaign
+
+		if banner.CampaignID != nil {
+			campaign = campaignsByID[*banner.CampaignID]
+		}
+
+		if err := explainRejection(banner, campaign, now, slices.Contains(matching, banner), requester,
+			userSegmentIDs, pinned, selected); err != nil {
+			candidate.Reason = err.Error()
+
+			return candidate
+		}
+
+		candidate.Accepted = true
+		candidate.Reason = ReasonMatches
+
+		if pinned != nil {
+			candidate.Reason = ReasonPinnedByOverride
+		}
+
+		return candidate
+	}), nil
+}
+
+// explainRejection returns error describing why banner is not returned to requester or nil if it is returned,
+// campaign is nil if banner is not part of any campaign, pinned is nil if no shown banner is pinned to requester
+func explainRejection(banner *entity.Banner, campaign *entity.Campaign, now time.Time, tagsMatch bool, requester entity.Requester,
+	userSegmentIDs []int, pinned *entity.Banner, selected *entity.Banner) error {
+	// override is applied before tags and targeting
+	if pinned != nil {
+		if pinned.ID == banner.ID {
+			return nil
+		}
+
+		return ErrBannerPinned
+	}
+
+	if isExpired(banner, now) {
+		return ErrBannerEnded
+	}
+
+	if campaign != nil {
+		if err := explainCampaignRejection(campaign, now); err != nil {
+			return err
+		}
+	}
+
+	if !tagsMatch {
+		return ErrTagMismatch
+	}
+
+	if err := checkTargeting(banner, requester, userSegmentIDs); err != nil {
+		return err
+	}
+
+	if selected == nil || selected.ID != banner.ID {
+		return ErrBannerShadowed
+	}
+
+	// inactive banner is selected only if no active banner matches
+	if !banner.IsActive {
+		return ErrBannerInactive
+	}
+
+	return nil
+}
+
+// explainCampaignRejection returns error describing why banners of the campaign are not shown or nil if it is running
+func explainCampaignRejection(campaign *entity.Campaign, now time.Time) error {
+	switch {
+	case campaign.StartsAt != nil && now.Before(*campaign.StartsAt):
+		return ErrCampaignPending
+	case campaign.EndsAt != nil && !now.Before(*campaign.EndsAt):
+		return ErrCampaignEnded
+	case !campaign.IsRunning(now):
+		return ErrCampaignSpent
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package banner
+
+import (
+	"context"
+	"slices"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+// GetUserBanners returns active banners shown to requester in the page of enabled features grouped by feature,
+// banners of each feature are ranked the same way as by GetBannersByFeatureAndTags, features are paginated before
+// banners are targeted by geo, platform, app version and campaigns, so features left without banners are omitted from the page
+func (s *Service) GetUserBanners(ctx context.Context, tagIDs []int, requester entity.Requester, offset, limit int) ([]*entity.FeatureBanners, error) {
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the latest override of each feature is applied
+	overrides, err := s.OverrideRepo.GetUserOverrides(ctx, requester.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	userSegmentIDs, err := s.SegmentRepo.GetUserSegmentIDs(ctx, requester.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// banner may be matched by requested tags or any of their ancestors
+	matchingTagIDs := slices.Clone(tagIDs)
+
+	for _, chain := range ancestors {
+		matchingTagIDs = append(matchingTagIDs, chain...)
+	}
+
+	matchingTagIDs = sliceutils.Unique(matchingTagIDs)
+
+	pinnedIDs := sliceutils.Unique(sliceutils.Map(overrides, func(override *entity.Override) int { return override.BannerID }))
+
+	featureIDs, err := s.BannerRepo.GetShownFeatureIDs(ctx, matchingTagIDs, userSegmentIDs, pinnedIDs, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(featureIDs) == 0 {
+		return nil, nil
+	}
+
+	banners, err := s.BannerRepo.GetShownBanners(ctx, featureIDs, matchingTagIDs, userSegmentIDs, pinnedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	bannersByID := make(map[int]*entity.Banner, len(banners))
+	bannersByFeature := make(map[int][]*entity.Banner)
+
+	for _, banner := range banners {
+		bannersByID[banner.ID] = banner
+	}
+
+	// pinned banners are looked up among all banners, since override pins banner regardless of its campaign
+	if banners, err = s.applyCampaigns(ctx, banners); err != nil {
+		return nil, err
+	}
+
+	for _, banner := range banners {
+		for _, featureID := range banner.FeatureIDs {
+			bannersByFeature[featureID] = append(bannersByFeature[featureID], banner)
+		}
+	}
+
+	var featureBanners []*entity.FeatureBanners
+
+	for _, featureID := range featureIDs {
+		groups := groupBannersByTags(bannersByFeature[featureID], tagIDs, ancestors)
+
+		ranked := rankShownBanners(groups, requester, userSegmentIDs, getPinnedBanner(overrides, bannersByID, featureID))
+
+		shown, err := s.claimShownBanners(ctx, ranked, len(ranked))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(shown) == 0 {
+			continue
+		}
+
+		featureBanners = append(featureBanners, &entity.FeatureBanners{
+			FeatureID: featureID,
+			Banners:   shown,
+		})
+	}
+
+	return featureBanners, nil
+}
+
+// getPinnedBanner returns copy of banner pinned to user in the feature by the latest of overrides or nil,
+// banner is copied since it may be shown in other features without override
+func getPinnedBanner(overrides []*entity.Override, bannersByID map[int]*entity.Banner, featureID int) *entity.Banner {
+	for _, override := range overrides {
+		if !slices.Contains(override.FeatureIDs, featureID) {
+			continue
+		}
+
+		banner, exists := bannersByID[override.BannerID]
+		if !exists {
+			return nil
+		}
+
+		pinned := *banner
+		pinned.IsOverridden = true
+
+		// cached pinned banner is not served after override expires
+		pinned.LimitShownUntil(override.ExpiresAt)
+
+		return &pinned
+	}
+
+	return nil
+}
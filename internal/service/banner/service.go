@@ -8,63 +8,336 @@ import (
 	"avito-backend-trainee-2024/internal/domain/entity"
 
 	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	versionutils "avito-backend-trainee-2024/pkg/utils/version"
 )
 
 type BannerRepo interface {
-	GetAllBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error)
+	GetAllBanners(ctx context.Context, featureID, offset, limit int) ([]*entity.Banner, error)
+	GetArchivedBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error)
 	GetBannerByID(ctx context.Context, id int) (*entity.Banner, error)
-	GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int) (*entity.Banner, error)
+	GetShownBannerByID(ctx context.Context, id int) (*entity.Banner, error)
+	GetBannerCandidates(ctx context.Context, featureID int, tagIDs []int, isActive bool) ([]*entity.Banner, error)
+	GetBannersByFeatures(ctx context.Context, featureIDs []int) ([]*entity.Banner, error)
+	GetShownFeatureIDs(ctx context.Context, tagIDs, segmentIDs, pinnedIDs []int, offset, limit int) ([]int, error)
+	GetShownBanners(ctx context.Context, featureIDs, tagIDs, segmentIDs, pinnedIDs []int) ([]*entity.Banner, error)
 	CreateBanner(ctx context.Context, banner entity.Banner) (*entity.Banner, error)
 	UpdateBanner(ctx context.Context, id int, updateModel entity.Banner) error
 	DeleteBanner(ctx context.Context, id int) (*entity.Banner, error)
 }
 
 type FeatureRepo interface {
-	GetFeatureByID(ctx context.Context, id int) (*entity.Feature, error)
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetFeaturesWithIDs(ctx context.Context, IDs []int) ([]*entity.Feature, error)
 }
 
 type TagRepo interface {
 	GetTagsWithIDs(ctx context.Context, IDs []int) ([]*entity.Tag, error)
+	GetTagAncestors(ctx context.Context, IDs []int) (map[int][]int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+}
+
+type SegmentRepo interface {
+	GetSegmentsWithIDs(ctx context.Context, IDs []int) ([]*entity.Segment, error)
+	GetUserSegmentIDs(ctx context.Context, userID int) ([]int, error)
+}
+
+type OverrideRepo interface {
+	GetUserOverride(ctx context.Context, userID, featureID int) (*entity.Override, error)
+	GetUserOverrides(ctx context.Context, userID int) ([]*entity.Override, error)
+}
+
+type MissRecorder interface {
+	RecordMiss(featureID int, tagIDs []int)
+	GetMisses(ctx context.Context, featureID, offset, limit int) ([]*entity.BannerMiss, error)
+}
+
+// FeatureSwitch tells whether feature is turned on, no banners of disabled feature are shown to users
+type FeatureSwitch interface {
+	IsFeatureEnabled(ctx context.Context, id int) (bool, error)
+}
+
+// CampaignTracker provides campaigns of banners and claims impressions against their budgets
+type CampaignTracker interface {
+	GetCampaignsWithIDs(ctx context.Context, IDs []int) ([]*entity.Campaign, error)
+	ClaimImpressions(ctx context.Context, campaignIDs []int) (bool, error)
 }
 
 type Service struct {
-	BannerRepo  BannerRepo
-	FeatureRepo FeatureRepo
-	TagRepo     TagRepo
+	BannerRepo    BannerRepo
+	FeatureRepo   FeatureRepo
+	TagRepo       TagRepo
+	SegmentRepo   SegmentRepo
+	OverrideRepo  OverrideRepo
+	MissRecorder  MissRecorder
+	FeatureSwitch FeatureSwitch
+
+	CampaignTracker CampaignTracker
 }
 
-func New(bannerRepo BannerRepo, featureRepo FeatureRepo, tagRepo TagRepo) *Service {
+func New(bannerRepo BannerRepo, featureRepo FeatureRepo, tagRepo TagRepo, segmentRepo SegmentRepo, overrideRepo OverrideRepo,
+	missRecorder MissRecorder, featureSwitch FeatureSwitch, campaignTracker CampaignTracker) *Service {
 	return &Service{
-		BannerRepo:  bannerRepo,
-		FeatureRepo: featureRepo,
-		TagRepo:     tagRepo,
+		BannerRepo:      bannerRepo,
+		FeatureRepo:     featureRepo,
+		TagRepo:         tagRepo,
+		SegmentRepo:     segmentRepo,
+		OverrideRepo:    overrideRepo,
+		MissRecorder:    missRecorder,
+		FeatureSwitch:   featureSwitch,
+		CampaignTracker: campaignTracker,
 	}
 }
 
-func (s *Service) GetAllBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error) {
-	return s.BannerRepo.GetAllBanners(ctx, offset, limit)
+// GetAllBanners returns banners shown in the feature with their features, banners of all features are returned
+// if featureID is 0
+func (s *Service) GetAllBanners(ctx context.Context, featureID, offset, limit int) ([]*entity.Banner, error) {
+	banners, err := s.BannerRepo.GetAllBanners(ctx, featureID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.fillFeatures(ctx, banners); err != nil {
+		return nil, err
+	}
+
+	return banners, nil
 }
 
-func (s *Service) GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int) (*entity.Banner, error) {
-	slices.Sort(tagIDs) // sort slice
+// GetArchivedBanners returns banners archived by sweep, they are hidden from other banner lists until updated
+func (s *Service) GetArchivedBanners(ctx context.Context, offset, limit int) ([]*entity.Banner, error) {
+	banners, err := s.BannerRepo.GetArchivedBanners(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
 
-	banner, err := s.BannerRepo.GetBannerByFeatureAndTags(ctx, featureID, tagIDs)
+	if err = s.fillFeatures(ctx, banners); err != nil {
+		return nil, err
+	}
+
+	return banners, nil
+}
+
+// fillFeatures sets features of banners, so admins can see which screens banners are shown on
+func (s *Service) fillFeatures(ctx context.Context, banners []*entity.Banner) error {
+	var featureIDs []int
+
+	for _, banner := range banners {
+		featureIDs = append(featureIDs, banner.FeatureIDs...)
+	}
+
+	features, err := s.FeatureRepo.GetFeaturesWithIDs(ctx, sliceutils.Unique(featureIDs))
+	if err != nil {
+		return err
+	}
+
+	featuresByID := make(map[int]*entity.Feature, len(features))
+
+	for _, feature := range features {
+		featuresByID[feature.ID] = feature
+	}
+
+	for _, banner := range banners {
+		banner.Features = sliceutils.Map(banner.FeatureIDs, func(id int) *entity.Feature { return featuresByID[id] })
+	}
+
+	return nil
+}
+
+// GetFeatureIDBySlug returns id of feature with slug, so clients can refer features by slugs instead of ids
+func (s *Service) GetFeatureIDBySlug(ctx context.Context, slug string) (int, error) {
+	return s.FeatureRepo.GetFeatureIDBySlug(ctx, slug)
+}
+
+// GetTagIDsBySlugs returns ids of tags with slugs in the same order
+func (s *Service) GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error) {
+	return s.TagRepo.GetTagIDsBySlugs(ctx, slugs)
+}
+
+// IsFeatureEnabled reports whether banners of the feature may be shown to users
+func (s *Service) IsFeatureEnabled(ctx context.Context, featureID int) (bool, error) {
+	return s.FeatureSwitch.IsFeatureEnabled(ctx, featureID)
+}
+
+func (s *Service) GetBannerByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester) (*entity.Banner, error) {
+	// disabled feature hides all its banners, overrides included
+	enabled, err := s.FeatureSwitch.IsFeatureEnabled(ctx, featureID)
 	if err != nil {
 		return nil, err
 	}
 
-	if banner == nil {
+	if !enabled {
 		return nil, ErrNoSuchBanner
 	}
 
-	return banner, nil
+	// banner pinned to user by override is returned regardless of tags and targeting
+	pinned, err := s.getOverriddenBanner(ctx, requester.UserID, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pinned != nil {
+		if _, err = s.claimShownBanners(ctx, []*entity.Banner{pinned}, 1); err != nil {
+			return nil, err
+		}
+
+		return pinned, nil
+	}
+
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// inactive banner is looked up only if no active one matches, so requester is told banner is inactive
+	for _, isActive := range []bool{true, false} {
+		banner, err := s.lookupBanner(ctx, featureID, tagIDs, ancestors, requester, isActive)
+		if err != nil || banner != nil {
+			return banner, err
+		}
+	}
+
+	s.recordMiss(featureID, tagIDs, ancestors)
+
+	return nil, ErrNoSuchBanner
+}
+
+// lookupBanner returns banner of the feature with activity isActive which is ranked first for requester,
+// nil is returned if no such banner matches
+func (s *Service) lookupBanner(ctx context.Context, featureID int, tagIDs []int, ancestors map[int][]int,
+	requester entity.Requester, isActive bool) (*entity.Banner, error) {
+	banners, err := s.BannerRepo.GetBannerCandidates(ctx, featureID, coveringTagIDs(tagIDs, ancestors), isActive)
+	if err != nil {
+		return nil, err
+	}
+
+	// banners of campaigns which are not running are not shown, so other banners are selected instead of them
+	if banners, err = s.applyCampaigns(ctx, banners); err != nil {
+		return nil, err
+	}
+
+	groups := groupBannersByTags(banners, tagIDs, ancestors)
+
+	userSegmentIDs, err := s.getUserSegmentIDs(ctx, flattenGroups(groups), requester)
+	if err != nil {
+		return nil, err
+	}
+
+	// banners of campaigns with spent budget are skipped
+	shown, err := s.claimShownBanners(ctx, rankReturnedBanners(groups, requester, userSegmentIDs), 1)
+	if err != nil || len(shown) == 0 {
+		return nil, err
+	}
+
+	return shown[0], nil
 }
 
-// validateBanner checks if associated with banner tags and feature are presented in db
-func (s *Service) validateBanner(ctx context.Context, banner entity.Banner, validateFeature, validateTags bool) error {
-	if validateFeature {
-		feature, err := s.FeatureRepo.GetFeatureByID(ctx, banner.FeatureID)
-		if err != nil || feature == nil {
+// GetBannersByFeatureAndTags returns up to limit active banners shown to requester ranked the same way banner is
+// selected by GetBannerByFeatureAndTags, so the first of them is the one it returns if active
+func (s *Service) GetBannersByFeatureAndTags(ctx context.Context, featureID int, tagIDs []int, requester entity.Requester,
+	limit int) ([]*entity.Banner, error) {
+	enabled, err := s.FeatureSwitch.IsFeatureEnabled(ctx, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	pinned, err := s.getOverriddenBanner(ctx, requester.UserID, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// inactive banners are not listed, the pinned one is fetched above
+	banners, err := s.BannerRepo.GetBannerCandidates(ctx, featureID, coveringTagIDs(tagIDs, ancestors), true)
+	if err != nil {
+		return nil, err
+	}
+
+	if banners, err = s.applyCampaigns(ctx, banners); err != nil {
+		return nil, err
+	}
+
+	groups := groupBannersByTags(banners, tagIDs, ancestors)
+
+	userSegmentIDs, err := s.getUserSegmentIDs(ctx, flattenGroups(groups), requester)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := rankShownBanners(groups, requester, userSegmentIDs, pinned)
+	if len(ranked) == 0 {
+		s.recordMiss(featureID, tagIDs, ancestors)
+	}
+
+	return s.claimShownBanners(ctx, ranked, limit)
+}
+
+// getOverriddenBanner returns banner pinned to user in the feature by override, nil is returned if there is no override
+// or pinned banner is archived or ended, so banner is selected as if user had no override
+func (s *Service) getOverriddenBanner(ctx context.Context, userID, featureID int) (*entity.Banner, error) {
+	override, err := s.OverrideRepo.GetUserOverride(ctx, userID, featureID)
+	if err != nil || override == nil {
+		return nil, err
+	}
+
+	pinned, err := s.BannerRepo.GetShownBannerByID(ctx, override.BannerID)
+	if err != nil || pinned == nil {
+		return nil, err
+	}
+
+	pinned.IsOverridden = true
+
+	// cached pinned banner is not served after override expires
+	pinned.LimitShownUntil(override.ExpiresAt)
+
+	return pinned, nil
+}
+
+// recordMiss records lookup of the feature and tags with no banner found, tags are recorded as the tags aliases were
+// merged into, lookups with unknown tags are not recorded, so made up tag ids do not pollute telemetry
+func (s *Service) recordMiss(featureID int, tagIDs []int, ancestors map[int][]int) {
+	canonicalIDs := make([]int, 0, len(tagIDs))
+
+	for _, tagID := range tagIDs {
+		chain, exists := ancestors[tagID]
+		if !exists {
+			return
+		}
+
+		canonicalIDs = append(canonicalIDs, chain[0])
+	}
+
+	s.MissRecorder.RecordMiss(featureID, normalizeTagIDs(canonicalIDs))
+}
+
+// getUserSegmentIDs returns segments of requester, segments are fetched only if some of banners is targeted to segments
+func (s *Service) getUserSegmentIDs(ctx context.Context, banners []*entity.Banner, requester entity.Requester) ([]int, error) {
+	if !slices.ContainsFunc(banners, func(banner *entity.Banner) bool { return len(banner.SegmentIDs) != 0 }) {
+		return nil, nil
+	}
+
+	return s.SegmentRepo.GetUserSegmentIDs(ctx, requester.UserID)
+}
+
+// validateBanner checks if associated with banner tags, features and segments are presented in db and its targeting is valid
+func (s *Service) validateBanner(ctx context.Context, banner entity.Banner, validateFeatures, validateTags, validateSegments bool) error {
+	if validateFeatures {
+		features, err := s.FeatureRepo.GetFeaturesWithIDs(ctx, banner.FeatureIDs)
+		if err != nil {
+			return errors.Join(ErrNoSuchFeature, err)
+		}
+
+		// features are compared the same way as tags below, banner is shown in each feature once
+		slices.Sort(banner.FeatureIDs)
+
+		if !sliceutils.Equals(banner.FeatureIDs, sliceutils.Map(features, func(feature *entity.Feature) int { return feature.ID })) {
 			return ErrNoSuchFeature
 		}
 	}
@@ -85,14 +358,65 @@ func (s *Service) validateBanner(ctx context.Context, banner entity.Banner, vali
 		if !sliceutils.Equals(banner.TagIDs, sliceutils.Map(tags, func(tag *entity.Tag) int { return tag.ID })) {
 			return ErrNoSuchTag
 		}
+
+		// banners are tagged with tags aliases were merged into
+		if slices.ContainsFunc(tags, func(tag *entity.Tag) bool { return tag.AliasOfID != nil }) {
+			return ErrTagIsAlias
+		}
+	}
+
+	if validateSegments {
+		segments, err := s.SegmentRepo.GetSegmentsWithIDs(ctx, banner.SegmentIDs)
+		if err != nil {
+			return errors.Join(ErrNoSuchSegment, err)
+		}
+
+		slices.Sort(banner.SegmentIDs)
+
+		if !sliceutils.Equals(banner.SegmentIDs, sliceutils.Map(segments, func(segment *entity.Segment) int { return segment.ID })) {
+			return ErrNoSuchSegment
+		}
+	}
+
+	// zero campaign id removes banner from its campaign on update
+	if banner.CampaignID != nil && *banner.CampaignID != 0 {
+		campaigns, err := s.CampaignTracker.GetCampaignsWithIDs(ctx, []int{*banner.CampaignID})
+		if err != nil {
+			return errors.Join(ErrNoSuchCampaign, err)
+		}
+
+		if len(campaigns) == 0 {
+			return ErrNoSuchCampaign
+		}
+	}
+
+	return nil
+}
+
+// validateAppVersions checks if app versions of banner are valid and min version is not greater than max one
+func validateAppVersions(banner entity.Banner) error {
+	for _, version := range []string{banner.MinAppVersion, banner.MaxAppVersion} {
+		if version != "" && !versionutils.IsValid(version) {
+			return ErrInvalidAppVersion
+		}
+	}
+
+	if banner.MinAppVersion != "" && banner.MaxAppVersion != "" {
+		if cmp, _ := versionutils.Compare(banner.MinAppVersion, banner.MaxAppVersion); cmp > 0 {
+			return ErrInvalidAppVersion
+		}
 	}
 
 	return nil
 }
 
 func (s *Service) CreateBanner(ctx context.Context, banner entity.Banner) (*entity.Banner, error) {
-	// firstly validate that feature and tags associated with banner exists in db
-	if err := s.validateBanner(ctx, banner, true, true); err != nil {
+	// firstly validate that features and tags associated with banner exists in db
+	if err := s.validateBanner(ctx, banner, true, true, len(banner.SegmentIDs) != 0); err != nil {
+		return nil, err
+	}
+
+	if err := validateAppVersions(banner); err != nil {
 		return nil, err
 	}
 
@@ -100,14 +424,53 @@ func (s *Service) CreateBanner(ctx context.Context, banner entity.Banner) (*enti
 }
 
 func (s *Service) UpdateBanner(ctx context.Context, id int, updateModel entity.Banner) error {
-	// firstly validate that feature and tags associated with banner exists in db
-	if err := s.validateBanner(ctx, updateModel, updateModel.FeatureID != 0, len(updateModel.TagIDs) != 0); err != nil {
+	// firstly validate that features and tags associated with banner exists in db
+	if err := s.ValidateBannerUpdate(ctx, id, updateModel); err != nil {
 		return err
 	}
 
 	return s.BannerRepo.UpdateBanner(ctx, id, updateModel)
 }
 
+// ValidateBannerUpdate checks update model the same way UpdateBanner does, so update scheduled for later
+// is rejected at once if it is invalid
+func (s *Service) ValidateBannerUpdate(ctx context.Context, id int, updateModel entity.Banner) error {
+	if err := s.validateBanner(ctx, updateModel, len(updateModel.FeatureIDs) != 0, len(updateModel.TagIDs) != 0, len(updateModel.SegmentIDs) != 0); err != nil {
+		return err
+	}
+
+	banner, err := s.BannerRepo.GetBannerByID(ctx, id)
+	if err != nil {
+		return errors.Join(ErrNoSuchBanner, err)
+	}
+
+	// app versions are validated as they will be after update, so bound is not moved past the stored other one
+	updated := *banner
+
+	applyAppVersions(&updated, updateModel)
+
+	return validateAppVersions(updated)
+}
+
+// applyAppVersions replaces app versions of banner with not empty ones of update model, NoAppVersion removes bound
+func applyAppVersions(banner *entity.Banner, updateModel entity.Banner) {
+	switch updateModel.MinAppVersion {
+	case "":
+	case entity.NoAppVersion:
+		banner.MinAppVersion = ""
+	default:
+		banner.MinAppVersion = updateModel.MinAppVersion
+	}
+
+	switch updateModel.MaxAppVersion {
+	case "":
+	case entity.NoAppVersion:
+		banner.MaxAppVersion = ""
+	default:
+		banner.MaxAppVersion = updateModel.MaxAppVersion
+	}
+}
+
 func (s *Service) DeleteBanner(ctx context.Context, id int) (*entity.Banner, error) {
 	return s.BannerRepo.DeleteBanner(ctx, id)
 }
@@ -0,0 +1,208 @@
+package banner
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+// matchTags checks if banner tags match requested ones taking tags hierarchy into account and returns distance of the match:
+// each requested tag must be covered by banner tag equal to it or to one of its ancestors, each banner tag must cover
+// some requested tag, distance is a sum of levels between requested tags and the closest banner tags covering them
+func matchTags(bannerTagIDs []int, tagIDs []int, ancestors map[int][]int) (int, bool) {
+	covering := make(map[int]bool, len(bannerTagIDs))
+	distance := 0
+
+	for _, tagID := range tagIDs {
+		chain, exists := ancestors[tagID]
+		if !exists {
+			chain = []int{tagID}
+		}
+
+		level := -1
+
+		for i, ancestorID := range chain {
+			if !slices.Contains(bannerTagIDs, ancestorID) {
+				continue
+			}
+
+			covering[ancestorID] = true
+
+			if level == -1 {
+				level = i
+			}
+		}
+
+		if level == -1 {
+			return 0, false
+		}
+
+		distance += level
+	}
+
+	return distance, len(covering) == len(bannerTagIDs)
+}
+
+// coveringTagIDs returns requested tags with their ancestors, banner having any other tag never matches requested tags
+func coveringTagIDs(tagIDs []int, ancestors map[int][]int) []int {
+	covering := slices.Clone(tagIDs)
+
+	for _, tagID := range tagIDs {
+		covering = append(covering, ancestors[tagID]...)
+	}
+
+	return sliceutils.Unique(covering)
+}
+
+// getBannersMatchingTags returns banners matching requested tags grouped by distance of the match,
+// the most specific group goes first, so banners with exactly requested tags are preferred to inherited ones
+func (s *Service) getBannersMatchingTags(ctx context.Context, banners []*entity.Banner, tagIDs []int) ([][]*entity.Banner, error) {
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupBannersByTags(banners, tagIDs, ancestors), nil
+}
+
+// groupBannersByTags is getBannersMatchingTags with ancestors of requested tags already fetched
+func groupBannersByTags(banners []*entity.Banner, tagIDs []int, ancestors map[int][]int) [][]*entity.Banner {
+	groups := make(map[int][]*entity.Banner)
+
+	for _, banner := range banners {
+		distance, ok := matchTags(banner.TagIDs, tagIDs, ancestors)
+		if !ok {
+			continue
+		}
+
+		groups[distance] = append(groups[distance], banner)
+	}
+
+	distances := make([]int, 0, len(groups))
+
+	for distance := range groups {
+		distances = append(distances, distance)
+	}
+
+	sort.Ints(distances)
+
+	matching := make([][]*entity.Banner, 0, len(groups))
+
+	for _, distance := range distances {
+		matching = append(matching, groups[distance])
+	}
+
+	return matching
+}
+
+// normalizeTagIDs returns sorted copy of tag ids without duplicates
+func normalizeTagIDs(tagIDs []int) []int {
+	normalized := sliceutils.Unique(tagIDs)
+
+	slices.Sort(normalized)
+
+	return normalized
+}
+
+// selectBannerFromGroups returns banner returned to requester which is ranked first by rankReturnedBanners
+func selectBannerFromGroups(groups [][]*entity.Banner, requester entity.Requester, userSegmentIDs []int) *entity.Banner {
+	ranked := rankReturnedBanners(groups, requester, userSegmentIDs)
+	if len(ranked) == 0 {
+		return nil
+	}
+
+	return ranked[0]
+}
+
+// rankReturnedBanners returns banners ranked by rankBannersFromGroups with active banners going first, so inactive
+// banner is returned only if no active one matches and requester is told banner is inactive
+func rankReturnedBanners(groups [][]*entity.Banner, requester entity.Requester, userSegmentIDs []int) []*entity.Banner {
+	ranked := rankBannersFromGroups(groups, requester, userSegmentIDs)
+
+	active := sliceutils.Filter(ranked, func(banner *entity.Banner) bool { return banner.IsActive })
+	inactive := sliceutils.Filter(ranked, func(banner *entity.Banner) bool { return !banner.IsActive })
+
+	return append(active, inactive...)
+}
+
+// rankBannersFromGroups returns banners shown to requester ranked by priority, banners of the same priority
+// are ranked by specificity: more specific groups go first and targeted banners of the group go before untargeted ones,
+// the rest is ranked by id
+func rankBannersFromGroups(groups [][]*entity.Banner, requester entity.Requester, userSegmentIDs []int) []*entity.Banner {
+	type rankedBanner struct {
+		banner     *entity.Banner
+		group      int
+		untargeted bool
+	}
+
+	var shown []rankedBanner
+
+	for i, group := range groups {
+		for _, banner := range group {
+			if checkTargeting(banner, requester, userSegmentIDs) != nil {
+				continue
+			}
+
+			shown = append(shown, rankedBanner{banner: banner, group: i, untargeted: !isTargeted(banner)})
+		}
+	}
+
+	sort.SliceStable(shown, func(i, j int) bool {
+		if left, right := priority(shown[i].banner), priority(shown[j].banner); left != right {
+			return left > right
+		}
+
+		if shown[i].group != shown[j].group {
+			return shown[i].group < shown[j].group
+		}
+
+		if shown[i].untargeted != shown[j].untargeted {
+			return !shown[i].untargeted
+		}
+
+		return shown[i].banner.ID < shown[j].banner.ID
+	})
+
+	return sliceutils.Map(shown, func(ranked rankedBanner) *entity.Banner { return ranked.banner })
+}
+
+// priority returns priority of the banner, banner without priority set is ranked as having zero one
+func priority(banner *entity.Banner) int {
+	if banner.Priority == nil {
+		return 0
+	}
+
+	return *banner.Priority
+}
+
+// rankShownBanners returns active banners ranked by rankBannersFromGroups, banner pinned to requester by override
+// goes first even if inactive
+func rankShownBanners(groups [][]*entity.Banner, requester entity.Requester, userSegmentIDs []int, pinned *entity.Banner) []*entity.Banner {
+	var ranked []*entity.Banner
+
+	if pinned != nil {
+		ranked = append(ranked, pinned)
+	}
+
+	for _, banner := range rankBannersFromGroups(groups, requester, userSegmentIDs) {
+		if banner.IsActive && (pinned == nil || banner.ID != pinned.ID) {
+			ranked = append(ranked, banner)
+		}
+	}
+
+	return ranked
+}
+
+func flattenGroups(groups [][]*entity.Banner) []*entity.Banner {
+	var banners []*entity.Banner
+
+	for _, group := range groups {
+		banners = append(banners, group...)
+	}
+
+	return banners
+}
@@ -0,0 +1,60 @@
+package banner
+
+import (
+	"slices"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	versionutils "avito-backend-trainee-2024/pkg/utils/version"
+)
+
+// isTargeted reports if banner is targeted to some audience narrower than its feature and tags
+func isTargeted(banner *entity.Banner) bool {
+	return len(banner.SegmentIDs) != 0 || len(banner.Countries) != 0 || len(banner.Regions) != 0 ||
+		len(banner.Platforms) != 0 || banner.MinAppVersion != "" || banner.MaxAppVersion != ""
+}
+
+// checkTargeting returns nil if banner may be shown to requester, otherwise error describing the mismatch
+func checkTargeting(banner *entity.Banner, requester entity.Requester, userSegmentIDs []int) error {
+	if len(banner.SegmentIDs) != 0 && !containsAny(banner.SegmentIDs, userSegmentIDs) {
+		return ErrSegmentMismatch
+	}
+
+	// requester with unknown location does not match any geo targeted banner
+	if len(banner.Countries) != 0 && !slices.Contains(banner.Countries, requester.Country) {
+		return ErrCountryMismatch
+	}
+
+	if len(banner.Regions) != 0 && !slices.Contains(banner.Regions, requester.Region) {
+		return ErrRegionMismatch
+	}
+
+	if len(banner.Platforms) != 0 && !slices.Contains(banner.Platforms, requester.Platform) {
+		return ErrPlatformMismatch
+	}
+
+	// unknown app version is considered unsupported, because old apps may not send it at all
+	if banner.MinAppVersion != "" {
+		if cmp, err := versionutils.Compare(requester.AppVersion, banner.MinAppVersion); err != nil || cmp < 0 {
+			return ErrAppVersionMismatch
+		}
+	}
+
+	if banner.MaxAppVersion != "" {
+		if cmp, err := versionutils.Compare(requester.AppVersion, banner.MaxAppVersion); err != nil || cmp > 0 {
+			return ErrAppVersionMismatch
+		}
+	}
+
+	return nil
+}
+
+func containsAny(s []int, values []int) bool {
+	for _, v := range values {
+		if slices.Contains(s, v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,8 @@
+package campaign
+
+import "errors"
+
+var (
+	ErrInvalidSchedule   = errors.New("campaign must end after it starts")
+	ErrInvalidAppVersion = errors.New("invalid app version")
+)
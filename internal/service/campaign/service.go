@@ -0,0 +1,287 @@
+package campaign
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+	versionutils "avito-backend-trainee-2024/pkg/utils/version"
+)
+
+const (
+	DefaultFlushInterval   = time.Minute
+	DefaultReservationSize = 100
+)
+
+type CampaignRepo interface {
+	GetAllCampaigns(ctx context.Context, offset, limit int) ([]*entity.Campaign, error)
+	GetCampaignsWithIDs(ctx context.Context, IDs []int) ([]*entity.Campaign, error)
+	GetCampaignByID(ctx context.Context, id int) (*entity.Campaign, error)
+	CreateCampaign(ctx context.Context, campaign entity.Campaign) (*entity.Campaign, error)
+	UpdateCampaign(ctx context.Context, id int, updateModel entity.Campaign) error
+	DeleteCampaign(ctx context.Context, id int) error
+	ReserveImpressions(ctx context.Context, id, count int) (int, error)
+	ReleaseImpressions(ctx context.Context, counts map[int]int) error
+	CheckUniqueConstraints(ctx context.Context, id int, name string) error
+}
+
+// Service manages campaigns and counts impressions of their banners against budgets, impressions are reserved
+// from budgets in db by chunks of reservationSize, so budget is never overspent however many instances show banners
+// and showing campaign banner costs a query only once per chunk, reserved impressions not shown are returned
+// to budgets every flush interval
+type Service struct {
+	CampaignRepo CampaignRepo
+
+	flushInterval   time.Duration
+	reservationSize int
+	logger          *logrus.Logger
+
+	mu       sync.Mutex
+	reserved map[int]int // impressions reserved from budgets of campaigns and not shown yet
+}
+
+func New(campaignRepo CampaignRepo, flushInterval time.Duration, reservationSize int, logger *logrus.Logger) *Service {
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+
+	if reservationSize <= 0 {
+		reservationSize = DefaultReservationSize
+	}
+
+	return &Service{
+		CampaignRepo:    campaignRepo,
+		flushInterval:   flushInterval,
+		reservationSize: reservationSize,
+		logger:          logger,
+		reserved:        make(map[int]int),
+	}
+}
+
+func (s *Service) GetAllCampaigns(ctx context.Context, offset, limit int) ([]*entity.Campaign, error) {
+	campaigns, err := s.CampaignRepo.GetAllCampaigns(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.subtractReservedImpressions(campaigns)
+
+	return campaigns, nil
+}
+
+// GetCampaignsWithIDs returns campaigns with impressions reserved by the service but not yet shown subtracted,
+// so campaign is running while the service may show its banners
+func (s *Service) GetCampaignsWithIDs(ctx context.Context, IDs []int) ([]*entity.Campaign, error) {
+	campaigns, err := s.CampaignRepo.GetCampaignsWithIDs(ctx, IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.subtractReservedImpressions(campaigns)
+
+	return campaigns, nil
+}
+
+func (s *Service) GetCampaignByID(ctx context.Context, id int) (*entity.Campaign, error) {
+	campaign, err := s.CampaignRepo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.subtractReservedImpressions([]*entity.Campaign{campaign})
+
+	return campaign, nil
+}
+
+func (s *Service) CreateCampaign(ctx context.Context, campaign entity.Campaign) (*entity.Campaign, error) {
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.CampaignRepo.CheckUniqueConstraints(ctx, 0, campaign.Name); err != nil {
+		return nil, err
+	}
+
+	return s.CampaignRepo.CreateCampaign(ctx, campaign)
+}
+
+func (s *Service) UpdateCampaign(ctx context.Context, id int, updateModel entity.Campaign) error {
+	campaign, err := s.CampaignRepo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if updateModel.Name != "" {
+		if err = s.CampaignRepo.CheckUniqueConstraints(ctx, id, updateModel.Name); err != nil {
+			return err
+		}
+	}
+
+	// schedule and versions are validated as they will be after update
+	updated := *campaign
+
+	// zero dates remove schedule
+	if updateModel.StartsAt != nil {
+		updated.StartsAt = nilIfZero(updateModel.StartsAt)
+	}
+
+	if updateModel.EndsAt != nil {
+		updated.EndsAt = nilIfZero(updateModel.EndsAt)
+	}
+
+	if updateModel.MinAppVersion != "" {
+		updated.MinAppVersion = updateModel.MinAppVersion
+	}
+
+	if updateModel.MaxAppVersion != "" {
+		updated.MaxAppVersion = updateModel.MaxAppVersion
+	}
+
+	if err = validateCampaign(updated); err != nil {
+		return err
+	}
+
+	return s.CampaignRepo.UpdateCampaign(ctx, id, updateModel)
+}
+
+func (s *Service) DeleteCampaign(ctx context.Context, id int) error {
+	return s.CampaignRepo.DeleteCampaign(ctx, id)
+}
+
+func nilIfZero(t *time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+// validateCampaign checks if campaign ends after it starts and its app versions are valid
+func validateCampaign(campaign entity.Campaign) error {
+	if campaign.StartsAt != nil && campaign.EndsAt != nil && !campaign.EndsAt.After(*campaign.StartsAt) {
+		return ErrInvalidSchedule
+	}
+
+	for _, version := range []string{campaign.MinAppVersion, campaign.MaxAppVersion} {
+		if version != "" && !versionutils.IsValid(version) {
+			return ErrInvalidAppVersion
+		}
+	}
+
+	if campaign.MinAppVersion != "" && campaign.MaxAppVersion != "" {
+		if cmp, _ := versionutils.Compare(campaign.MinAppVersion, campaign.MaxAppVersion); cmp > 0 {
+			return ErrInvalidAppVersion
+		}
+	}
+
+	return nil
+}
+
+// ClaimImpressions counts one impression of banners of each of campaigns and reports whether budgets of all
+// of them allow it, nothing is counted if some budget is spent, so banners of spent campaigns are not shown
+func (s *Service) ClaimImpressions(ctx context.Context, campaignIDs []int) (bool, error) {
+	campaignIDs = sliceutils.Unique(campaignIDs)
+
+	s.mu.Lock()
+
+	var lacking []int
+
+	for _, id := range campaignIDs {
+		if s.reserved[id] == 0 {
+			lacking = append(lacking, id)
+		}
+	}
+
+	s.mu.Unlock()
+
+	for _, id := range lacking {
+		reserved, err := s.CampaignRepo.ReserveImpressions(ctx, id, s.reservationSize)
+		if err != nil {
+			return false, err
+		}
+
+		s.mu.Lock()
+		s.reserved[id] += reserved
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// reserved impressions may be claimed concurrently since they were checked
+	for _, id := range campaignIDs {
+		if s.reserved[id] == 0 {
+			return false, nil
+		}
+	}
+
+	for _, id := range campaignIDs {
+		s.reserved[id]--
+	}
+
+	return true, nil
+}
+
+func (s *Service) subtractReservedImpressions(campaigns []*entity.Campaign) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, campaign := range campaigns {
+		campaign.ImpressionsCount -= s.reserved[campaign.ID]
+	}
+}
+
+// Flush returns impressions reserved but not shown to budgets of campaigns, impressions stay reserved if they were
+// not returned
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	reserved := s.reserved
+	s.reserved = make(map[int]int)
+	s.mu.Unlock()
+
+	for id, count := range reserved {
+		if count == 0 {
+			delete(reserved, id)
+		}
+	}
+
+	if len(reserved) == 0 {
+		return nil
+	}
+
+	if err := s.CampaignRepo.ReleaseImpressions(ctx, reserved); err != nil {
+		s.mu.Lock()
+
+		for id, count := range reserved {
+			s.reserved[id] += count
+		}
+
+		s.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// Run returns not shown reserved impressions every flush interval until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				s.logger.Errorf("error occurred returning reserved campaign impressions: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package feature
+
+import "errors"
+
+var (
+	ErrInvalidSlug  = errors.New("slug must consist of lowercase latin letters and digits separated by '-'")
+	ErrInvalidLabel = errors.New("label must consist of latin letters, digits and symbols '_.:/=-'")
+)
@@ -0,0 +1,103 @@
+package feature
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	slugutils "avito-backend-trainee-2024/pkg/utils/slug"
+)
+
+type FeatureRepo interface {
+	GetAllFeatures(ctx context.Context, offset, limit int) ([]*entity.Feature, error)
+	GetFeatureByID(ctx context.Context, id int) (*entity.Feature, error)
+	CreateFeature(ctx context.Context, feature entity.Feature) (*entity.Feature, error)
+	UpdateFeature(ctx context.Context, id int, updateModel entity.Feature) error
+	DeleteFeature(ctx context.Context, id int) error
+	CheckUniqueConstraints(ctx context.Context, id int, slug string) error
+	SetFeatureEnabled(ctx context.Context, id int, enabled bool) error
+	GetDisabledFeatureIDs(ctx context.Context) ([]int, error)
+}
+
+type Service struct {
+	FeatureRepo FeatureRepo
+
+	// disabled features are kept in memory, so lookups are not slowed down by the kill switch check
+	mu       sync.RWMutex
+	disabled map[int]bool
+	loadedAt time.Time
+}
+
+func New(featureRepo FeatureRepo) *Service {
+	return &Service{
+		FeatureRepo: featureRepo,
+	}
+}
+
+func (s *Service) GetAllFeatures(ctx context.Context, offset, limit int) ([]*entity.Feature, error) {
+	return s.FeatureRepo.GetAllFeatures(ctx, offset, limit)
+}
+
+func (s *Service) GetFeatureByID(ctx context.Context, id int) (*entity.Feature, error) {
+	return s.FeatureRepo.GetFeatureByID(ctx, id)
+}
+
+// CreateFeature creates feature, slug is made of feature name if not provided
+func (s *Service) CreateFeature(ctx context.Context, feature entity.Feature) (*entity.Feature, error) {
+	if feature.Slug == "" {
+		feature.Slug = slugutils.Make(feature.Name)
+	}
+
+	if err := s.validateSlug(ctx, 0, feature.Slug); err != nil {
+		return nil, err
+	}
+
+	if err := validateLabels(feature.Labels); err != nil {
+		return nil, err
+	}
+
+	return s.FeatureRepo.CreateFeature(ctx, feature)
+}
+
+func (s *Service) UpdateFeature(ctx context.Context, id int, updateModel entity.Feature) error {
+	if updateModel.Slug != "" {
+		if err := s.validateSlug(ctx, id, updateModel.Slug); err != nil {
+			return err
+		}
+	}
+
+	if err := validateLabels(updateModel.Labels); err != nil {
+		return err
+	}
+
+	return s.FeatureRepo.UpdateFeature(ctx, id, updateModel)
+}
+
+// validateSlug checks format of slug and ensures it is not used by another feature
+func (s *Service) validateSlug(ctx context.Context, id int, slug string) error {
+	if !slugutils.IsValid(slug) {
+		return ErrInvalidSlug
+	}
+
+	return s.FeatureRepo.CheckUniqueConstraints(ctx, id, slug)
+}
+
+func (s *Service) DeleteFeature(ctx context.Context, id int) error {
+	return s.FeatureRepo.DeleteFeature(ctx, id)
+}
+
+// labelFormat allows labels like 'promo' or 'owner=growth'
+var labelFormat = regexp.MustCompile(`^[a-zA-Z0-9_.:/=-]+$`)
+
+func validateLabels(labels []string) error {
+	for _, label := range labels {
+		if !labelFormat.MatchString(label) {
+			return ErrInvalidLabel
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package feature
+
+import (
+	"context"
+	"time"
+)
+
+// disabledRefreshInterval is how often disabled features are reloaded from db,
+// so switches made by other instances of the service are picked up
+const disabledRefreshInterval = 5 * time.Second
+
+// SetFeatureEnabled turns feature on or off, disabled feature has no banners shown to users
+func (s *Service) SetFeatureEnabled(ctx context.Context, id int, enabled bool) error {
+	if err := s.FeatureRepo.SetFeatureEnabled(ctx, id, enabled); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disabled == nil {
+		// not loaded yet, the whole set is fetched on the next check
+		return nil
+	}
+
+	if enabled {
+		delete(s.disabled, id)
+	} else {
+		s.disabled[id] = true
+	}
+
+	return nil
+}
+
+// IsFeatureEnabled reports whether banners of the feature may be shown to users
+func (s *Service) IsFeatureEnabled(ctx context.Context, id int) (bool, error) {
+	s.mu.RLock()
+	loaded := s.disabled != nil && time.Since(s.loadedAt) < disabledRefreshInterval
+	disabled := s.disabled[id]
+	s.mu.RUnlock()
+
+	if loaded {
+		return !disabled, nil
+	}
+
+	if err := s.loadDisabledFeatures(ctx); err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return !s.disabled[id], nil
+}
+
+func (s *Service) loadDisabledFeatures(ctx context.Context) error {
+	IDs, err := s.FeatureRepo.GetDisabledFeatureIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	disabled := make(map[int]bool, len(IDs))
+
+	for _, id := range IDs {
+		disabled[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disabled = disabled
+	s.loadedAt = time.Now()
+
+	return nil
+}
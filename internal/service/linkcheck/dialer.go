@@ -0,0 +1,50 @@
+package linkcheck
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var errForbiddenAddress = errors.New("address is private, loopback or link-local")
+
+// newTransport returns transport which refuses to connect to private, loopback and link-local addresses unless they are
+// allowed, address is checked after host is resolved, so neither redirects nor DNS pointing inside the network pass
+func newTransport(allowPrivateAddrs bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if !allowPrivateAddrs {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+
+			if isForbiddenAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %v", errForbiddenAddress, addrPort.Addr())
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // proxy would connect to checked host instead of dialer
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
+
+func isForbiddenAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified()
+}
@@ -0,0 +1,48 @@
+package linkcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces requests to the same host by interval, so banners sharing host do not flood it
+type hostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// wait reserves the next slot of host and blocks until it comes or ctx is done
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+
+	now := time.Now()
+
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+
+	l.next[host] = at.Add(l.interval)
+
+	l.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
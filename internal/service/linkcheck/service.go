@@ -0,0 +1,272 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+const (
+	DefaultCheckInterval  = time.Hour
+	DefaultConcurrency    = 8
+	DefaultHostInterval   = time.Second
+	DefaultRequestTimeout = 10 * time.Second
+
+	// MaxRedirects is how many redirects are followed before url is considered broken
+	MaxRedirects = 10
+)
+
+var errTooManyRedirects = fmt.Errorf("stopped after %v redirects", MaxRedirects)
+
+type LinkCheckRepo interface {
+	GetBrokenLinks(ctx context.Context, offset, limit int) ([]*entity.LinkCheck, error)
+	SaveLinkChecks(ctx context.Context, checks []*entity.LinkCheck) error
+}
+
+type BannerRepo interface {
+	GetAllBanners(ctx context.Context, featureID, offset, limit int) ([]*entity.Banner, error)
+}
+
+// Service periodically requests urls of active banners and records whether they are reachable, so banners pointing
+// at missing pages are found before users do
+type Service struct {
+	LinkCheckRepo LinkCheckRepo
+	BannerRepo    BannerRepo
+
+	checkInterval  time.Duration
+	concurrency    int
+	hostInterval   time.Duration
+	requestTimeout time.Duration
+	transport      http.RoundTripper
+	logger         *logrus.Logger
+}
+
+// New creates service, urls resolved to private, loopback or link-local addresses are reported broken without being
+// requested unless allowPrivateAddrs is set, so banner urls can not be used to probe internal network
+func New(linkCheckRepo LinkCheckRepo, bannerRepo BannerRepo, checkInterval time.Duration, concurrency int,
+	hostInterval, requestTimeout time.Duration, allowPrivateAddrs bool, logger *logrus.Logger) *Service {
+	if checkInterval <= 0 {
+		checkInterval = DefaultCheckInterval
+	}
+
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	if hostInterval <= 0 {
+		hostInterval = DefaultHostInterval
+	}
+
+	if requestTimeout <= 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+
+	return &Service{
+		LinkCheckRepo:  linkCheckRepo,
+		BannerRepo:     bannerRepo,
+		checkInterval:  checkInterval,
+		concurrency:    concurrency,
+		hostInterval:   hostInterval,
+		requestTimeout: requestTimeout,
+		transport:      newTransport(allowPrivateAddrs),
+		logger:         logger,
+	}
+}
+
+func (s *Service) GetBrokenLinks(ctx context.Context, offset, limit int) ([]*entity.LinkCheck, error) {
+	return s.LinkCheckRepo.GetBrokenLinks(ctx, offset, limit)
+}
+
+// CheckLinks checks urls of all active banners, summary of the check is logged
+func (s *Service) CheckLinks(ctx context.Context) error {
+	banners, err := s.BannerRepo.GetAllBanners(ctx, 0, 0, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	var active []*entity.Banner
+
+	for _, banner := range banners {
+		if banner.IsActive {
+			active = append(active, banner)
+		}
+	}
+
+	checks, err := s.CheckBanners(ctx, active)
+	if err != nil {
+		return err
+	}
+
+	var broken []int
+
+	for _, check := range checks {
+		if check.IsBroken {
+			broken = append(broken, check.BannerID)
+		}
+	}
+
+	s.logger.WithField("broken_ids", broken).Infof("banner link check done: %v banners checked, %v broken links found",
+		len(checks), len(broken))
+
+	return nil
+}
+
+// CheckBanners checks urls of the banners and saves the checks, url shared by several banners is requested once
+func (s *Service) CheckBanners(ctx context.Context, banners []*entity.Banner) ([]*entity.LinkCheck, error) {
+	bannerIDsByUrl := make(map[string][]int)
+
+	for _, banner := range banners {
+		bannerIDsByUrl[banner.Content.Url] = append(bannerIDsByUrl[banner.Content.Url], banner.ID)
+	}
+
+	limiter := newHostLimiter(s.hostInterval)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		checks []*entity.LinkCheck
+	)
+
+	sem := make(chan struct{}, s.concurrency)
+
+	for rawUrl, bannerIDs := range bannerIDsByUrl {
+		wg.Add(1)
+
+		go func(rawUrl string, bannerIDs []int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			check := s.checkUrl(ctx, limiter, rawUrl)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, id := range bannerIDs {
+				bannerCheck := *check
+				bannerCheck.BannerID = id
+
+				checks = append(checks, &bannerCheck)
+			}
+		}(rawUrl, bannerIDs)
+	}
+
+	wg.Wait()
+
+	// checks interrupted by shutdown are not saved, so urls are not reported broken for no reason
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.LinkCheckRepo.SaveLinkChecks(ctx, checks); err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+// checkUrl requests url with HEAD falling back to GET for servers not supporting HEAD, url is broken if it can not be
+// reached or responds with error status
+func (s *Service) checkUrl(ctx context.Context, limiter *hostLimiter, rawUrl string) *entity.LinkCheck {
+	check := &entity.LinkCheck{
+		Url:       rawUrl,
+		Redirects: []string{},
+	}
+
+	defer func() {
+		check.IsBroken = check.Error != "" || check.StatusCode >= http.StatusBadRequest
+		check.CheckedAt = time.Now()
+	}()
+
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		if err = limiter.wait(ctx, parsed.Host); err != nil {
+			check.Error = err.Error()
+			return check
+		}
+
+		check.StatusCode, check.Redirects, err = s.request(ctx, limiter, method, rawUrl)
+		if err != nil {
+			check.Error = err.Error()
+			return check
+		}
+
+		if check.StatusCode != http.StatusMethodNotAllowed && check.StatusCode != http.StatusNotImplemented {
+			break
+		}
+	}
+
+	return check
+}
+
+// request returns status code of the final response and urls request was redirected through, each redirect waits
+// for its host the same way as the first request does
+func (s *Service) request(ctx context.Context, limiter *hostLimiter, method, rawUrl string) (int, []string, error) {
+	redirects := []string{}
+
+	client := &http.Client{
+		Transport: s.transport,
+		Timeout:   s.requestTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > MaxRedirects {
+				return errTooManyRedirects
+			}
+
+			redirects = append(redirects, req.URL.String())
+
+			return limiter.wait(req.Context(), req.URL.Host)
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, nil)
+	if err != nil {
+		return 0, redirects, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return 0, redirects, err
+	}
+
+	_ = resp.Body.Close()
+
+	return resp.StatusCode, redirects, nil
+}
+
+// Run checks links at once and then every check interval until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.CheckLinks(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			s.logger.Errorf("error occurred checking banner links: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package miss
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+const (
+	DefaultFlushInterval = time.Minute
+	DefaultRetention     = 90 * 24 * time.Hour
+	DefaultMaxPending    = 10_000
+)
+
+type MissRepo interface {
+	GetMisses(ctx context.Context, featureID int, pending []*entity.BannerMiss, offset, limit int) ([]*entity.BannerMiss, error)
+	SaveMisses(ctx context.Context, misses []*entity.BannerMiss) error
+	DeleteMisses(ctx context.Context, lastMissedBefore time.Time) (int, error)
+}
+
+// Service counts lookups with no banner found in memory and periodically persists counters,
+// so recording a miss does not cost a query on the lookup path, at most maxPending combinations are counted
+// between flushes and persisted combinations not missed for retention are deleted
+type Service struct {
+	MissRepo MissRepo
+
+	flushInterval time.Duration
+	retention     time.Duration
+	maxPending    int
+	logger        *logrus.Logger
+
+	mu      sync.Mutex
+	pending map[string]*entity.BannerMiss
+	dropped int // misses of new combinations not counted since pending combinations reached maxPending
+}
+
+func New(missRepo MissRepo, flushInterval, retention time.Duration, maxPending int, logger *logrus.Logger) *Service {
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+
+	if maxPending <= 0 {
+		maxPending = DefaultMaxPending
+	}
+
+	return &Service{
+		MissRepo:      missRepo,
+		flushInterval: flushInterval,
+		retention:     retention,
+		maxPending:    maxPending,
+		logger:        logger,
+		pending:       make(map[string]*entity.BannerMiss),
+	}
+}
+
+// RecordMiss counts miss of the feature and tags, tags are deduplicated and sorted, so the same combination
+// is counted once however it is requested, miss of new combination is dropped if maxPending combinations are counted
+func (s *Service) RecordMiss(featureID int, tagIDs []int) {
+	tagIDs = sliceutils.Unique(tagIDs)
+	slices.Sort(tagIDs)
+
+	key := fmt.Sprintf("%v#%v", featureID, tagIDs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	miss, exists := s.pending[key]
+	if !exists {
+		if len(s.pending) >= s.maxPending {
+			s.dropped++
+			return
+		}
+
+		miss = &entity.BannerMiss{
+			FeatureID: featureID,
+			TagIDs:    tagIDs,
+		}
+
+		s.pending[key] = miss
+	}
+
+	miss.MissesCount++
+	miss.LastMissedAt = time.Now()
+}
+
+// GetMisses returns persisted misses of the feature together with not yet persisted ones sorted by their count,
+// misses of all features are returned if featureID is 0
+func (s *Service) GetMisses(ctx context.Context, featureID, offset, limit int) ([]*entity.BannerMiss, error) {
+	var pending []*entity.BannerMiss
+
+	s.mu.Lock()
+
+	for _, miss := range s.pending {
+		if featureID == 0 || miss.FeatureID == featureID {
+			copied := *miss
+
+			pending = append(pending, &copied)
+		}
+	}
+
+	s.mu.Unlock()
+
+	return s.MissRepo.GetMisses(ctx, featureID, pending, offset, limit)
+}
+
+// Flush persists counted misses, misses are counted again if they were not persisted,
+// misses of unknown features and tags are not persisted
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending, dropped := s.pending, s.dropped
+	s.pending, s.dropped = make(map[string]*entity.BannerMiss), 0
+	s.mu.Unlock()
+
+	if dropped != 0 {
+		s.logger.Warnf("%v banner misses of new combinations were dropped as %v combinations were counted", dropped, s.maxPending)
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	misses := make([]*entity.BannerMiss, 0, len(pending))
+
+	for _, miss := range pending {
+		misses = append(misses, miss)
+	}
+
+	if err := s.MissRepo.SaveMisses(ctx, misses); err != nil {
+		for _, miss := range misses {
+			s.restore(miss)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Prune deletes persisted misses of combinations not missed for retention period and returns their count
+func (s *Service) Prune(ctx context.Context) (int, error) {
+	return s.MissRepo.DeleteMisses(ctx, time.Now().Add(-s.retention))
+}
+
+// restore returns not persisted miss to pending ones unless maxPending combinations are already counted
+func (s *Service) restore(miss *entity.BannerMiss) {
+	key := fmt.Sprintf("%v#%v", miss.FeatureID, miss.TagIDs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, exists := s.pending[key]
+	if !exists {
+		if len(s.pending) >= s.maxPending {
+			s.dropped += miss.MissesCount
+			return
+		}
+
+		s.pending[key] = miss
+		return
+	}
+
+	pending.MissesCount += miss.MissesCount
+
+	if miss.LastMissedAt.After(pending.LastMissedAt) {
+		pending.LastMissedAt = miss.LastMissedAt
+	}
+}
+
+// Run flushes counted misses and prunes outdated ones every flush interval until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				s.logger.Errorf("error occurred persisting banner misses: %v", err)
+			}
+
+			if _, err := s.Prune(ctx); err != nil {
+				s.logger.Errorf("error occurred pruning banner misses: %v", err)
+			}
+		}
+	}
+}
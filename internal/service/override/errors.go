@@ -0,0 +1,8 @@
+package override
+
+import "errors"
+
+var (
+	ErrNoSuchBanner   = errors.New("no such banner")
+	ErrAlreadyExpired = errors.New("override expiration time must be in the future")
+)
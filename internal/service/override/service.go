@@ -0,0 +1,54 @@
+package override
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+type OverrideRepo interface {
+	GetAllOverrides(ctx context.Context, offset, limit int) ([]*entity.Override, error)
+	CreateOverrides(ctx context.Context, bannerID int, userIDs []int, expiresAt time.Time) ([]*entity.Override, error)
+	DeleteOverride(ctx context.Context, id int) error
+}
+
+type BannerRepo interface {
+	GetBannerByID(ctx context.Context, id int) (*entity.Banner, error)
+}
+
+type Service struct {
+	OverrideRepo OverrideRepo
+	BannerRepo   BannerRepo
+}
+
+func New(overrideRepo OverrideRepo, bannerRepo BannerRepo) *Service {
+	return &Service{
+		OverrideRepo: overrideRepo,
+		BannerRepo:   bannerRepo,
+	}
+}
+
+func (s *Service) GetAllOverrides(ctx context.Context, offset, limit int) ([]*entity.Override, error) {
+	return s.OverrideRepo.GetAllOverrides(ctx, offset, limit)
+}
+
+// CreateOverrides pins banner to users until expiresAt, banner may be inactive
+func (s *Service) CreateOverrides(ctx context.Context, bannerID int, userIDs []int, expiresAt time.Time) ([]*entity.Override, error) {
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrAlreadyExpired
+	}
+
+	if _, err := s.BannerRepo.GetBannerByID(ctx, bannerID); err != nil {
+		return nil, errors.Join(ErrNoSuchBanner, err)
+	}
+
+	return s.OverrideRepo.CreateOverrides(ctx, bannerID, sliceutils.Unique(userIDs), expiresAt)
+}
+
+func (s *Service) DeleteOverride(ctx context.Context, id int) error {
+	return s.OverrideRepo.DeleteOverride(ctx, id)
+}
@@ -0,0 +1,7 @@
+package schedule
+
+import "errors"
+
+var (
+	ErrApplyAtInPast = errors.New("scheduled edit must be applied in the future")
+)
@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+const (
+	DefaultApplyInterval = 30 * time.Second
+
+	// MaxApplyAttempts is how many times edit is tried to be applied before it is marked as failed,
+	// so edit broken by changes made after it was scheduled is not retried forever
+	MaxApplyAttempts = 5
+)
+
+type ScheduleRepo interface {
+	GetScheduledEdits(ctx context.Context, bannerID, offset, limit int) ([]*entity.ScheduledEdit, error)
+	GetDueEdits(ctx context.Context, now time.Time) ([]*entity.ScheduledEdit, error)
+	CreateScheduledEdit(ctx context.Context, edit entity.ScheduledEdit) (*entity.ScheduledEdit, error)
+	ApplyEdit(ctx context.Context, id int, validate func(ctx context.Context, bannerID int, updateModel entity.Banner) error,
+		maxAttempts int) (*entity.ScheduledEdit, error)
+	DeleteScheduledEdit(ctx context.Context, id int) error
+}
+
+type BannerRepo interface {
+	GetBannerByID(ctx context.Context, id int) (*entity.Banner, error)
+}
+
+// BannerUpdater validates edits the same way banners updated by admins are validated
+type BannerUpdater interface {
+	ValidateBannerUpdate(ctx context.Context, id int, updateModel entity.Banner) error
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+}
+
+// Service keeps edits of banners scheduled for later in db and applies them once their time has come,
+// edits due while service was down are applied on start
+type Service struct {
+	ScheduleRepo  ScheduleRepo
+	BannerRepo    BannerRepo
+	BannerUpdater BannerUpdater
+
+	applyInterval time.Duration
+	logger        *logrus.Logger
+}
+
+func New(scheduleRepo ScheduleRepo, bannerRepo BannerRepo, bannerUpdater BannerUpdater, applyInterval time.Duration,
+	logger *logrus.Logger) *Service {
+	if applyInterval <= 0 {
+		applyInterval = DefaultApplyInterval
+	}
+
+	return &Service{
+		ScheduleRepo:  scheduleRepo,
+		BannerRepo:    bannerRepo,
+		BannerUpdater: bannerUpdater,
+		applyInterval: applyInterval,
+		logger:        logger,
+	}
+}
+
+// GetScheduledEdits returns not applied edits of the banner, edits of all banners are returned if bannerID is 0
+func (s *Service) GetScheduledEdits(ctx context.Context, bannerID, offset, limit int) ([]*entity.ScheduledEdit, error) {
+	return s.ScheduleRepo.GetScheduledEdits(ctx, bannerID, offset, limit)
+}
+
+// ScheduleEdit schedules edit of existing banner, edit is validated at once and once again when it is applied
+func (s *Service) ScheduleEdit(ctx context.Context, edit entity.ScheduledEdit) (*entity.ScheduledEdit, error) {
+	if !edit.ApplyAt.After(time.Now()) {
+		return nil, ErrApplyAtInPast
+	}
+
+	if _, err := s.BannerRepo.GetBannerByID(ctx, edit.BannerID); err != nil {
+		return nil, err
+	}
+
+	if err := s.BannerUpdater.ValidateBannerUpdate(ctx, edit.BannerID, edit.Patch); err != nil {
+		return nil, err
+	}
+
+	return s.ScheduleRepo.CreateScheduledEdit(ctx, edit)
+}
+
+func (s *Service) CancelScheduledEdit(ctx context.Context, id int) error {
+	return s.ScheduleRepo.DeleteScheduledEdit(ctx, id)
+}
+
+// GetFeatureIDBySlug returns id of feature with slug, so edits can refer features by slugs instead of ids
+func (s *Service) GetFeatureIDBySlug(ctx context.Context, slug string) (int, error) {
+	return s.BannerUpdater.GetFeatureIDBySlug(ctx, slug)
+}
+
+// GetTagIDsBySlugs returns ids of tags with slugs in the same order
+func (s *Service) GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error) {
+	return s.BannerUpdater.GetTagIDsBySlugs(ctx, slugs)
+}
+
+// ApplyDueEdits applies pending edits which time has come in the order they were scheduled, edit failed to apply
+// is retried on the next run until MaxApplyAttempts are reached, edits being applied by other instance are skipped
+func (s *Service) ApplyDueEdits(ctx context.Context) error {
+	edits, err := s.ScheduleRepo.GetDueEdits(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, due := range edits {
+		edit, err := s.ScheduleRepo.ApplyEdit(ctx, due.ID, s.BannerUpdater.ValidateBannerUpdate, MaxApplyAttempts)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case edit == nil:
+			continue
+		case edit.Status == entity.ScheduledEditApplied:
+			s.logger.Infof("scheduled edit %v of banner %v applied", edit.ID, edit.BannerID)
+		default:
+			s.logger.Errorf("error occurred applying scheduled edit %v of banner %v: %v", edit.ID, edit.BannerID, edit.LastError)
+		}
+	}
+
+	return nil
+}
+
+// Run applies due edits at once and then every apply interval until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.applyInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ApplyDueEdits(ctx); err != nil {
+			s.logger.Errorf("error occurred applying scheduled edits: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
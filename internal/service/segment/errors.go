@@ -0,0 +1,7 @@
+package segment
+
+import "errors"
+
+var (
+	ErrEmptySegment = errors.New("segment must contain at least one user")
+)
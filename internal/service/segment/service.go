@@ -0,0 +1,49 @@
+package segment
+
+import (
+	"context"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	sliceutils "avito-backend-trainee-2024/pkg/utils/slice"
+)
+
+type SegmentRepo interface {
+	GetAllSegments(ctx context.Context, offset, limit int) ([]*entity.Segment, error)
+	CreateSegment(ctx context.Context, segment entity.Segment, userIDs []int) (*entity.Segment, error)
+	DeleteSegment(ctx context.Context, id int) error
+	CheckUniqueConstraints(ctx context.Context, name string) error
+}
+
+type Service struct {
+	SegmentRepo SegmentRepo
+}
+
+func New(segmentRepo SegmentRepo) *Service {
+	return &Service{
+		SegmentRepo: segmentRepo,
+	}
+}
+
+func (s *Service) GetAllSegments(ctx context.Context, offset, limit int) ([]*entity.Segment, error) {
+	return s.SegmentRepo.GetAllSegments(ctx, offset, limit)
+}
+
+func (s *Service) CreateSegment(ctx context.Context, segment entity.Segment, userIDs []int) (*entity.Segment, error) {
+	userIDs = sliceutils.Unique(userIDs) // CRM lists often contain duplicates
+
+	if len(userIDs) == 0 {
+		return nil, ErrEmptySegment
+	}
+
+	// ensure that segment with this name does not exist
+	if err := s.SegmentRepo.CheckUniqueConstraints(ctx, segment.Name); err != nil {
+		return nil, err
+	}
+
+	return s.SegmentRepo.CreateSegment(ctx, segment, userIDs)
+}
+
+func (s *Service) DeleteSegment(ctx context.Context, id int) error {
+	return s.SegmentRepo.DeleteSegment(ctx, id)
+}
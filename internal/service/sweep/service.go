@@ -0,0 +1,83 @@
+package sweep
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+const (
+	DefaultSweepInterval = time.Hour
+	DefaultArchiveAfter  = 90 * 24 * time.Hour
+)
+
+type BannerRepo interface {
+	SweepBanners(ctx context.Context, now, untouchedSince time.Time) (*entity.BannerSweep, error)
+}
+
+// Service periodically deactivates expired banners and archives inactive banners untouched for archive period,
+// so they do not clutter banners listed to admins
+type Service struct {
+	BannerRepo BannerRepo
+
+	sweepInterval time.Duration
+	archiveAfter  time.Duration
+	logger        *logrus.Logger
+}
+
+func New(bannerRepo BannerRepo, sweepInterval, archiveAfter time.Duration, logger *logrus.Logger) *Service {
+	if sweepInterval <= 0 {
+		sweepInterval = DefaultSweepInterval
+	}
+
+	if archiveAfter <= 0 {
+		archiveAfter = DefaultArchiveAfter
+	}
+
+	return &Service{
+		BannerRepo:    bannerRepo,
+		sweepInterval: sweepInterval,
+		archiveAfter:  archiveAfter,
+		logger:        logger,
+	}
+}
+
+// Sweep deactivates banners ended by now and archives inactive banners untouched for archive period,
+// summary of the sweep is logged
+func (s *Service) Sweep(ctx context.Context) (*entity.BannerSweep, error) {
+	now := time.Now()
+
+	sweep, err := s.BannerRepo.SweepBanners(ctx, now, now.Add(-s.archiveAfter))
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"deactivated_ids": sweep.DeactivatedIDs,
+		"archived_ids":    sweep.ArchivedIDs,
+	}).Infof("banner sweep done: %v expired banners deactivated, %v untouched banners archived",
+		len(sweep.DeactivatedIDs), len(sweep.ArchivedIDs))
+
+	return sweep, nil
+}
+
+// Run sweeps banners at once and then every sweep interval until ctx is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil {
+			s.logger.Errorf("error occurred sweeping banners: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
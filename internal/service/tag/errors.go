@@ -0,0 +1,13 @@
+package tag
+
+import "errors"
+
+var (
+	ErrNoSuchParentTag = errors.New("no such parent tag")
+	ErrTagCycle        = errors.New("tag can not be a descendant of itself")
+	ErrTagIsAlias      = errors.New("tag is an alias of another tag")
+	ErrInvalidSlug     = errors.New("slug must consist of lowercase latin letters and digits separated by '-'")
+
+	ErrMergeIntoItself     = errors.New("tag can not be merged into itself")
+	ErrMergeIntoDescendant = errors.New("tag can not be merged into its descendant")
+)
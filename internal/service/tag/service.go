@@ -0,0 +1,138 @@
+package tag
+
+import (
+	"context"
+	"slices"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+
+	slugutils "avito-backend-trainee-2024/pkg/utils/slug"
+)
+
+type TagRepo interface {
+	GetAllTags(ctx context.Context, name string, offset, limit int) ([]*entity.Tag, error)
+	GetTagByID(ctx context.Context, id int) (*entity.Tag, error)
+	CreateTag(ctx context.Context, tag entity.Tag) (*entity.Tag, error)
+	UpdateTag(ctx context.Context, id int, updateModel entity.Tag) error
+	DeleteTag(ctx context.Context, id int, detach bool) error
+	GetTagAncestors(ctx context.Context, IDs []int) (map[int][]int, error)
+	MergeTags(ctx context.Context, sourceID, targetID int, keepAlias bool, username string) (*entity.TagMerge, error)
+	CheckUniqueConstraints(ctx context.Context, id int, slug string) error
+}
+
+type Service struct {
+	TagRepo TagRepo
+}
+
+func New(tagRepo TagRepo) *Service {
+	return &Service{
+		TagRepo: tagRepo,
+	}
+}
+
+func (s *Service) GetAllTags(ctx context.Context, name string, offset, limit int) ([]*entity.Tag, error) {
+	return s.TagRepo.GetAllTags(ctx, name, offset, limit)
+}
+
+func (s *Service) GetTagByID(ctx context.Context, id int) (*entity.Tag, error) {
+	return s.TagRepo.GetTagByID(ctx, id)
+}
+
+// CreateTag creates tag, slug is made of tag name if not provided
+func (s *Service) CreateTag(ctx context.Context, tag entity.Tag) (*entity.Tag, error) {
+	if tag.Slug == "" {
+		tag.Slug = slugutils.Make(tag.Name)
+	}
+
+	if err := s.validateSlug(ctx, 0, tag.Slug); err != nil {
+		return nil, err
+	}
+
+	if tag.ParentID != nil {
+		if err := s.validateParent(ctx, 0, *tag.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.TagRepo.CreateTag(ctx, tag)
+}
+
+// UpdateTag updates tag, nil parent id leaves parent unchanged, zero one makes tag a root of hierarchy
+func (s *Service) UpdateTag(ctx context.Context, id int, updateModel entity.Tag) error {
+	if updateModel.Slug != "" {
+		if err := s.validateSlug(ctx, id, updateModel.Slug); err != nil {
+			return err
+		}
+	}
+
+	if updateModel.ParentID != nil && *updateModel.ParentID != 0 {
+		if err := s.validateParent(ctx, id, *updateModel.ParentID); err != nil {
+			return err
+		}
+	}
+
+	return s.TagRepo.UpdateTag(ctx, id, updateModel)
+}
+
+// validateSlug checks format of slug and ensures it is not used by another tag
+func (s *Service) validateSlug(ctx context.Context, id int, slug string) error {
+	if !slugutils.IsValid(slug) {
+		return ErrInvalidSlug
+	}
+
+	return s.TagRepo.CheckUniqueConstraints(ctx, id, slug)
+}
+
+// validateParent checks if parent tag exists and tag with id is not among its ancestors, so hierarchy stays acyclic
+func (s *Service) validateParent(ctx context.Context, id, parentID int) error {
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, []int{parentID})
+	if err != nil {
+		return err
+	}
+
+	chain, exists := ancestors[parentID]
+	if !exists {
+		return ErrNoSuchParentTag
+	}
+
+	// chain of alias starts with the tag it was merged into
+	if chain[0] != parentID {
+		return ErrTagIsAlias
+	}
+
+	if slices.Contains(chain, id) {
+		return ErrTagCycle
+	}
+
+	return nil
+}
+
+// DeleteTag deletes tag, if detach is true tag is removed from banners using it instead of refusing deletion
+func (s *Service) DeleteTag(ctx context.Context, id int, detach bool) error {
+	return s.TagRepo.DeleteTag(ctx, id, detach)
+}
+
+// MergeTags merges source tag into target one on behalf of admin with username,
+// source tag can not be merged into its descendant as hierarchy would become cyclic
+func (s *Service) MergeTags(ctx context.Context, sourceID, targetID int, keepAlias bool, username string) (*entity.TagMerge, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeIntoItself
+	}
+
+	ancestors, err := s.TagRepo.GetTagAncestors(ctx, []int{targetID})
+	if err != nil {
+		return nil, err
+	}
+
+	if chain, exists := ancestors[targetID]; exists {
+		if chain[0] != targetID {
+			return nil, ErrTagIsAlias
+		}
+
+		if slices.Contains(chain, sourceID) {
+			return nil, ErrMergeIntoDescendant
+		}
+	}
+
+	return s.TagRepo.MergeTags(ctx, sourceID, targetID, keepAlias, username)
+}
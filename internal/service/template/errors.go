@@ -0,0 +1,10 @@
+package template
+
+import "errors"
+
+var (
+	ErrInvalidPlaceholder   = errors.New("invalid placeholder, placeholder is name of letters, digits and underscores in {{ }}")
+	ErrPlaceholderNotFilled = errors.New("placeholder is not filled")
+	ErrUnknownPlaceholder   = errors.New("template has no such placeholder")
+	ErrInvalidUrl           = errors.New("url of banner created from template is invalid")
+)
@@ -0,0 +1,51 @@
+package template
+
+import (
+	"regexp"
+	"strings"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+var placeholderRegexp = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+
+// parsePlaceholders returns names of placeholders in content in order they first appear, braces left after
+// placeholders are removed mean placeholder is malformed
+func parsePlaceholders(content entity.Content) ([]string, error) {
+	var names []string
+
+	seen := make(map[string]struct{})
+
+	for _, field := range []string{content.Title, content.Text, content.Url} {
+		rest := placeholderRegexp.ReplaceAllString(field, "")
+		if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+			return nil, ErrInvalidPlaceholder
+		}
+
+		for _, match := range placeholderRegexp.FindAllStringSubmatch(field, -1) {
+			if _, ok := seen[match[1]]; ok {
+				continue
+			}
+
+			seen[match[1]] = struct{}{}
+			names = append(names, match[1])
+		}
+	}
+
+	return names, nil
+}
+
+// render replaces placeholders in content with values, all placeholders must be filled
+func render(content entity.Content, values map[string]string) entity.Content {
+	replace := func(field string) string {
+		return placeholderRegexp.ReplaceAllStringFunc(field, func(placeholder string) string {
+			return values[placeholderRegexp.FindStringSubmatch(placeholder)[1]]
+		})
+	}
+
+	return entity.Content{
+		Title: replace(content.Title),
+		Text:  replace(content.Text),
+		Url:   replace(content.Url),
+	}
+}
@@ -0,0 +1,146 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+type TemplateRepo interface {
+	GetAllTemplates(ctx context.Context, offset, limit int) ([]*entity.BannerTemplate, error)
+	GetTemplateByID(ctx context.Context, id int) (*entity.BannerTemplate, error)
+	CreateTemplate(ctx context.Context, template entity.BannerTemplate) (*entity.BannerTemplate, error)
+	UpdateTemplate(ctx context.Context, id int, updateModel entity.BannerTemplate) error
+	DeleteTemplate(ctx context.Context, id int) error
+	CheckUniqueConstraints(ctx context.Context, id int, name string) error
+}
+
+// BannerCreator creates banners from templates the same way banners are created by admins
+type BannerCreator interface {
+	CreateBanner(ctx context.Context, banner entity.Banner) (*entity.Banner, error)
+	GetFeatureIDBySlug(ctx context.Context, slug string) (int, error)
+	GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error)
+}
+
+// Service manages banner templates and creates banners from them filling placeholders of template content
+type Service struct {
+	TemplateRepo  TemplateRepo
+	BannerCreator BannerCreator
+}
+
+func New(templateRepo TemplateRepo, bannerCreator BannerCreator) *Service {
+	return &Service{
+		TemplateRepo:  templateRepo,
+		BannerCreator: bannerCreator,
+	}
+}
+
+func (s *Service) GetAllTemplates(ctx context.Context, offset, limit int) ([]*entity.BannerTemplate, error) {
+	templates, err := s.TemplateRepo.GetAllTemplates(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		// templates are validated before they are saved
+		template.Placeholders, _ = parsePlaceholders(template.Content)
+	}
+
+	return templates, nil
+}
+
+func (s *Service) GetTemplateByID(ctx context.Context, id int) (*entity.BannerTemplate, error) {
+	template, err := s.TemplateRepo.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Placeholders, _ = parsePlaceholders(template.Content)
+
+	return template, nil
+}
+
+func (s *Service) CreateTemplate(ctx context.Context, template entity.BannerTemplate) (*entity.BannerTemplate, error) {
+	placeholders, err := parsePlaceholders(template.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.TemplateRepo.CheckUniqueConstraints(ctx, 0, template.Name); err != nil {
+		return nil, err
+	}
+
+	created, err := s.TemplateRepo.CreateTemplate(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Placeholders = placeholders
+
+	return created, nil
+}
+
+func (s *Service) UpdateTemplate(ctx context.Context, id int, updateModel entity.BannerTemplate) error {
+	if _, err := parsePlaceholders(updateModel.Content); err != nil {
+		return err
+	}
+
+	if updateModel.Name != "" {
+		if err := s.TemplateRepo.CheckUniqueConstraints(ctx, id, updateModel.Name); err != nil {
+			return err
+		}
+	}
+
+	return s.TemplateRepo.UpdateTemplate(ctx, id, updateModel)
+}
+
+func (s *Service) DeleteTemplate(ctx context.Context, id int) error {
+	return s.TemplateRepo.DeleteTemplate(ctx, id)
+}
+
+// InstantiateTemplate creates banner with content of the template, its placeholders are filled with values,
+// each placeholder must be filled with not blank value and each value must fill some placeholder
+func (s *Service) InstantiateTemplate(ctx context.Context, id int, values map[string]string, banner entity.Banner) (*entity.Banner, error) {
+	template, err := s.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholders := make(map[string]struct{}, len(template.Placeholders))
+
+	for _, name := range template.Placeholders {
+		if value, ok := values[name]; !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("%w: %v", ErrPlaceholderNotFilled, name)
+		}
+
+		placeholders[name] = struct{}{}
+	}
+
+	for name := range values {
+		if _, ok := placeholders[name]; !ok {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownPlaceholder, name)
+		}
+	}
+
+	banner.Content = render(template.Content, values)
+
+	// url of template is checked once it is rendered, since placeholders may be anywhere in it
+	if parsed, err := url.ParseRequestURI(banner.Content.Url); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, ErrInvalidUrl
+	}
+
+	return s.BannerCreator.CreateBanner(ctx, banner)
+}
+
+// GetFeatureIDBySlug returns id of feature with slug, so banners may be created from templates referring features by slugs
+func (s *Service) GetFeatureIDBySlug(ctx context.Context, slug string) (int, error) {
+	return s.BannerCreator.GetFeatureIDBySlug(ctx, slug)
+}
+
+// GetTagIDsBySlugs returns ids of tags with slugs in the same order
+func (s *Service) GetTagIDsBySlugs(ctx context.Context, slugs []string) ([]int, error) {
+	return s.BannerCreator.GetTagIDsBySlugs(ctx, slugs)
+}
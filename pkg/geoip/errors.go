@@ -0,0 +1,7 @@
+package geoip
+
+import "errors"
+
+var (
+	ErrLocationNotFound = errors.New("location not found")
+)
@@ -0,0 +1,156 @@
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadDelay is time to wait after last change of database file before reloading it,
+// so file being copied is not read partially
+const reloadDelay = time.Second
+
+type Location struct {
+	Country string // ISO 3166-1 country code, e.g. RU
+	Region  string // ISO 3166-2 subdivision code, e.g. RU-MOW
+}
+
+// record is a subset of fields of both GeoIP2/GeoLite2 Country and City databases
+type record struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Resolver resolves location of ip using MaxMind-format database file, file is reloaded on change
+type Resolver struct {
+	path    string
+	watcher *fsnotify.Watcher
+	logger  *logrus.Logger
+
+	mu     sync.RWMutex
+	reader *maxminddb.Reader
+}
+
+func New(path string, logger *logrus.Logger) (*Resolver, error) {
+	r := &Resolver{
+		path:   filepath.Clean(path),
+		logger: logger,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// watch directory instead of file, because files are usually replaced by rename
+	if err = watcher.Add(filepath.Dir(r.path)); err != nil {
+		_ = watcher.Close()
+
+		return nil, err
+	}
+
+	r.watcher = watcher
+
+	go r.watch()
+
+	return r, nil
+}
+
+// load reads database into memory, so database file may be safely overwritten while in use
+func (r *Resolver) load() error {
+	buf, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	reader, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.reader = reader
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *Resolver) watch() {
+	var timer *time.Timer
+
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if filepath.Clean(event.Name) != r.path || !event.Has(fsnotify.Create|fsnotify.Write) {
+				continue
+			}
+
+			if timer != nil {
+				timer.Stop()
+			}
+
+			timer = time.AfterFunc(reloadDelay, func() {
+				if err := r.load(); err != nil {
+					r.logger.Errorf("error occurred reloading geoip database %v: %v", r.path, err)
+					return
+				}
+
+				r.logger.Infof("geoip database %v reloaded", r.path)
+			})
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			r.logger.Errorf("error occurred watching geoip database %v: %v", r.path, err)
+		}
+	}
+}
+
+func (r *Resolver) Lookup(ip net.IP) (Location, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rec record
+
+	_, found, err := r.reader.LookupNetwork(ip, &rec)
+	if err != nil {
+		return Location{}, err
+	}
+
+	if !found || rec.Country.IsoCode == "" {
+		return Location{}, ErrLocationNotFound
+	}
+
+	location := Location{
+		Country: rec.Country.IsoCode,
+	}
+
+	if len(rec.Subdivisions) != 0 && rec.Subdivisions[0].IsoCode != "" {
+		location.Region = rec.Country.IsoCode + "-" + rec.Subdivisions[0].IsoCode
+	}
+
+	return location, nil
+}
+
+func (r *Resolver) Close() error {
+	return r.watcher.Close()
+}
@@ -0,0 +1,7 @@
+package local
+
+import "errors"
+
+var (
+	ErrInvalidKey = errors.New("invalid object key")
+)
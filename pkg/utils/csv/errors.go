@@ -0,0 +1,7 @@
+package csv
+
+import "errors"
+
+var (
+	ErrInvalidValue = errors.New("invalid value")
+)
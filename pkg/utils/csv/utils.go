@@ -0,0 +1,47 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadIntColumn returns ints from the first column of csv, first row is skipped if it is a header
+func ReadIntColumn(r io.Reader) ([]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // rows may contain additional columns
+
+	var res []int
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		valStr := strings.TrimSpace(record[0])
+		if valStr == "" {
+			continue
+		}
+
+		val, err := strconv.Atoi(valStr)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+
+			return nil, errors.Join(ErrInvalidValue, fmt.Errorf("line %v: %w", line, err))
+		}
+
+		res = append(res, val)
+	}
+
+	return res, nil
+}
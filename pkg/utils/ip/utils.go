@@ -0,0 +1,72 @@
+package ip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseNetworks parses CIDRs or single ips of format '10.0.0.0/8', '127.0.0.1'
+func ParseNetworks(networks []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(networks))
+
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			if ip := net.ParseIP(network); ip != nil && ip.To4() != nil {
+				network += "/32"
+			} else {
+				network += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, ipNet)
+	}
+
+	return res, nil
+}
+
+func containedInAny(ip net.IP, networks []*net.IPNet) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns ip of client sent request, X-Forwarded-For header is taken into account only if
+// request came from trusted proxy, addresses appended by trusted proxies are skipped from right to left
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !containedInAny(ip, trustedProxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if forwardedIP == nil {
+			break // header is malformed, so the rest can not be trusted
+		}
+
+		ip = forwardedIP
+
+		if !containedInAny(ip, trustedProxies) {
+			break
+		}
+	}
+
+	return ip
+}
@@ -0,0 +1,22 @@
+package slug
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+	slugFormat   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Make returns slug made of name: lowercase latin letters and digits separated by '-',
+// empty string is returned if name has no such symbols
+func Make(name string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// IsValid reports if slug is of format 'some-slug-1'
+func IsValid(slug string) bool {
+	return slugFormat.MatchString(slug)
+}
@@ -20,3 +20,67 @@ func FillIntSliceFromString(s string) ([]int, error) {
 
 	return slice, nil
 }
+
+// FillIntSliceFromPostgresArray returns int slice constructed from postgres array of format '{1,2,3...}'
+func FillIntSliceFromPostgresArray(s string) ([]int, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+
+	if s == "" {
+		return []int{}, nil
+	}
+
+	return FillIntSliceFromString(s)
+}
+
+// IntSliceToPostgresArray returns postgres array of format '{1,2,3...}' constructed from int slice
+func IntSliceToPostgresArray(slice []int) string {
+	strs := make([]string, 0, len(slice))
+
+	for _, i := range slice {
+		strs = append(strs, strconv.Itoa(i))
+	}
+
+	return "{" + strings.Join(strs, ",") + "}"
+}
+
+// FillStringSliceFromPostgresArray returns string slice constructed from one-dimensional postgres array of format
+// '{a,"b c","d\"e"...}', elements postgres quoted are unquoted and unescaped
+func FillStringSliceFromPostgresArray(s string) []string {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+
+	slice := []string{}
+
+	if s == "" {
+		return slice
+	}
+
+	var (
+		elem            strings.Builder
+		quoted, escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			elem.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			slice = append(slice, elem.String())
+			elem.Reset()
+		default:
+			elem.WriteRune(r)
+		}
+	}
+
+	return append(slice, elem.String())
+}
+
+// EscapePostgresLikePattern escapes wildcards of LIKE pattern with backslash, so s is matched literally,
+// query must declare backslash as escape character with ESCAPE '\'
+func EscapePostgresLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
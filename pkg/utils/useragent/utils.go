@@ -0,0 +1,37 @@
+package useragent
+
+import (
+	"strings"
+)
+
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// Parse returns platform and app version from User-Agent header. Apps' user agents are expected to be of format
+// 'App/1.2.3 (Android 14; ...)', so version is taken from the first product token, browsers have no app version
+func Parse(userAgent string) (platform string, appVersion string) {
+	lower := strings.ToLower(userAgent)
+
+	switch {
+	case strings.HasPrefix(lower, "mozilla/"):
+		return PlatformWeb, ""
+	case strings.Contains(lower, "android"):
+		platform = PlatformAndroid
+	case strings.Contains(lower, "iphone"), strings.Contains(lower, "ipad"), strings.Contains(lower, "ios"),
+		strings.Contains(lower, "darwin"):
+		platform = PlatformIOS
+	default:
+		return "", "" // http clients like okhttp/4.9.0 do not describe the app
+	}
+
+	product, _, _ := strings.Cut(userAgent, " ")
+
+	if _, version, found := strings.Cut(product, "/"); found {
+		appVersion = version
+	}
+
+	return platform, appVersion
+}
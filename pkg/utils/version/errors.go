@@ -0,0 +1,7 @@
+package version
+
+import "errors"
+
+var (
+	ErrInvalidVersion = errors.New("invalid version")
+)
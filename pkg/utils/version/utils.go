@@ -0,0 +1,69 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parse returns numeric parts of version of format '1.2.3', any number of parts is allowed
+func Parse(version string) ([]int, error) {
+	if version == "" {
+		return nil, ErrInvalidVersion
+	}
+
+	parts := strings.Split(version, ".")
+	res := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidVersion
+		}
+
+		res = append(res, n)
+	}
+
+	return res, nil
+}
+
+// IsValid reports if version is of format '1.2.3'
+func IsValid(version string) bool {
+	_, err := Parse(version)
+
+	return err == nil
+}
+
+// Compare returns -1 if v1 < v2, 0 if v1 == v2 and 1 if v1 > v2, missing parts are treated as zeros, so '1.2' == '1.2.0'
+func Compare(v1, v2 string) (int, error) {
+	parts1, err := Parse(v1)
+	if err != nil {
+		return 0, err
+	}
+
+	parts2, err := Parse(v2)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < max(len(parts1), len(parts2)); i++ {
+		var p1, p2 int
+
+		if i < len(parts1) {
+			p1 = parts1[i]
+		}
+
+		if i < len(parts2) {
+			p2 = parts2[i]
+		}
+
+		if p1 < p2 {
+			return -1, nil
+		}
+
+		if p1 > p2 {
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
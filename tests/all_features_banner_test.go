@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/handler/response"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+func (s *Suite) TestGetBannersOfAllFeatures() {
+	assertions := s.Require()
+
+	query := url.Values{}
+
+	query.Set("tag_ids", "1,2")
+	query.Set("use_last_revision", "true")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "/all", query, nil))
+
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var featureBanners []response.GetFeatureBannersResponse
+
+	s.NoError(json.NewDecoder(recorder.Body).Decode(&featureBanners))
+
+	// the first banner is shown in both features, inactive banner of the second feature is not returned
+	assertions.Len(featureBanners, 2)
+
+	assertions.Equal(1, featureBanners[0].FeatureID)
+	assertions.Len(featureBanners[0].Banners, 1)
+	assertions.Equal("title", featureBanners[0].Banners[0].Title)
+
+	assertions.Equal(2, featureBanners[1].FeatureID)
+	assertions.Len(featureBanners[1].Banners, 1)
+	assertions.Equal("title", featureBanners[1].Banners[0].Title)
+}
+
+func (s *Suite) TestGetBannersOfAllFeaturesPaginatedByFeature() {
+	assertions := s.Require()
+
+	query := url.Values{}
+
+	query.Set("tag_ids", "1,2")
+	query.Set("use_last_revision", "true")
+	query.Set("offset", "1")
+	query.Set("limit", "1")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "/all", query, nil))
+
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var featureBanners []response.GetFeatureBannersResponse
+
+	s.NoError(json.NewDecoder(recorder.Body).Decode(&featureBanners))
+
+	// only the second of features with banners shown to user is on the page
+	assertions.Len(featureBanners, 1)
+
+	assertions.Equal(2, featureBanners[0].FeatureID)
+	assertions.Len(featureBanners[0].Banners, 1)
+	assertions.Equal("title", featureBanners[0].Banners[0].Title)
+}
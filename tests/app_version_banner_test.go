@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	bannerservice "avito-backend-trainee-2024/internal/service/banner"
+	"context"
+)
+
+// createVersionedBanner creates banner of feature 2 shown to app versions in range and deletes it once test is done
+func (s *Suite) createVersionedBanner(ctx context.Context, minAppVersion, maxAppVersion string) *entity.Banner {
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:        []int{3},
+		FeatureIDs:    []int{2},
+		Platforms:     []string{"android"},
+		MinAppVersion: minAppVersion,
+		MaxAppVersion: maxAppVersion,
+		Content: entity.Content{
+			Title: "versioned_title",
+			Text:  "versioned_text",
+			Url:   "http://versioned.com",
+		},
+	})
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	})
+
+	return created
+}
+
+func (s *Suite) TestUpdateAppVersionValidatedAgainstStoredOne() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created := s.createVersionedBanner(ctx, "2.0.0", "3.0.0")
+
+	// min version is moved past stored max version
+	err := s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{MinAppVersion: "4.0.0"})
+	assertions.ErrorIs(err, bannerservice.ErrInvalidAppVersion)
+
+	err = s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{MaxAppVersion: "1.0.0"})
+	assertions.ErrorIs(err, bannerservice.ErrInvalidAppVersion)
+
+	err = s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{MinAppVersion: "not_a_version"})
+	assertions.ErrorIs(err, bannerservice.ErrInvalidAppVersion)
+
+	// both bounds moved together are valid
+	assertions.NoError(s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{MinAppVersion: "4.0.0", MaxAppVersion: "5.0.0"}))
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Equal("4.0.0", banner.MinAppVersion)
+	assertions.Equal("5.0.0", banner.MaxAppVersion)
+}
+
+func (s *Suite) TestRemoveAppVersions() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created := s.createVersionedBanner(ctx, "2.0.0", "3.0.0")
+
+	// removed max version no longer bounds min version
+	assertions.NoError(s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{
+		MinAppVersion: "4.0.0",
+		MaxAppVersion: entity.NoAppVersion,
+	}))
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Equal("4.0.0", banner.MinAppVersion)
+	assertions.Empty(banner.MaxAppVersion)
+
+	assertions.NoError(s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{
+		MinAppVersion: entity.NoAppVersion,
+		MaxAppVersion: entity.NoAppVersion,
+	}))
+
+	banner, err = s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Empty(banner.MinAppVersion)
+	assertions.Empty(banner.MaxAppVersion)
+}
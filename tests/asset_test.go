@@ -0,0 +1,110 @@
+package tests
+
+import (
+	assethandler "avito-backend-trainee-2024/internal/handler/asset"
+	assetrepo "avito-backend-trainee-2024/internal/repository/postgres/asset"
+	assetservice "avito-backend-trainee-2024/internal/service/asset"
+	localstorage "avito-backend-trainee-2024/pkg/storage/local"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// failingStorage is asset storage which can not be read
+type failingStorage struct {
+	assetservice.Storage
+}
+
+func (failingStorage) Open(context.Context, string) (io.ReadSeekCloser, error) {
+	return nil, errors.New("storage is unavailable")
+}
+
+// serveAsset serves asset with hash by handler of asset service using storage
+func (s *Suite) serveAsset(storage assetservice.Storage, hash string) *httptest.ResponseRecorder {
+	service := assetservice.New(assetrepo.New(s.db), storage, "http://localhost/asset")
+	handler := assethandler.New(service, logrus.New(), validator.New(validator.WithRequiredStructEnabled()))
+
+	recorder := httptest.NewRecorder()
+	handler.Routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/"+hash, nil))
+
+	return recorder
+}
+
+func (s *Suite) TestUploadAsset() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+
+	assertions.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 320, 100))))
+
+	asset, err := s.assetService.UploadAsset(ctx, buf.Bytes())
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.assetService.DeleteAsset(ctx, asset.ID))
+	}()
+
+	assertions.Equal("image/png", asset.MimeType)
+	assertions.Equal(buf.Len(), asset.Size)
+	assertions.Equal(320, asset.Width)
+	assertions.Equal(100, asset.Height)
+	assertions.Equal("http://localhost/asset/"+asset.Hash, asset.URL)
+
+	// the same image is stored once
+	uploadedAgain, err := s.assetService.UploadAsset(ctx, buf.Bytes())
+	assertions.NoError(err)
+	assertions.Equal(asset.ID, uploadedAgain.ID)
+}
+
+func (s *Suite) TestUploadUnsupportedAsset() {
+	assertions := s.Require()
+
+	_, err := s.assetService.UploadAsset(context.Background(), []byte("not an image"))
+
+	assertions.ErrorIs(err, assetservice.ErrUnsupportedMediaType)
+}
+
+func (s *Suite) TestServeAssetErrorStatuses() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	storage, err := localstorage.New(s.T().TempDir())
+	assertions.NoError(err)
+
+	var buf bytes.Buffer
+
+	assertions.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 10, 10))))
+
+	asset, err := assetservice.New(assetrepo.New(s.db), storage, "http://localhost/asset").UploadAsset(ctx, buf.Bytes())
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.assetService.DeleteAsset(ctx, asset.ID))
+	}()
+
+	recorder := s.serveAsset(storage, asset.Hash)
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode, recorder.Body.String())
+	assertions.Equal(buf.Bytes(), recorder.Body.Bytes())
+
+	s.requireErrorResponse(s.serveAsset(storage, "not-existing-hash"), http.StatusNotFound, "no such asset")
+
+	// asset which content is missing is not found too
+	emptyStorage, err := localstorage.New(s.T().TempDir())
+	assertions.NoError(err)
+
+	s.requireErrorResponse(s.serveAsset(emptyStorage, asset.Hash), http.StatusNotFound, "no such asset")
+
+	// failure to read asset is not reported as missing asset
+	s.requireErrorResponse(s.serveAsset(failingStorage{}, asset.Hash), http.StatusInternalServerError, "internal error")
+}
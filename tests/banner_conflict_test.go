@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+)
+
+func (s *Suite) TestCreateBannerConflictingWithExisting() {
+	assertions := s.Require()
+
+	// the first fixture banner is active and shown in feature 1 with tags 1 and 2 to everyone
+	_, err := s.bannerService.CreateBanner(context.Background(), entity.Banner{
+		TagIDs:     []int{2, 1},
+		FeatureIDs: []int{1},
+		Content: entity.Content{
+			Title: "conflict_title",
+			Text:  "conflict_text",
+			Url:   "http://conflict.com",
+		},
+		IsActive: true,
+	})
+
+	var conflict *entity.BannerConflictError
+
+	assertions.ErrorAs(err, &conflict)
+	assertions.Contains(conflict.BannerIDs, 1)
+}
+
+func (s *Suite) TestUpdateBannerConflictingWithExisting() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	// banner targeted to country does not conflict with untargeted one
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{1, 2},
+		FeatureIDs: []int{1},
+		Countries:  []string{"RU"},
+		Content: entity.Content{
+			Title: "conflict_title",
+			Text:  "conflict_text",
+			Url:   "http://conflict.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	err = s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{Countries: []string{}, IsActive: true})
+
+	var conflict *entity.BannerConflictError
+
+	assertions.ErrorAs(err, &conflict)
+	assertions.Equal([]int{1, created.ID}, conflict.BannerIDs)
+
+	// inactive banner is never shown by lookup, so it does not conflict
+	assertions.NoError(s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{Countries: []string{}, IsActive: false}))
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/response"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func (s *Suite) TestGetBannersByBatchOfQueries() {
+	assertions := s.Require()
+
+	body := `{"queries": [
+		{"feature_id": 1, "tag_ids": [1, 2]},
+		{"key": "missing", "feature_id": 10, "tag_ids": [1, 2]}
+	]}`
+
+	query := url.Values{}
+
+	query.Set("use_last_revision", "true")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodPost, "/batch", query, strings.NewReader(body)))
+
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var results map[string]response.BatchUserBannerResultResponse
+
+	s.NoError(json.NewDecoder(recorder.Body).Decode(&results))
+
+	assertions.Len(results, 2)
+
+	// results are keyed by query if key is not provided
+	found := results["feature_id=1&tag_ids=1,2"]
+
+	assertions.NotNil(found.Banner)
+	assertions.Equal("title", found.Banner.Title)
+	assertions.Equal("text", found.Banner.Text)
+	assertions.Equal("http://url.com", found.Banner.Url)
+
+	assertions.Nil(results["missing"].Banner)
+	assertions.Equal("no such banner", results["missing"].Error)
+}
+
+func (s *Suite) TestBatchQueriesServedFromLookupCache() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "batch_cached_tag")
+	banner := s.createTaggedBanner(ctx, []int{tags[0].ID}, true)
+
+	// single lookup caches banner
+	recorder := s.getUserBanner(regularUser, "2", fmt.Sprint(tags[0].ID))
+
+	s.requireBannerContent(recorder, banner.Content)
+
+	assertions.NoError(s.bannerService.UpdateBanner(ctx, banner.ID, entity.Banner{
+		Content:  entity.Content{Title: "batch_updated_title"},
+		IsActive: true,
+	}))
+
+	body := fmt.Sprintf(`{"queries": [{"key": "cached", "feature_id": 2, "tag_ids": [%v]}]}`, tags[0].ID)
+
+	recorder = s.serveAs(regularUser, newUserBannerRequest(http.MethodPost, "/batch", url.Values{}, strings.NewReader(body)))
+
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var results map[string]response.BatchUserBannerResultResponse
+
+	s.NoError(json.NewDecoder(recorder.Body).Decode(&results))
+
+	// batch query shares cache entry with single lookup of the same feature and tags
+	assertions.NotNil(results["cached"].Banner)
+	assertions.Equal(banner.Content.Title, results["cached"].Banner.Title)
+}
@@ -0,0 +1,266 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	campaignrepo "avito-backend-trainee-2024/internal/repository/postgres/campaign"
+	bannerservice "avito-backend-trainee-2024/internal/service/banner"
+	campaignservice "avito-backend-trainee-2024/internal/service/campaign"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func (s *Suite) TestGetBannerOfCampaignWithSpentBudget() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	campaign, err := s.campaignService.CreateCampaign(ctx, entity.Campaign{
+		Name:              "campaign_budget_test_name",
+		ImpressionsBudget: 1,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.campaignService.DeleteCampaign(ctx, campaign.ID))
+	}()
+
+	// child tag is requested, so no fixture banner is matched
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		CampaignID: &campaign.ID,
+		Content: entity.Content{
+			Title: "campaign_title",
+			Text:  "campaign_text",
+			Url:   "http://campaign.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	requester := entity.Requester{UserID: 1}
+
+	banner, err := s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{3}, requester)
+	assertions.NoError(err)
+	assertions.Equal(created.ID, banner.ID)
+
+	// the only impression of the budget is counted before it is persisted
+	_, err = s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{3}, requester)
+	assertions.ErrorIs(err, bannerservice.ErrNoSuchBanner)
+}
+
+func (s *Suite) TestGetBannerOfCampaignWithTargeting() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	endsAt := time.Now().Add(time.Hour)
+
+	campaign, err := s.campaignService.CreateCampaign(ctx, entity.Campaign{
+		Name:      "campaign_targeting_test_name",
+		EndsAt:    &endsAt,
+		Countries: []string{"RU"},
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.campaignService.DeleteCampaign(ctx, campaign.ID))
+	}()
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		CampaignID: &campaign.ID,
+		Content: entity.Content{
+			Title: "campaign_title",
+			Text:  "campaign_text",
+			Url:   "http://campaign.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	// banner has no targeting of its own, so countries of campaign are applied
+	_, err = s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{3}, entity.Requester{UserID: 1, Country: "DE"})
+	assertions.ErrorIs(err, bannerservice.ErrNoSuchBanner)
+
+	banner, err := s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{3}, entity.Requester{UserID: 1, Country: "RU"})
+	assertions.NoError(err)
+	assertions.Equal(created.ID, banner.ID)
+}
+
+// createCampaignBanner creates campaign and its banner of feature 2 with tags and deletes them once test is done
+func (s *Suite) createCampaignBanner(ctx context.Context, campaign entity.Campaign, tagIDs []int) (*entity.Campaign, *entity.Banner) {
+	assertions := s.Require()
+
+	created, err := s.campaignService.CreateCampaign(ctx, campaign)
+	assertions.NoError(err)
+
+	banner, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     tagIDs,
+		FeatureIDs: []int{2},
+		CampaignID: &created.ID,
+		Content: entity.Content{
+			Title: campaign.Name + "_title",
+			Text:  campaign.Name + "_text",
+			Url:   "http://campaign.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	s.T().Cleanup(func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, banner.ID)
+		s.NoError(err)
+
+		s.NoError(s.campaignService.DeleteCampaign(ctx, created.ID))
+	})
+
+	return created, banner
+}
+
+func (s *Suite) TestCampaignBudgetNotOverspentByInstances() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	campaign, err := s.campaignService.CreateCampaign(ctx, entity.Campaign{
+		Name:              "campaign_instances_test_name",
+		ImpressionsBudget: 3,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.campaignService.DeleteCampaign(ctx, campaign.ID))
+	}()
+
+	// each instance reserves impressions from the same budget in db
+	instances := []*campaignservice.Service{
+		campaignservice.New(campaignrepo.New(s.db), time.Minute, 2, logrus.New()),
+		campaignservice.New(campaignrepo.New(s.db), time.Minute, 2, logrus.New()),
+	}
+
+	claimed := 0
+
+	for i := 0; i < 4; i++ {
+		for _, instance := range instances {
+			ok, err := instance.ClaimImpressions(ctx, []int{campaign.ID})
+			assertions.NoError(err)
+
+			if ok {
+				claimed++
+			}
+		}
+	}
+
+	assertions.Equal(3, claimed)
+}
+
+func (s *Suite) TestCampaignReservedImpressionsReturned() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	campaign, err := s.campaignService.CreateCampaign(ctx, entity.Campaign{
+		Name:              "campaign_reservation_test_name",
+		ImpressionsBudget: 10,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.campaignService.DeleteCampaign(ctx, campaign.ID))
+	}()
+
+	instance := campaignservice.New(campaignrepo.New(s.db), time.Minute, 5, logrus.New())
+
+	ok, err := instance.ClaimImpressions(ctx, []int{campaign.ID})
+	assertions.NoError(err)
+	assertions.True(ok)
+
+	// instance subtracts impressions it reserved but not shown, others see them spent until they are returned
+	own, err := instance.GetCampaignByID(ctx, campaign.ID)
+	assertions.NoError(err)
+	assertions.Equal(1, own.ImpressionsCount)
+
+	other, err := s.campaignService.GetCampaignByID(ctx, campaign.ID)
+	assertions.NoError(err)
+	assertions.Equal(5, other.ImpressionsCount)
+
+	assertions.NoError(instance.Flush(ctx))
+
+	other, err = s.campaignService.GetCampaignByID(ctx, campaign.ID)
+	assertions.NoError(err)
+	assertions.Equal(1, other.ImpressionsCount)
+}
+
+func (s *Suite) TestCachedCampaignBannerServedWithinBudget() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "campaign_cached_tag")
+
+	_, banner := s.createCampaignBanner(ctx, entity.Campaign{
+		Name:              "campaign_cached_test_name",
+		ImpressionsBudget: 2,
+	}, []int{tags[0].ID})
+
+	query := url.Values{}
+
+	query.Set("feature_id", "2")
+	query.Set("tag_ids", fmt.Sprint(tags[0].ID))
+
+	// the first impression caches banner, the second one is served from cache
+	recorder := s.getUserBanner(regularUser, "2", fmt.Sprint(tags[0].ID))
+	s.requireBannerContent(recorder, banner.Content)
+
+	recorder = s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+	s.requireBannerContent(recorder, banner.Content)
+
+	// cached banner is not served beyond budget
+	recorder = s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+	assertions.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestCampaignScheduleRemoved() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	endedAt := time.Now().Add(-time.Hour)
+
+	campaign, banner := s.createCampaignBanner(ctx, entity.Campaign{
+		Name:   "campaign_schedule_test_name",
+		EndsAt: &endedAt,
+	}, []int{3})
+
+	requester := entity.Requester{UserID: 1}
+
+	_, err := s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{3}, requester)
+	assertions.ErrorIs(err, bannerservice.ErrNoSuchBanner)
+
+	// zero date removes end of campaign
+	assertions.NoError(s.campaignService.UpdateCampaign(ctx, campaign.ID, entity.Campaign{EndsAt: &time.Time{}}))
+
+	updated, err := s.campaignService.GetCampaignByID(ctx, campaign.ID)
+	assertions.NoError(err)
+	assertions.Nil(updated.EndsAt)
+
+	found, err := s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{3}, requester)
+	assertions.NoError(err)
+	assertions.Equal(banner.ID, found.ID)
+}
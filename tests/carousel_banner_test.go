@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+func (s *Suite) TestGetBannersWithLimit() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	// banners have exactly requested tags, the first fixture banner matches them through parent tag 2 of tag 3
+	for _, carousel := range []struct {
+		title     string
+		priority  int
+		countries []string
+	}{
+		{"carousel_low", 0, nil},
+		{"carousel_high", 10, nil},
+		{"carousel_middle", 5, nil},
+		{"carousel_hidden", 20, []string{"KZ"}}, // is not shown to requester out of targeted country
+	} {
+		created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+			TagIDs:     []int{1, 3},
+			FeatureIDs: []int{2},
+			Countries:  carousel.countries,
+			Priority:   &carousel.priority,
+			Content: entity.Content{
+				Title: carousel.title,
+				Text:  "carousel_text",
+				Url:   "http://carousel.com",
+			},
+			IsActive: true,
+		})
+		assertions.NoError(err)
+
+		defer func() {
+			_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+			s.NoError(err)
+		}()
+	}
+
+	query := url.Values{}
+
+	query.Set("feature_id", "2")
+	query.Set("tag_ids", "1,3")
+	query.Set("limit", "5")
+	query.Set("use_last_revision", "true")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var banners []entity.Banner
+
+	s.NoError(json.NewDecoder(recorder.Body).Decode(&banners))
+
+	// higher priority goes first, banners of the same priority are ranked by specificity of their tags
+	var titles []string
+
+	for _, banner := range banners {
+		titles = append(titles, banner.Content.Title)
+	}
+
+	assertions.Equal([]string{"carousel_high", "carousel_middle", "carousel_low", "title"}, titles)
+}
+
+func (s *Suite) TestGetBannersWithInvalidLimit() {
+	query := url.Values{}
+
+	query.Set("feature_id", "1")
+	query.Set("tag_ids", "1,2")
+	query.Set("limit", "0")
+	query.Set("use_last_revision", "true")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+
+	s.requireErrorResponse(recorder, http.StatusBadRequest, "'limit' query param must be between 1 and 20")
+}
+
+func (s *Suite) TestBannerCandidatesFilteredByTagsAndActivity() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "candidate_tag", "candidate_other_tag")
+	tag, other := tags[0], tags[1]
+
+	active := s.createTaggedBanner(ctx, []int{tag.ID}, true)
+	inactive := s.createTaggedBanner(ctx, []int{tag.ID, 3}, false)
+	foreign := s.createTaggedBanner(ctx, []int{tag.ID, other.ID}, true)
+
+	candidateIDs := func(isActive bool) []int {
+		candidates, err := s.bannerRepo.GetBannerCandidates(ctx, 2, []int{tag.ID, 3}, isActive)
+		assertions.NoError(err)
+
+		var IDs []int
+
+		for _, candidate := range candidates {
+			IDs = append(IDs, candidate.ID)
+		}
+
+		return IDs
+	}
+
+	// banner having tag out of requested ones is not fetched
+	activeIDs := candidateIDs(true)
+	assertions.Contains(activeIDs, active.ID)
+	assertions.NotContains(activeIDs, inactive.ID)
+	assertions.NotContains(activeIDs, foreign.ID)
+
+	inactiveIDs := candidateIDs(false)
+	assertions.Contains(inactiveIDs, inactive.ID)
+	assertions.NotContains(inactiveIDs, active.ID)
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	bannerservice "avito-backend-trainee-2024/internal/service/banner"
+	"context"
+)
+
+func (s *Suite) TestCloneBannerWithOverrides() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		Countries:  []string{"RU"},
+		Platforms:  []string{"ios"},
+		Content: entity.Content{
+			Title: "original_title",
+			Text:  "original_text",
+			Url:   "http://original.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	clone, err := s.bannerService.CloneBanner(ctx, created.ID, entity.Banner{
+		TagIDs:    []int{1},
+		Countries: []string{"KZ"},
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, clone.ID)
+		s.NoError(err)
+	}()
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, clone.ID)
+	assertions.NoError(err)
+
+	assertions.NotEqual(created.ID, banner.ID)
+	assertions.False(banner.IsActive)
+	assertions.Equal([]int{1}, banner.TagIDs)
+	assertions.Equal([]int{2}, banner.FeatureIDs)
+	assertions.Equal([]string{"KZ"}, banner.Countries)
+	assertions.Equal([]string{"ios"}, banner.Platforms)
+	assertions.Equal("original_title", banner.Content.Title)
+	assertions.Equal("original_text", banner.Content.Text)
+	assertions.Equal("http://original.com", banner.Content.Url)
+}
+
+func (s *Suite) TestCloneMissingBanner() {
+	assertions := s.Require()
+
+	_, err := s.bannerService.CloneBanner(context.Background(), 1_000_000, entity.Banner{})
+
+	assertions.ErrorIs(err, bannerservice.ErrNoSuchBanner)
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (s *Suite) TestCoverageReportOfMissedAndNotServedCombinations() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "coverage_missed_tag", "coverage_inactive_tag", "coverage_expired_tag")
+	missed, inactive, expired := tags[0], tags[1], tags[2]
+
+	inactiveBanner := s.createTaggedBanner(ctx, []int{inactive.ID}, false)
+
+	endedAt := time.Now().Add(-time.Hour)
+
+	expiredBanner, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{expired.ID},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "expired_title",
+			Text:  "expired_text",
+			Url:   "http://expired.com",
+		},
+		IsActive: true,
+		EndsAt:   &endedAt,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, expiredBanner.ID)
+		s.NoError(err)
+	}()
+
+	// lookup finding nothing is reported as uncovered combination
+	recorder := s.getUserBanner(regularUser, "2", fmt.Sprint(missed.ID))
+
+	assertions.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+
+	report, err := s.bannerService.GetCoverageReport(ctx, 2)
+	assertions.NoError(err)
+
+	var uncovered *entity.BannerMiss
+
+	for _, miss := range report.Uncovered {
+		if miss.FeatureID == 2 && len(miss.TagIDs) == 1 && miss.TagIDs[0] == missed.ID {
+			uncovered = miss
+		}
+	}
+
+	assertions.NotNil(uncovered)
+	assertions.Equal(1, uncovered.MissesCount)
+
+	combinations := make(map[int]*entity.InactiveCombination)
+
+	for _, combination := range report.Inactive {
+		if len(combination.TagIDs) == 1 {
+			combinations[combination.TagIDs[0]] = combination
+		}
+	}
+
+	assertions.Contains(combinations, inactive.ID)
+	assertions.Equal(entity.CoverageReasonInactive, combinations[inactive.ID].Reason)
+	assertions.Equal([]int{inactiveBanner.ID}, combinations[inactive.ID].BannerIDs)
+
+	assertions.Contains(combinations, expired.ID)
+	assertions.Equal(entity.CoverageReasonExpired, combinations[expired.ID].Reason)
+	assertions.Equal([]int{expiredBanner.ID}, combinations[expired.ID].BannerIDs)
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	explainbannerhandler "avito-backend-trainee-2024/internal/handler/banner/explain"
+	midlewares "avito-backend-trainee-2024/internal/handler/middleware"
+	"avito-backend-trainee-2024/internal/handler/response"
+	overriderepo "avito-backend-trainee-2024/internal/repository/postgres/override"
+	bannerservice "avito-backend-trainee-2024/internal/service/banner"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *Suite) TestExplainBannerResolutionReasons() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "explain_ended_tag", "explain_pending_tag", "explain_finished_tag", "explain_spent_tag",
+		"explain_matching_tag", "explain_mismatching_tag")
+
+	endsAt := time.Now().Add(-time.Minute)
+
+	ended, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{tags[0].ID},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "explain_ended_title",
+			Text:  "explain_ended_text",
+			Url:   "http://explain-ended.com",
+		},
+		IsActive: true,
+		EndsAt:   &endsAt,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, ended.ID)
+		s.NoError(err)
+	}()
+
+	startsAt, finishedAt := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+
+	_, pending := s.createCampaignBanner(ctx, entity.Campaign{Name: "explain_pending", StartsAt: &startsAt}, []int{tags[1].ID})
+	_, finished := s.createCampaignBanner(ctx, entity.Campaign{Name: "explain_finished", EndsAt: &finishedAt}, []int{tags[2].ID})
+
+	spentCampaign, spent := s.createCampaignBanner(ctx, entity.Campaign{Name: "explain_spent", ImpressionsBudget: 1}, []int{tags[3].ID})
+
+	_, err = s.db.ExecContext(ctx, "UPDATE campaign SET impressions_count = impressions_budget WHERE id = $1", spentCampaign.ID)
+	assertions.NoError(err)
+
+	matching := s.createTaggedBanner(ctx, []int{tags[4].ID}, true)
+	mismatching := s.createTaggedBanner(ctx, []int{tags[5].ID}, true)
+
+	candidates, err := s.bannerService.ExplainBannerResolution(ctx, 2, []int{tags[4].ID}, entity.Requester{UserID: regularUser.ID})
+	assertions.NoError(err)
+
+	reasons := make(map[int]string)
+
+	for _, candidate := range candidates {
+		reasons[candidate.Banner.ID] = candidate.Reason
+	}
+
+	assertions.Equal(bannerservice.ErrBannerEnded.Error(), reasons[ended.ID])
+	assertions.Equal(bannerservice.ErrCampaignPending.Error(), reasons[pending.ID])
+	assertions.Equal(bannerservice.ErrCampaignEnded.Error(), reasons[finished.ID])
+	assertions.Equal(bannerservice.ErrCampaignSpent.Error(), reasons[spent.ID])
+	assertions.Equal(bannerservice.ErrTagMismatch.Error(), reasons[mismatching.ID])
+	assertions.Equal(bannerservice.ReasonMatches, reasons[matching.ID])
+}
+
+func (s *Suite) TestExplainIgnoresOverrideOfArchivedBanner() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "explain_archived_override_tag")
+
+	archived := s.createTaggedBanner(ctx, []int{tags[0].ID, 3}, true)
+	served := s.createTaggedBanner(ctx, []int{tags[0].ID}, true)
+
+	_, err := s.db.ExecContext(ctx, "UPDATE banner SET archived_at = now() WHERE id = $1", archived.ID)
+	assertions.NoError(err)
+
+	overrides, err := overriderepo.New(s.db).CreateOverrides(ctx, archived.ID, []int{qaUser.ID}, time.Now().Add(time.Hour))
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(overriderepo.New(s.db).DeleteOverride(ctx, overrides[0].ID))
+	}()
+
+	candidates, err := s.bannerService.ExplainBannerResolution(ctx, 2, []int{tags[0].ID}, entity.Requester{UserID: qaUser.ID})
+	assertions.NoError(err)
+
+	reasons := make(map[int]string)
+
+	for _, candidate := range candidates {
+		reasons[candidate.Banner.ID] = candidate.Reason
+	}
+
+	// lookup ignores override of archived banner and returns matching one
+	assertions.Equal(bannerservice.ReasonMatches, reasons[served.ID])
+}
+
+func (s *Suite) TestExplainCacheHitOfExpiredEntry() {
+	assertions := s.Require()
+
+	cache := gocache.New(5*time.Minute, 10*time.Minute)
+
+	handler := explainbannerhandler.New(s.bannerService, cache, logrus.New(), validator.New(validator.WithRequiredStructEnabled()))
+
+	query := url.Values{}
+	query.Set("feature_id", "2")
+	query.Set("tag_ids", "3")
+
+	explain := func() bool {
+		req := httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		req.Header.Set("id", strconv.Itoa(regularUser.ID))
+
+		recorder := httptest.NewRecorder()
+		handler.ExplainBannerResolution(recorder, req)
+
+		assertions.Equal(http.StatusOK, recorder.Result().StatusCode, recorder.Body.String())
+
+		var resp response.ExplainBannerResponse
+
+		assertions.NoError(json.NewDecoder(recorder.Body).Decode(&resp))
+
+		return resp.CacheHit
+	}
+
+	key := midlewares.UserBannerCacheKey(midlewares.UserBannerLookupRoute, query, entity.Requester{UserID: regularUser.ID})
+
+	expiredAt := time.Now().Add(-time.Minute)
+
+	midlewares.CacheUserBanner(cache, key, midlewares.CachedUserBanner{IsActive: true, ExpiresAt: &expiredAt})
+	assertions.False(explain())
+
+	midlewares.CacheUserBanner(cache, key, midlewares.CachedUserBanner{IsActive: true})
+	assertions.True(explain())
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+func (s *Suite) TestGetCachedBannerOfDisabledFeature() {
+	query := url.Values{}
+
+	// no 'use_last_revision' param, so banner is served from cache on the second request
+	query.Set("feature_id", "1")
+	query.Set("tag_ids", "1,2")
+
+	getBanner := func() *httptest.ResponseRecorder {
+		return s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+	}
+
+	s.Require().Equal(http.StatusOK, getBanner().Result().StatusCode)
+
+	ctx := context.Background()
+
+	s.NoError(s.featureService.SetFeatureEnabled(ctx, 1, false))
+	defer func() {
+		s.NoError(s.featureService.SetFeatureEnabled(ctx, 1, true))
+	}()
+
+	s.requireErrorResponse(getBanner(), http.StatusBadRequest, "error occurred fetching banner: no such banner")
+}
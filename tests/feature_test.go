@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	featurerepo "avito-backend-trainee-2024/internal/repository/postgres/feature"
+	"context"
+	"errors"
+	"time"
+)
+
+func (s *Suite) TestFeatureLabelsWithSpecialCharactersStored() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	repo := featurerepo.New(s.db)
+
+	labels := []string{"with,comma", `with "quotes"`, "{with braces}", `with\backslash`, "with space", "NULL"}
+
+	created, err := repo.CreateFeature(ctx, entity.Feature{
+		Name:   "labeled_feature",
+		Slug:   "labeled-feature",
+		Labels: labels,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(repo.DeleteFeature(ctx, created.ID))
+	}()
+
+	assertions.Equal(labels, created.Labels)
+
+	updated := []string{`"quoted"`, "a,b"}
+
+	assertions.NoError(repo.UpdateFeature(ctx, created.ID, entity.Feature{Labels: updated}))
+
+	feature, err := repo.GetFeatureByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Equal(updated, feature.Labels)
+}
+
+// createFeature creates feature and deletes it once test is done, banners of the feature must be deleted by then
+func (s *Suite) createFeature(ctx context.Context, slug string) *entity.Feature {
+	repo := featurerepo.New(s.db)
+
+	created, err := repo.CreateFeature(ctx, entity.Feature{Name: slug, Slug: slug})
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		err := repo.DeleteFeature(ctx, created.ID)
+		if !errors.Is(err, featurerepo.ErrNoSuchFeature) {
+			s.NoError(err)
+		}
+	})
+
+	return created
+}
+
+func (s *Suite) TestDeleteFeature() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	repo := featurerepo.New(s.db)
+
+	unused := s.createFeature(ctx, "unused-feature")
+	used := s.createFeature(ctx, "used-feature")
+
+	banner := s.createTaggedBanner(ctx, []int{3}, false)
+
+	_, err := s.db.ExecContext(ctx, "INSERT INTO banner_feature (banner_id, feature_id) VALUES ($1, $2)", banner.ID, used.ID)
+	assertions.NoError(err)
+
+	assertions.ErrorIs(repo.DeleteFeature(ctx, used.ID), featurerepo.ErrFeatureInUse)
+
+	assertions.NoError(repo.DeleteFeature(ctx, unused.ID))
+	assertions.ErrorIs(repo.DeleteFeature(ctx, unused.ID), featurerepo.ErrNoSuchFeature)
+}
+
+func (s *Suite) TestDeleteFeatureWaitsForBannerBeingAttached() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	feature := s.createFeature(ctx, "attached-feature")
+
+	banner := s.createTaggedBanner(ctx, []int{3}, false)
+
+	// banner is being attached to the feature by concurrent transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	assertions.NoError(err)
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO banner_feature (banner_id, feature_id) VALUES ($1, $2)", banner.ID, feature.ID)
+	assertions.NoError(err)
+
+	deleted := make(chan error, 1)
+
+	go func() {
+		deleted <- featurerepo.New(s.db).DeleteFeature(ctx, feature.ID)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	assertions.NoError(tx.Commit())
+
+	assertions.ErrorIs(<-deleted, featurerepo.ErrFeatureInUse)
+}
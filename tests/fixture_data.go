@@ -16,12 +16,18 @@ var (
 			IsAdmin:        true,
 			HashedPassword: "12345678",
 		},
+		{
+			Username:       "qa",
+			IsAdmin:        false,
+			HashedPassword: "12345678",
+		},
 	}
 
+	// the first banner is shown in both features
 	banners = []entity.Banner{
 		{
-			TagIDs:    []int{1, 2},
-			FeatureID: 1,
+			TagIDs:     []int{1, 2},
+			FeatureIDs: []int{1, 2},
 			Content: entity.Content{
 				Title: "title",
 				Text:  "text",
@@ -30,8 +36,8 @@ var (
 			IsActive: true,
 		},
 		{
-			TagIDs:    []int{1},
-			FeatureID: 2,
+			TagIDs:     []int{1},
+			FeatureIDs: []int{2},
 			Content: entity.Content{
 				Title: "title2",
 				Text:  "text2",
@@ -39,5 +45,46 @@ var (
 			},
 			IsActive: false,
 		},
+		{
+			TagIDs:     []int{2},
+			FeatureIDs: []int{1},
+			SegmentIDs: []int{1},
+			Content: entity.Content{
+				Title: "title3",
+				Text:  "text3",
+				Url:   "http://url3.com",
+			},
+			IsActive: true,
+		},
+	}
+
+	// tags with ids 1 and 2 are created by migrations, so child tag of the second one gets id 3
+	tags = []entity.Tag{
+		{
+			Name:     "tag_test_child_name",
+			Slug:     "tag-test-child-name",
+			ParentID: &parentTagID,
+		},
+	}
+
+	parentTagID = 2
+
+	segments = []entity.Segment{
+		{
+			Name: "segment_test_name",
+		},
+	}
+
+	// segmentUsers[i] are ids of users in segments[i]
+	segmentUsers = [][]int{
+		{1},
+	}
+
+	// inactive banner with feature 2 is pinned to qa user
+	overrides = []entity.Override{
+		{
+			BannerID: 2,
+			UserID:   3,
+		},
 	}
 )
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"avito-backend-trainee-2024/internal/handler/middleware"
+	"avito-backend-trainee-2024/pkg/geoip"
+	iputils "avito-backend-trainee-2024/pkg/utils/ip"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeGeoResolver map[string]geoip.Location
+
+func (r fakeGeoResolver) Lookup(ip net.IP) (geoip.Location, error) {
+	location, exists := r[ip.String()]
+	if !exists {
+		return geoip.Location{}, geoip.ErrLocationNotFound
+	}
+
+	return location, nil
+}
+
+func (s *Suite) TestClientIP() {
+	assertions := s.Require()
+
+	trustedProxies, err := iputils.ParseNetworks([]string{"10.0.0.0/8", "192.168.1.1"})
+	assertions.NoError(err)
+
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{"direct client", "1.1.1.1:1234", nil, "1.1.1.1"},
+		{"forwarded header of untrusted client is ignored", "1.1.1.1:1234", []string{"2.2.2.2"}, "1.1.1.1"},
+		{"client behind trusted proxy", "10.0.0.1:1234", []string{"2.2.2.2"}, "2.2.2.2"},
+		{"addresses appended by trusted proxies are skipped", "10.0.0.1:1234", []string{"3.3.3.3, 2.2.2.2, 192.168.1.1"}, "2.2.2.2"},
+		{"several forwarded headers are joined", "10.0.0.1:1234", []string{"3.3.3.3", "2.2.2.2"}, "2.2.2.2"},
+		{"malformed address stops the chain", "10.0.0.1:1234", []string{"3.3.3.3, not_an_ip, 10.0.0.2"}, "10.0.0.2"},
+		{"remote address without port", "1.1.1.1", nil, "1.1.1.1"},
+		{"ipv6 client", "[2001:db8::1]:1234", nil, "2001:db8::1"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+
+		for _, forwarded := range tc.forwarded {
+			req.Header.Add("X-Forwarded-For", forwarded)
+		}
+
+		assertions.Equal(tc.expected, iputils.ClientIP(req, trustedProxies).String(), tc.name)
+	}
+}
+
+func (s *Suite) TestGeoLocationMiddleware() {
+	assertions := s.Require()
+
+	resolver := fakeGeoResolver{"1.1.1.1": {Country: "RU", Region: "RU-MOW"}}
+
+	for _, tc := range []struct {
+		name            string
+		resolver        middleware.GeoResolver
+		remoteAddr      string
+		country, region string
+	}{
+		{"location is resolved", resolver, "1.1.1.1:1234", "RU", "RU-MOW"},
+		{"unknown location leaves headers empty", resolver, "2.2.2.2:1234", "", ""},
+		{"nil resolver leaves headers empty", nil, "1.1.1.1:1234", "", ""},
+	} {
+		var country, region string
+
+		next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			country, region = req.Header.Get("country"), req.Header.Get("region")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+
+		// location set by client itself is never trusted
+		req.Header.Set("country", "KZ")
+		req.Header.Set("region", "KZ-ALA")
+
+		middleware.GeoLocation(tc.resolver, nil, logrus.New())(next).ServeHTTP(httptest.NewRecorder(), req)
+
+		assertions.Equal(tc.country, country, tc.name)
+		assertions.Equal(tc.region, region, tc.name)
+	}
+}
+
+func (s *Suite) TestGeoTargetedBannerFiltered() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "geo_targeted_tag")
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{tags[0].ID},
+		FeatureIDs: []int{2},
+		Countries:  []string{"RU"},
+		Regions:    []string{"RU-MOW"},
+		Content: entity.Content{
+			Title: "geo_title",
+			Text:  "geo_text",
+			Url:   "http://geo.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	for _, tc := range []struct {
+		name      string
+		requester entity.Requester
+		shown     bool
+	}{
+		{"country and region match", entity.Requester{UserID: regularUser.ID, Country: "RU", Region: "RU-MOW"}, true},
+		{"other region", entity.Requester{UserID: regularUser.ID, Country: "RU", Region: "RU-SPE"}, false},
+		{"other country", entity.Requester{UserID: regularUser.ID, Country: "KZ"}, false},
+		{"unknown location", entity.Requester{UserID: regularUser.ID}, false},
+	} {
+		banner, err := s.bannerService.GetBannerByFeatureAndTags(ctx, 2, []int{tags[0].ID}, tc.requester)
+
+		if tc.shown {
+			assertions.NoError(err, tc.name)
+			assertions.Equal(created.ID, banner.ID, tc.name)
+		} else {
+			assertions.Error(err, tc.name)
+		}
+	}
+}
+
+func (s *Suite) TestDeleteGeoTargetedBanner() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "geo_deleted_tag")
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{tags[0].ID},
+		FeatureIDs: []int{2},
+		Countries:  []string{"RU", "KZ"},
+		Regions:    []string{"RU-MOW"},
+		Platforms:  []string{"ios"},
+		Content: entity.Content{
+			Title: "geo_deleted_title",
+			Text:  "geo_deleted_text",
+			Url:   "http://geo-deleted.com",
+		},
+		IsActive: true,
+	})
+	assertions.NoError(err)
+
+	deleted, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Equal(created.ID, deleted.ID)
+	assertions.Equal([]string{"RU", "KZ"}, deleted.Countries)
+	assertions.Equal([]string{"RU-MOW"}, deleted.Regions)
+	assertions.Equal([]string{"ios"}, deleted.Platforms)
+}
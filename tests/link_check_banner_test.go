@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	linkcheckrepo "avito-backend-trainee-2024/internal/repository/postgres/linkcheck"
+	linkcheckservice "avito-backend-trainee-2024/internal/service/linkcheck"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (s *Suite) TestCheckBannerLinks() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/moved", func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, "/landing", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/landing", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/", http.NotFound)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var banners []*entity.Banner
+
+	// banners are targeted to different countries, so they do not conflict with each other
+	for _, link := range []struct{ path, country string }{{"/moved", "RU"}, {"/missing", "KZ"}} {
+		created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+			TagIDs:     []int{3},
+			FeatureIDs: []int{2},
+			Countries:  []string{link.country},
+			Content: entity.Content{
+				Title: "link_title",
+				Text:  "link_text",
+				Url:   server.URL + link.path,
+			},
+			IsActive: true,
+		})
+		assertions.NoError(err)
+
+		defer func() {
+			_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+			s.NoError(err)
+		}()
+
+		banners = append(banners, created)
+	}
+
+	checks, err := s.linkCheckService.CheckBanners(ctx, banners)
+	assertions.NoError(err)
+	assertions.Len(checks, 2)
+
+	checksByBannerID := make(map[int]*entity.LinkCheck)
+
+	for _, check := range checks {
+		checksByBannerID[check.BannerID] = check
+	}
+
+	moved := checksByBannerID[banners[0].ID]
+	assertions.False(moved.IsBroken)
+	assertions.Equal(http.StatusOK, moved.StatusCode)
+	assertions.Equal([]string{server.URL + "/landing"}, moved.Redirects)
+
+	missing := checksByBannerID[banners[1].ID]
+	assertions.True(missing.IsBroken)
+	assertions.Equal(http.StatusNotFound, missing.StatusCode)
+
+	broken, err := s.linkCheckService.GetBrokenLinks(ctx, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	var brokenIDs []int
+
+	for _, check := range broken {
+		brokenIDs = append(brokenIDs, check.BannerID)
+	}
+
+	assertions.Contains(brokenIDs, banners[1].ID)
+	assertions.NotContains(brokenIDs, banners[0].ID)
+}
+
+// createLinkedBanners creates active banners with urls and deletes them once test is done, banners are targeted
+// to different countries, so they do not conflict with each other
+func (s *Suite) createLinkedBanners(ctx context.Context, urls ...string) []*entity.Banner {
+	countries := []string{"RU", "KZ", "BY", "AM"}
+
+	var banners []*entity.Banner
+
+	for i, url := range urls {
+		created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+			TagIDs:     []int{3},
+			FeatureIDs: []int{2},
+			Countries:  []string{countries[i]},
+			Content: entity.Content{
+				Title: "link_title",
+				Text:  "link_text",
+				Url:   url,
+			},
+			IsActive: true,
+		})
+		s.Require().NoError(err)
+
+		s.T().Cleanup(func() {
+			_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+			s.NoError(err)
+		})
+
+		banners = append(banners, created)
+	}
+
+	return banners
+}
+
+func (s *Suite) TestBrokenLinksOfInactiveBannersNotReported() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	banners := s.createLinkedBanners(ctx, server.URL+"/inactive", server.URL+"/archived")
+	inactive, archived := banners[0], banners[1]
+
+	_, err := s.linkCheckService.CheckBanners(ctx, banners)
+	assertions.NoError(err)
+
+	_, err = s.db.ExecContext(ctx, "UPDATE banner SET is_active = false WHERE id = $1", inactive.ID)
+	assertions.NoError(err)
+
+	_, err = s.db.ExecContext(ctx, "UPDATE banner SET archived_at = now() WHERE id = $1", archived.ID)
+	assertions.NoError(err)
+
+	broken, err := s.linkCheckService.GetBrokenLinks(ctx, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	for _, check := range broken {
+		assertions.NotEqual(inactive.ID, check.BannerID)
+		assertions.NotEqual(archived.ID, check.BannerID)
+	}
+}
+
+func (s *Suite) TestLinksToPrivateAddressesNotRequested() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	var requested atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requested.Store(true)
+	}))
+	defer server.Close()
+
+	service := linkcheckservice.New(linkcheckrepo.New(s.db), s.bannerRepo, linkcheckservice.DefaultCheckInterval,
+		linkcheckservice.DefaultConcurrency, time.Millisecond, linkcheckservice.DefaultRequestTimeout, false, logrus.New())
+
+	checks, err := service.CheckBanners(ctx, s.createLinkedBanners(ctx, server.URL))
+	assertions.NoError(err)
+	assertions.Len(checks, 1)
+
+	assertions.True(checks[0].IsBroken)
+	assertions.Zero(checks[0].StatusCode)
+	assertions.NotEmpty(checks[0].Error)
+	assertions.False(requested.Load())
+}
+
+func (s *Suite) TestRedirectsOfLinkRateLimited() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/first", func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, "/second", http.StatusFound)
+	})
+	mux.HandleFunc("/second", func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, "/landing", http.StatusFound)
+	})
+	mux.HandleFunc("/landing", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	hostInterval := 100 * time.Millisecond
+
+	service := linkcheckservice.New(linkcheckrepo.New(s.db), s.bannerRepo, linkcheckservice.DefaultCheckInterval,
+		linkcheckservice.DefaultConcurrency, hostInterval, linkcheckservice.DefaultRequestTimeout, true, logrus.New())
+
+	banners := s.createLinkedBanners(ctx, server.URL+"/first")
+
+	start := time.Now()
+
+	checks, err := service.CheckBanners(ctx, banners)
+	assertions.NoError(err)
+	assertions.Len(checks, 1)
+	assertions.False(checks[0].IsBroken)
+
+	// the first request is sent at once, each of two redirects waits for the host
+	assertions.GreaterOrEqual(time.Since(start), 2*hostInterval)
+}
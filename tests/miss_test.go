@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+	missrepo "avito-backend-trainee-2024/internal/repository/postgres/miss"
+	missservice "avito-backend-trainee-2024/internal/service/miss"
+	stringutils "avito-backend-trainee-2024/pkg/utils/string"
+)
+
+// deleteMissesOnCleanup deletes persisted misses with any of tags once test is done
+func (s *Suite) deleteMissesOnCleanup(ctx context.Context, tags []*entity.Tag) {
+	tagIDs := make([]int, 0, len(tags))
+
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	s.T().Cleanup(func() {
+		_, err := s.db.ExecContext(
+			ctx,
+			"DELETE FROM banner_miss WHERE tag_ids && $1::integer[]",
+			stringutils.IntSliceToPostgresArray(tagIDs),
+		)
+		s.NoError(err)
+	})
+}
+
+func findMiss(misses []*entity.BannerMiss, featureID int, tagIDs []int) *entity.BannerMiss {
+	for _, miss := range misses {
+		if miss.FeatureID == featureID && slices.Equal(miss.TagIDs, tagIDs) {
+			return miss
+		}
+	}
+
+	return nil
+}
+
+func (s *Suite) TestMissesAggregatedAndFlushed() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "miss_first_tag", "miss_second_tag")
+	first, second := tags[0], tags[1]
+
+	s.deleteMissesOnCleanup(ctx, tags)
+
+	service := missservice.New(missrepo.New(s.db), time.Minute, time.Hour, 10, logrus.New())
+
+	// the same combination requested in any order and with duplicates is counted once
+	service.RecordMiss(2, []int{second.ID, first.ID})
+	service.RecordMiss(2, []int{first.ID, second.ID, first.ID})
+
+	misses, err := service.GetMisses(ctx, 2, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	pending := findMiss(misses, 2, []int{first.ID, second.ID})
+	assertions.NotNil(pending)
+	assertions.Equal(2, pending.MissesCount)
+
+	assertions.NoError(service.Flush(ctx))
+
+	persisted, err := missrepo.New(s.db).GetMisses(ctx, 2, nil, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	miss := findMiss(persisted, 2, []int{first.ID, second.ID})
+	assertions.NotNil(miss)
+	assertions.Equal(2, miss.MissesCount)
+
+	// flushed misses are added to persisted ones
+	service.RecordMiss(2, []int{first.ID, second.ID})
+	assertions.NoError(service.Flush(ctx))
+
+	persisted, err = missrepo.New(s.db).GetMisses(ctx, 2, nil, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	miss = findMiss(persisted, 2, []int{first.ID, second.ID})
+	assertions.NotNil(miss)
+	assertions.Equal(3, miss.MissesCount)
+}
+
+func (s *Suite) TestMissesOfNewCombinationsDroppedOverCap() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "miss_capped_tag", "miss_dropped_tag")
+	capped, dropped := tags[0], tags[1]
+
+	s.deleteMissesOnCleanup(ctx, tags)
+
+	service := missservice.New(missrepo.New(s.db), time.Minute, time.Hour, 1, logrus.New())
+
+	service.RecordMiss(2, []int{capped.ID})
+	service.RecordMiss(2, []int{dropped.ID})
+
+	// already counted combination is still counted
+	service.RecordMiss(2, []int{capped.ID})
+
+	misses, err := service.GetMisses(ctx, 2, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	miss := findMiss(misses, 2, []int{capped.ID})
+	assertions.NotNil(miss)
+	assertions.Equal(2, miss.MissesCount)
+
+	assertions.Nil(findMiss(misses, 2, []int{dropped.ID}))
+}
+
+func (s *Suite) TestMissesOfUnknownTagsNotPersisted() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "miss_known_tag")
+	known := tags[0]
+
+	s.deleteMissesOnCleanup(ctx, tags)
+
+	unknownTagID := 1_000_000_000
+
+	service := missservice.New(missrepo.New(s.db), time.Minute, time.Hour, 10, logrus.New())
+
+	service.RecordMiss(2, []int{known.ID, unknownTagID})
+	assertions.NoError(service.Flush(ctx))
+
+	persisted, err := missrepo.New(s.db).GetMisses(ctx, 2, nil, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	assertions.Nil(findMiss(persisted, 2, []int{known.ID, unknownTagID}))
+
+	// lookups with unknown tags are not counted as misses at all
+	s.getUserBanner(regularUser, "2", "1000000000")
+
+	report, err := s.bannerService.GetCoverageReport(ctx, 2)
+	assertions.NoError(err)
+
+	assertions.Nil(findMiss(report.Uncovered, 2, []int{unknownTagID}))
+}
+
+func (s *Suite) TestOutdatedMissesPruned() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "miss_outdated_tag", "miss_recent_tag")
+	outdated, recent := tags[0], tags[1]
+
+	s.deleteMissesOnCleanup(ctx, tags)
+
+	repo := missrepo.New(s.db)
+
+	assertions.NoError(repo.SaveMisses(ctx, []*entity.BannerMiss{
+		{FeatureID: 2, TagIDs: []int{outdated.ID}, MissesCount: 1, LastMissedAt: time.Now().Add(-2 * time.Hour)},
+		{FeatureID: 2, TagIDs: []int{recent.ID}, MissesCount: 1, LastMissedAt: time.Now()},
+	}))
+
+	service := missservice.New(repo, time.Minute, time.Hour, 10, logrus.New())
+
+	pruned, err := service.Prune(ctx)
+	assertions.NoError(err)
+	assertions.GreaterOrEqual(pruned, 1)
+
+	persisted, err := repo.GetMisses(ctx, 2, nil, 0, math.MaxInt64)
+	assertions.NoError(err)
+
+	assertions.Nil(findMiss(persisted, 2, []int{outdated.ID}))
+	assertions.NotNil(findMiss(persisted, 2, []int{recent.ID}))
+}
+
+func (s *Suite) TestMissesPaginatedWithPendingOnes() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "miss_frequent_tag", "miss_pending_tag", "miss_rare_tag")
+	frequent, pending, rare := tags[0], tags[1], tags[2]
+
+	s.deleteMissesOnCleanup(ctx, tags)
+
+	repo := missrepo.New(s.db)
+
+	assertions.NoError(repo.SaveMisses(ctx, []*entity.BannerMiss{
+		{FeatureID: 2, TagIDs: []int{frequent.ID}, MissesCount: 1_000_003, LastMissedAt: time.Now()},
+		{FeatureID: 2, TagIDs: []int{rare.ID}, MissesCount: 1_000_001, LastMissedAt: time.Now()},
+	}))
+
+	service := missservice.New(repo, time.Minute, time.Hour, 10, logrus.New())
+
+	// not persisted miss is ranked together with persisted ones
+	for i := 0; i < 2; i++ {
+		service.RecordMiss(2, []int{pending.ID})
+	}
+
+	assertions.NoError(repo.SaveMisses(ctx, []*entity.BannerMiss{
+		{FeatureID: 2, TagIDs: []int{pending.ID}, MissesCount: 1_000_000, LastMissedAt: time.Now()},
+	}))
+
+	page, err := service.GetMisses(ctx, 2, 1, 1)
+	assertions.NoError(err)
+
+	assertions.Len(page, 1)
+	assertions.Equal([]int{pending.ID}, page[0].TagIDs)
+	assertions.Equal(1_000_002, page[0].MissesCount)
+}
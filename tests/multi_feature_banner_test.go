@@ -0,0 +1,12 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+)
+
+func (s *Suite) TestGetBannerBySecondFeature() {
+	// banner is shown in features 1 and 2
+	recorder := s.getUserBanner(regularUser, "2", "1,2")
+
+	s.requireBannerContent(recorder, entity.Content{Title: "title", Text: "text", Url: "http://url.com"})
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	overriderepo "avito-backend-trainee-2024/internal/repository/postgres/override"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func (s *Suite) TestGetOverriddenInactiveBannerByUser() {
+	// banner is pinned regardless of tags
+	recorder := s.getUserBanner(qaUser, "2", "2")
+
+	s.requireBannerContent(recorder, entity.Content{Title: "title2", Text: "text2", Url: "http://url2.com"})
+}
+
+func (s *Suite) TestOverrideOfArchivedBannerIgnored() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "archived_override_tag")
+
+	archived := s.createTaggedBanner(ctx, []int{tags[0].ID, 3}, true)
+	served := s.createTaggedBanner(ctx, []int{tags[0].ID}, true)
+
+	_, err := s.db.ExecContext(ctx, "UPDATE banner SET archived_at = now() WHERE id = $1", archived.ID)
+	assertions.NoError(err)
+
+	// the latest override of qa user in feature 2 pins archived banner
+	overrides, err := overriderepo.New(s.db).CreateOverrides(ctx, archived.ID, []int{qaUser.ID}, time.Now().Add(time.Hour))
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(overriderepo.New(s.db).DeleteOverride(ctx, overrides[0].ID))
+	}()
+
+	recorder := s.getUserBanner(qaUser, "2", strconv.Itoa(tags[0].ID))
+
+	s.requireBannerContent(recorder, entity.Content{
+		Title: fmt.Sprintf("tagged_title_%v", served.TagIDs),
+		Text:  "tagged_text",
+		Url:   "http://tagged.com",
+	})
+}
+
+func (s *Suite) TestCachedOverriddenBannerNotServedAfterOverrideExpires() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "expiring_override_tag")
+
+	pinned := s.createTaggedBanner(ctx, []int{tags[0].ID, 3}, false)
+	served := s.createTaggedBanner(ctx, []int{tags[0].ID}, true)
+
+	overrides, err := overriderepo.New(s.db).CreateOverrides(ctx, pinned.ID, []int{regularUser.ID}, time.Now().Add(time.Second))
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(overriderepo.New(s.db).DeleteOverride(ctx, overrides[0].ID))
+	}()
+
+	query := url.Values{}
+
+	// no 'use_last_revision' param, so banner is served from cache while it is valid
+	query.Set("feature_id", "2")
+	query.Set("tag_ids", strconv.Itoa(tags[0].ID))
+
+	getBanner := func() *httptest.ResponseRecorder {
+		return s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+	}
+
+	s.requireBannerContent(getBanner(), entity.Content{
+		Title: fmt.Sprintf("tagged_title_%v", pinned.TagIDs),
+		Text:  "tagged_text",
+		Url:   "http://tagged.com",
+	})
+
+	time.Sleep(time.Until(overrides[0].ExpiresAt))
+
+	s.requireBannerContent(getBanner(), entity.Content{
+		Title: fmt.Sprintf("tagged_title_%v", served.TagIDs),
+		Text:  "tagged_text",
+		Url:   "http://tagged.com",
+	})
+}
@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	schedulerepo "avito-backend-trainee-2024/internal/repository/postgres/schedule"
+	scheduleservice "avito-backend-trainee-2024/internal/service/schedule"
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+func (s *Suite) TestApplyDueScheduledEdit() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "scheduled_title",
+			Text:  "scheduled_text",
+			Url:   "http://scheduled.com",
+		},
+		IsActive: false,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	isActive := true
+
+	// edit due while service was down is inserted directly, since scheduling it in the past is rejected
+	_, err = schedulerepo.New(s.db).CreateScheduledEdit(ctx, entity.ScheduledEdit{
+		BannerID: created.ID,
+		Patch: entity.Banner{
+			Content: entity.Content{
+				Title: "scheduled_title_new",
+			},
+		},
+		IsActive: &isActive,
+		ApplyAt:  time.Now().Add(-time.Minute),
+	})
+	assertions.NoError(err)
+
+	assertions.NoError(s.scheduleService.ApplyDueEdits(ctx))
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.True(banner.IsActive)
+	assertions.Equal("scheduled_title_new", banner.Content.Title)
+	assertions.Equal("scheduled_text", banner.Content.Text)
+
+	edits, err := s.scheduleService.GetScheduledEdits(ctx, created.ID, 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.Empty(edits)
+}
+
+// createDueEdit creates banner and its edit due while service was down, both are deleted once test is done
+func (s *Suite) createDueEdit(ctx context.Context, isActive bool, edit entity.ScheduledEdit) (*entity.Banner, *entity.ScheduledEdit) {
+	assertions := s.Require()
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "due_edit_title",
+			Text:  "due_edit_text",
+			Url:   "http://due-edit.com",
+		},
+		IsActive: isActive,
+	})
+	assertions.NoError(err)
+
+	s.T().Cleanup(func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	})
+
+	edit.BannerID = created.ID
+	edit.ApplyAt = time.Now().Add(-time.Minute)
+
+	scheduled, err := schedulerepo.New(s.db).CreateScheduledEdit(ctx, edit)
+	assertions.NoError(err)
+
+	return created, scheduled
+}
+
+func (s *Suite) TestScheduledEditWithoutActivityKeepsBannerActive() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created, _ := s.createDueEdit(ctx, true, entity.ScheduledEdit{
+		Patch: entity.Banner{
+			Content: entity.Content{
+				Title: "due_edit_title_new",
+			},
+		},
+	})
+
+	assertions.NoError(s.scheduleService.ApplyDueEdits(ctx))
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.True(banner.IsActive)
+	assertions.Equal("due_edit_title_new", banner.Content.Title)
+}
+
+func (s *Suite) TestScheduledEditWithPriorityAndQuotedContentApplied() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	priority := 7
+
+	created, scheduled := s.createDueEdit(ctx, true, entity.ScheduledEdit{
+		Patch: entity.Banner{
+			Priority: &priority,
+			Content: entity.Content{
+				Title: "it's due",
+				Text:  "text with 'quotes'",
+			},
+		},
+	})
+
+	// priority is stored with edit
+	assertions.Equal(&priority, scheduled.Patch.Priority)
+
+	assertions.NoError(s.scheduleService.ApplyDueEdits(ctx))
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Equal(priority, *banner.Priority)
+	assertions.Equal("it's due", banner.Content.Title)
+	assertions.Equal("text with 'quotes'", banner.Content.Text)
+}
+
+func (s *Suite) TestScheduledEditAppliedOnce() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	_, scheduled := s.createDueEdit(ctx, true, entity.ScheduledEdit{
+		Patch: entity.Banner{
+			Content: entity.Content{
+				Title: "due_edit_title_once",
+			},
+		},
+	})
+
+	repo := schedulerepo.New(s.db)
+
+	validations := 0
+	validate := func(ctx context.Context, bannerID int, updateModel entity.Banner) error {
+		validations++
+		return nil
+	}
+
+	applied, err := repo.ApplyEdit(ctx, scheduled.ID, validate, scheduleservice.MaxApplyAttempts)
+	assertions.NoError(err)
+	assertions.NotNil(applied)
+	assertions.Equal(entity.ScheduledEditApplied, applied.Status)
+
+	// edit listed as due before it was applied by other instance is skipped
+	applied, err = repo.ApplyEdit(ctx, scheduled.ID, validate, scheduleservice.MaxApplyAttempts)
+	assertions.NoError(err)
+	assertions.Nil(applied)
+
+	assertions.Equal(1, validations)
+
+	// applied edit can not be canceled
+	assertions.ErrorIs(s.scheduleService.CancelScheduledEdit(ctx, scheduled.ID), schedulerepo.ErrNoSuchScheduledEdit)
+}
+
+func (s *Suite) TestScheduledEditLockedByOtherInstanceSkipped() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created, scheduled := s.createDueEdit(ctx, true, entity.ScheduledEdit{
+		Patch: entity.Banner{
+			Content: entity.Content{
+				Title: "due_edit_title_locked",
+			},
+		},
+	})
+
+	// other instance applying the edit holds its lock
+	tx, err := s.db.BeginTxx(ctx, nil)
+	assertions.NoError(err)
+
+	_, err = tx.ExecContext(ctx, "SELECT id FROM banner_scheduled_edit WHERE id = $1 FOR UPDATE", scheduled.ID)
+	assertions.NoError(err)
+
+	assertions.NoError(s.scheduleService.ApplyDueEdits(ctx))
+
+	assertions.NoError(tx.Rollback())
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+	assertions.Equal("due_edit_title", banner.Content.Title)
+
+	edits, err := s.scheduleService.GetScheduledEdits(ctx, created.ID, 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.Len(edits, 1)
+	assertions.Equal(entity.ScheduledEditPending, edits[0].Status)
+}
+
+func (s *Suite) TestFailedScheduledEditAttemptRolledBack() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	created, scheduled := s.createDueEdit(ctx, true, entity.ScheduledEdit{
+		Patch: entity.Banner{
+			Content: entity.Content{
+				Title: "due_edit_title_failed",
+			},
+		},
+	})
+
+	failed, err := schedulerepo.New(s.db).ApplyEdit(ctx, scheduled.ID, func(ctx context.Context, bannerID int, updateModel entity.Banner) error {
+		return errors.New("invalid edit")
+	}, 1)
+	assertions.NoError(err)
+	assertions.NotNil(failed)
+	assertions.Equal(entity.ScheduledEditFailed, failed.Status)
+	assertions.Equal(1, failed.Attempts)
+	assertions.Equal("invalid edit", failed.LastError)
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+	assertions.Equal("due_edit_title", banner.Content.Title)
+}
+
+func (s *Suite) TestScheduleEditInPast() {
+	assertions := s.Require()
+
+	_, err := s.scheduleService.ScheduleEdit(context.Background(), entity.ScheduledEdit{
+		BannerID: 1,
+		Patch: entity.Banner{
+			IsActive: true,
+		},
+		ApplyAt: time.Now().Add(-time.Minute),
+	})
+
+	assertions.ErrorIs(err, scheduleservice.ErrApplyAtInPast)
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	segmentrepo "avito-backend-trainee-2024/internal/repository/postgres/segment"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+func (s *Suite) TestGetSegmentBannerByUserInSegment() {
+	recorder := s.getUserBanner(regularUser, "1", "2")
+
+	s.requireBannerContent(recorder, entity.Content{Title: "title3", Text: "text3", Url: "http://url3.com"})
+}
+
+func (s *Suite) TestGetSegmentBannerByUserNotInSegment() {
+	recorder := s.getUserBanner(adminUser, "1", "2")
+
+	s.requireErrorResponse(recorder, http.StatusBadRequest, "error occurred fetching banner: no such banner")
+}
+
+func (s *Suite) TestDeleteSegmentWaitsForBannerBeingTargeted() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	repo := segmentrepo.New(s.db)
+
+	segment, err := repo.CreateSegment(ctx, entity.Segment{Name: "targeted_segment"}, []int{regularUser.ID})
+	assertions.NoError(err)
+
+	s.T().Cleanup(func() {
+		err := repo.DeleteSegment(ctx, segment.ID)
+		if !errors.Is(err, segmentrepo.ErrNoSuchSegment) {
+			s.NoError(err)
+		}
+	})
+
+	banner := s.createTaggedBanner(ctx, []int{3}, false)
+
+	// banner is being targeted to the segment by concurrent transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	assertions.NoError(err)
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO banner_segment (banner_id, segment_id) VALUES ($1, $2)", banner.ID, segment.ID)
+	assertions.NoError(err)
+
+	deleted := make(chan error, 1)
+
+	go func() {
+		deleted <- repo.DeleteSegment(ctx, segment.ID)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	assertions.NoError(tx.Commit())
+
+	assertions.ErrorIs(<-deleted, segmentrepo.ErrSegmentInUse)
+}
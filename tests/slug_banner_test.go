@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+
+	"avito-backend-trainee-2024/internal/domain/entity"
+	midlewares "avito-backend-trainee-2024/internal/handler/middleware"
+	featurerepo "avito-backend-trainee-2024/internal/repository/postgres/feature"
+)
+
+func (s *Suite) TestGetBannerBySlugs() {
+	query := url.Values{}
+
+	// slugs of features and tags created by migrations are made of their names
+	query.Set("feature_slug", "feature-test-name")
+	query.Set("tag_slugs", "tag-test-name,tag-test-name2")
+	query.Set("use_last_revision", "true")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+
+	s.requireBannerContent(recorder, entity.Content{Title: "title", Text: "text", Url: "http://url.com"})
+}
+
+func (s *Suite) TestGetBannerByNotExistingSlug() {
+	query := url.Values{}
+
+	query.Set("feature_slug", "not-existing-feature")
+	query.Set("tag_ids", "1,2")
+	query.Set("use_last_revision", "true")
+
+	recorder := s.serveAs(regularUser, newUserBannerRequest(http.MethodGet, "", query, nil))
+
+	s.requireErrorResponse(recorder, http.StatusBadRequest,
+		"error occurred resolving 'feature_slug' query param: no such feature")
+}
+
+// resolveSlugs runs query through slug resolution middleware with cache and returns query passed further
+func (s *Suite) resolveSlugs(cache *gocache.Cache, query url.Values) (*httptest.ResponseRecorder, url.Values) {
+	var resolved url.Values
+
+	handler := midlewares.SlugResolution(s.bannerService, cache, logrus.New())(
+		http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			resolved = req.URL.Query()
+		}),
+	)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil))
+
+	return recorder, resolved
+}
+
+func (s *Suite) TestRenamedFeatureSlugNotResolvedFromCache() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	cache := gocache.New(5*time.Minute, 10*time.Minute)
+
+	feature := s.createFeature(ctx, "slug-before-rename")
+
+	query := url.Values{}
+	query.Set("feature_slug", "slug-before-rename")
+
+	recorder, resolved := s.resolveSlugs(cache, query)
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode, recorder.Body.String())
+	assertions.Equal(strconv.Itoa(feature.ID), resolved.Get("feature_id"))
+
+	assertions.NoError(featurerepo.New(s.db).UpdateFeature(ctx, feature.ID, entity.Feature{Slug: "slug-after-rename"}))
+	midlewares.ForgetFeatureSlugs(cache, feature.ID)
+
+	recorder, _ = s.resolveSlugs(cache, query)
+	s.requireErrorResponse(recorder, http.StatusBadRequest,
+		"error occurred resolving 'feature_slug' query param: no such feature")
+}
+
+func (s *Suite) TestDeletedTagSlugNotResolvedFromCache() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	cache := gocache.New(5*time.Minute, 10*time.Minute)
+
+	tags := s.createTags(ctx, "slug deleted tag", "slug kept tag")
+	deleted, kept := tags[0], tags[1]
+
+	query := url.Values{}
+	query.Set("tag_slugs", deleted.Slug+","+kept.Slug)
+
+	recorder, resolved := s.resolveSlugs(cache, query)
+	assertions.Equal(http.StatusOK, recorder.Result().StatusCode, recorder.Body.String())
+	assertions.Equal(fmt.Sprintf("%d,%d", deleted.ID, kept.ID), resolved.Get("tag_ids"))
+
+	assertions.NoError(s.tagService.DeleteTag(ctx, deleted.ID, true))
+	midlewares.ForgetTagSlugs(cache, deleted.ID)
+
+	// slug of kept tag is still cached
+	assertions.Equal(1, cache.ItemCount())
+
+	recorder, _ = s.resolveSlugs(cache, query)
+	assertions.Equal(http.StatusBadRequest, recorder.Result().StatusCode, recorder.Body.String())
+}
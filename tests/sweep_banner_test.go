@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *Suite) TestSweepDeactivatesEndedBanner() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	endsAt := time.Now().Add(-time.Minute)
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "ended_title",
+			Text:  "ended_text",
+			Url:   "http://ended.com",
+		},
+		IsActive: true,
+		EndsAt:   &endsAt,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	sweep, err := s.sweepService.Sweep(ctx)
+	assertions.NoError(err)
+
+	assertions.Contains(sweep.DeactivatedIDs, created.ID)
+	assertions.NotContains(sweep.ArchivedIDs, created.ID)
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.False(banner.IsActive)
+	assertions.Nil(banner.ArchivedAt)
+}
+
+func (s *Suite) TestEndedBannerNotServedBeforeSweep() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "ended_not_swept_tag")
+
+	endsAt := time.Now().Add(-time.Minute)
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{tags[0].ID},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "ended_not_swept_title",
+			Text:  "ended_not_swept_text",
+			Url:   "http://ended-not-swept.com",
+		},
+		IsActive: true,
+		EndsAt:   &endsAt,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	recorder := s.getUserBanner(regularUser, "2", strconv.Itoa(tags[0].ID))
+
+	assertions.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (s *Suite) TestRemoveBannerEndDate() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	endsAt := time.Now().Add(time.Hour)
+
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: "removed_end_title",
+			Text:  "removed_end_text",
+			Url:   "http://removed-end.com",
+		},
+		IsActive: true,
+		EndsAt:   &endsAt,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	// zero end date removes it
+	assertions.NoError(s.bannerService.UpdateBanner(ctx, created.ID, entity.Banner{
+		IsActive: true,
+		EndsAt:   &time.Time{},
+	}))
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Nil(banner.EndsAt)
+	assertions.True(banner.IsActive)
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+func (s *Suite) TestGetBannerByChildTag() {
+	// banner is tagged with parent tag 2 only, so it is inherited by its child tag
+	recorder := s.getUserBanner(regularUser, "1", "3")
+
+	s.requireBannerContent(recorder, entity.Content{Title: "title3", Text: "text3", Url: "http://url3.com"})
+}
+
+func (s *Suite) TestInheritedActiveBannerPreferredToInactiveOne() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	parent := s.createTags(ctx, "inactive_parent_tag")[0]
+
+	child, err := s.tagService.CreateTag(ctx, entity.Tag{Name: "inactive_child_tag", ParentID: &parent.ID})
+	assertions.NoError(err)
+
+	s.T().Cleanup(func() {
+		s.NoError(s.tagService.DeleteTag(ctx, child.ID, true))
+	})
+
+	// banner with exactly requested tag is more specific, but inactive
+	s.createTaggedBanner(ctx, []int{child.ID}, false)
+	inherited := s.createTaggedBanner(ctx, []int{parent.ID}, true)
+
+	recorder := s.getUserBanner(regularUser, "2", strconv.Itoa(child.ID))
+
+	s.requireBannerContent(recorder, entity.Content{
+		Title: fmt.Sprintf("tagged_title_%v", inherited.TagIDs),
+		Text:  "tagged_text",
+		Url:   "http://tagged.com",
+	})
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	"context"
+	"fmt"
+	"math"
+)
+
+func (s *Suite) TestMergeTagsIntoAlias() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "merge_source", "merge_target")
+	source, target := tags[0], tags[1]
+
+	moved := s.createTaggedBanner(ctx, []int{source.ID}, true)
+	duplicate := s.createTaggedBanner(ctx, []int{source.ID, target.ID}, false)
+
+	merge, err := s.tagService.MergeTags(ctx, source.ID, target.ID, true, "admin")
+	assertions.NoError(err)
+
+	assertions.Equal(1, merge.MovedBanners)
+	assertions.Equal(1, merge.RemovedDuplicates)
+
+	for _, id := range []int{moved.ID, duplicate.ID} {
+		banner, err := s.bannerRepo.GetBannerByID(ctx, id)
+		assertions.NoError(err)
+		assertions.Equal([]int{target.ID}, banner.TagIDs)
+	}
+
+	// source tag is left as alias, so it is resolved to target one on lookup
+	recorder := s.getUserBanner(regularUser, "2", fmt.Sprint(source.ID))
+
+	s.requireBannerContent(recorder, moved.Content)
+
+	records, err := s.auditRepo.GetAuditRecords(ctx, entity.AuditEntityTag, 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.NotEmpty(records)
+
+	// the newest record goes first
+	assertions.Equal(entity.AuditActionMerge, records[0].Action)
+	assertions.Equal(source.ID, records[0].EntityID)
+	assertions.Equal("admin", records[0].Username)
+	assertions.JSONEq(
+		fmt.Sprintf(`{"target_id": %v, "keep_alias": true, "moved_banners": 1, "removed_duplicates": 1}`, target.ID),
+		records[0].Details,
+	)
+}
+
+func (s *Suite) TestMergeTagsConflictingBanners() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "conflicting_merge_source", "conflicting_merge_target")
+	source, target := tags[0], tags[1]
+
+	moved := s.createTaggedBanner(ctx, []int{source.ID}, true)
+	existing := s.createTaggedBanner(ctx, []int{target.ID}, true)
+
+	_, err := s.tagService.MergeTags(ctx, source.ID, target.ID, false, "admin")
+
+	var conflict *entity.BannerConflictError
+
+	assertions.ErrorAs(err, &conflict)
+	assertions.ElementsMatch([]int{moved.ID, existing.ID}, conflict.BannerIDs)
+
+	// merge is rolled back as a whole
+	banner, err := s.bannerRepo.GetBannerByID(ctx, moved.ID)
+	assertions.NoError(err)
+	assertions.Equal([]int{source.ID}, banner.TagIDs)
+
+	found, err := s.tagService.GetAllTags(ctx, source.Name, 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.Len(found, 1)
+}
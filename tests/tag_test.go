@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	tagrepo "avito-backend-trainee-2024/internal/repository/postgres/tag"
+	"context"
+	"fmt"
+	"math"
+)
+
+// createTags creates root tags with names and deletes them in the same order once test is done,
+// so source tag left as alias is deleted before the tag it was merged into
+func (s *Suite) createTags(ctx context.Context, names ...string) []*entity.Tag {
+	var tags []*entity.Tag
+
+	for _, name := range names {
+		tag, err := s.tagService.CreateTag(ctx, entity.Tag{Name: name})
+		s.Require().NoError(err)
+
+		tags = append(tags, tag)
+	}
+
+	s.T().Cleanup(func() {
+		for _, tag := range tags {
+			_ = s.tagService.DeleteTag(ctx, tag.ID, true)
+		}
+	})
+
+	return tags
+}
+
+// createTaggedBanner creates untargeted banner of feature 2 with tags and deletes it once test is done
+func (s *Suite) createTaggedBanner(ctx context.Context, tagIDs []int, isActive bool) *entity.Banner {
+	created, err := s.bannerService.CreateBanner(ctx, entity.Banner{
+		TagIDs:     tagIDs,
+		FeatureIDs: []int{2},
+		Content: entity.Content{
+			Title: fmt.Sprintf("tagged_title_%v", tagIDs),
+			Text:  "tagged_text",
+			Url:   "http://tagged.com",
+		},
+		IsActive: isActive,
+	})
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		_, _ = s.bannerRepo.DeleteBanner(ctx, created.ID)
+	})
+
+	return created
+}
+
+func (s *Suite) TestSearchTagsByNameWithWildcards() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "wild_card_tag", "wildXcard_tag", "100% wild tag")
+
+	// '_' and '%' are matched literally instead of any symbols
+	found, err := s.tagService.GetAllTags(ctx, "wild_card", 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.Len(found, 1)
+	assertions.Equal(tags[0].ID, found[0].ID)
+
+	found, err = s.tagService.GetAllTags(ctx, "%", 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.Len(found, 1)
+	assertions.Equal(tags[2].ID, found[0].ID)
+
+	// search is case insensitive
+	found, err = s.tagService.GetAllTags(ctx, "WILDX", 0, math.MaxInt64)
+	assertions.NoError(err)
+	assertions.Len(found, 1)
+	assertions.Equal(tags[1].ID, found[0].ID)
+}
+
+func (s *Suite) TestDeleteTagUsedByBanners() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "deleted_tag", "kept_tag")
+	deleted, kept := tags[0], tags[1]
+
+	banner := s.createTaggedBanner(ctx, []int{deleted.ID, kept.ID}, true)
+
+	assertions.ErrorIs(s.tagService.DeleteTag(ctx, deleted.ID, false), tagrepo.ErrTagInUse)
+
+	assertions.NoError(s.tagService.DeleteTag(ctx, deleted.ID, true))
+
+	detached, err := s.bannerRepo.GetBannerByID(ctx, banner.ID)
+	assertions.NoError(err)
+	assertions.Equal([]int{kept.ID}, detached.TagIDs)
+
+	// the only tag of banner is never detached
+	assertions.ErrorIs(s.tagService.DeleteTag(ctx, kept.ID, true), tagrepo.ErrTagIsOnlyBanner)
+}
+
+func (s *Suite) TestDeleteTagConflictingBanners() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	tags := s.createTags(ctx, "conflicting_deleted_tag", "conflicting_kept_tag")
+	deleted, kept := tags[0], tags[1]
+
+	detached := s.createTaggedBanner(ctx, []int{deleted.ID, kept.ID}, true)
+	existing := s.createTaggedBanner(ctx, []int{kept.ID}, true)
+
+	err := s.tagService.DeleteTag(ctx, deleted.ID, true)
+
+	var conflict *entity.BannerConflictError
+
+	assertions.ErrorAs(err, &conflict)
+	assertions.ElementsMatch([]int{detached.ID, existing.ID}, conflict.BannerIDs)
+
+	// deletion is rolled back as a whole
+	banner, err := s.bannerRepo.GetBannerByID(ctx, detached.ID)
+	assertions.NoError(err)
+	assertions.Equal([]int{deleted.ID, kept.ID}, banner.TagIDs)
+}
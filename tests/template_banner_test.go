@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"avito-backend-trainee-2024/internal/domain/entity"
+	templateservice "avito-backend-trainee-2024/internal/service/template"
+	"context"
+)
+
+func (s *Suite) TestInstantiateTemplate() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	template, err := s.templateService.CreateTemplate(ctx, entity.BannerTemplate{
+		Name: "discount_template",
+		Content: entity.Content{
+			Title: "{{discount}}% off",
+			Text:  "only in {{ city }}, {{discount}}% off everything",
+			Url:   "http://sale.com/{{city}}",
+		},
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.templateService.DeleteTemplate(ctx, template.ID))
+	}()
+
+	assertions.Equal([]string{"discount", "city"}, template.Placeholders)
+
+	created, err := s.templateService.InstantiateTemplate(ctx, template.ID, map[string]string{
+		"discount": "20",
+		"city":     "moscow",
+	}, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+		IsActive:   false,
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		_, err := s.bannerRepo.DeleteBanner(ctx, created.ID)
+		s.NoError(err)
+	}()
+
+	banner, err := s.bannerRepo.GetBannerByID(ctx, created.ID)
+	assertions.NoError(err)
+
+	assertions.Equal("20% off", banner.Content.Title)
+	assertions.Equal("only in moscow, 20% off everything", banner.Content.Text)
+	assertions.Equal("http://sale.com/moscow", banner.Content.Url)
+}
+
+func (s *Suite) TestInstantiateTemplateWithUnfilledPlaceholder() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	template, err := s.templateService.CreateTemplate(ctx, entity.BannerTemplate{
+		Name: "unfilled_template",
+		Content: entity.Content{
+			Title: "{{discount}}% off",
+			Text:  "only in {{city}}",
+			Url:   "http://sale.com",
+		},
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.templateService.DeleteTemplate(ctx, template.ID))
+	}()
+
+	_, err = s.templateService.InstantiateTemplate(ctx, template.ID, map[string]string{"discount": "20"}, entity.Banner{
+		TagIDs:     []int{3},
+		FeatureIDs: []int{2},
+	})
+
+	assertions.ErrorIs(err, templateservice.ErrPlaceholderNotFilled)
+}
+
+func (s *Suite) TestInstantiateTemplateWithBlankPlaceholderValue() {
+	assertions := s.Require()
+
+	ctx := context.Background()
+
+	template, err := s.templateService.CreateTemplate(ctx, entity.BannerTemplate{
+		Name: "blank_value_template",
+		Content: entity.Content{
+			Title: "{{discount}}% off",
+			Text:  "only in {{city}}",
+			Url:   "http://sale.com",
+		},
+	})
+	assertions.NoError(err)
+
+	defer func() {
+		s.NoError(s.templateService.DeleteTemplate(ctx, template.ID))
+	}()
+
+	for _, city := range []string{"", "  "} {
+		_, err = s.templateService.InstantiateTemplate(ctx, template.ID, map[string]string{"discount": "20", "city": city}, entity.Banner{
+			TagIDs:     []int{3},
+			FeatureIDs: []int{2},
+		})
+
+		assertions.ErrorIs(err, templateservice.ErrPlaceholderNotFilled)
+	}
+}